@@ -0,0 +1,37 @@
+package analyzer
+
+import (
+	"errors"
+
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+// ErrInvalidAlignment indicates that an alignment annotation is not a positive power of two
+var ErrInvalidAlignment = errors.New("invalid alignment")
+
+// ValidateAlignments checks that every [[ align = N ]] is a positive power of two, as C requires for the aligned
+// attribute. Returns all the errors joined.
+func ValidateAlignments(schema *parser.Schema) error {
+	errs := make([]error, 0)
+	walkDecls(schema.Decls, func(decl parser.Decl) {
+		annotated, ok := decl.(*parser.AnnotatedDecl)
+		if !ok {
+			return
+		}
+
+		align, ok := annotated.Find("align")
+		if !ok {
+			return
+		}
+
+		loc := parser.ExprLoc(align.Name)
+		value, err := intValue(align.Value)
+		if err != nil {
+			errs = append(errs, errorf(loc, ErrInvalidAlignment, "alignment must be an integer"))
+		} else if value <= 0 || value&(value-1) != 0 {
+			errs = append(errs, errorf(loc, ErrInvalidAlignment, "%d is not a power of two", value))
+		}
+	})
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,55 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"github.com/cedmundo/SimpleSchema/analyzer"
+	"github.com/cedmundo/SimpleSchema/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAlignments(t *testing.T) {
+	cases := []struct {
+		name        string
+		input       string
+		expectedErr error
+	}{
+		{
+			name:  "power of two",
+			input: "[[ align = 16 ]]\ntype s struct {\na : int\n}\n",
+		},
+		{
+			name:  "hexadecimal power of two",
+			input: "[[ align = 0x40 ]]\ntype s struct {\na : int\n}\n",
+		},
+		{
+			name:        "not a power of two",
+			input:       "[[ align = 12 ]]\ntype s struct {\na : int\n}\n",
+			expectedErr: analyzer.ErrInvalidAlignment,
+		},
+		{
+			name:        "zero alignment",
+			input:       "[[ align = 0 ]]\ntype s struct {\na : int\n}\n",
+			expectedErr: analyzer.ErrInvalidAlignment,
+		},
+		{
+			name:        "invalid field alignment",
+			input:       "type s struct {\n[[ align = 3 ]]\na : int\n}\n",
+			expectedErr: analyzer.ErrInvalidAlignment,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := parser.NewFromString(tt.name, tt.input).Parse()
+			require.NoError(t, err)
+
+			actualErr := analyzer.ValidateAlignments(schema)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+		})
+	}
+}
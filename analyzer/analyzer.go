@@ -0,0 +1,150 @@
+// Package analyzer checks the semantics of a parsed schema before any code is generated
+package analyzer
+
+import (
+	"fmt"
+
+	"github.com/cedmundo/SimpleSchema/lexer"
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+// Error is a diagnostic produced by an analyzer pass, it wraps a sentinel error so callers can use errors.Is
+type Error struct {
+	Loc lexer.Location
+	Err error
+	Msg string
+}
+
+// Error returns the diagnostic using the standard file coordinate format
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s: %s", e.Loc, e.Err, e.Msg)
+}
+
+// Unwrap returns the sentinel error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func errorf(loc lexer.Location, err error, msg string, args ...any) error {
+	return &Error{Loc: loc, Err: err, Msg: fmt.Sprintf(msg, args...)}
+}
+
+// walkDecls calls fn for every declaration, descending into annotated declarations and type blocks
+func walkDecls(decls []parser.Decl, fn func(parser.Decl)) {
+	for _, decl := range decls {
+		walkDecl(decl, fn)
+	}
+}
+
+func walkDecl(decl parser.Decl, fn func(parser.Decl)) {
+	fn(decl)
+	switch d := decl.(type) {
+	case *parser.AnnotatedDecl:
+		walkDecl(d.Decl, fn)
+	case *parser.TypeDecl:
+		walkExpr(d.Type, fn)
+	case *parser.ProcDecl:
+		walkExpr(d.Type, fn)
+	case *parser.Field:
+		walkExpr(d.Type, fn)
+	}
+}
+
+func walkExpr(expr parser.Expr, fn func(parser.Decl)) {
+	switch e := expr.(type) {
+	case *parser.StructDef:
+		walkDecls(e.Block.Decls, fn)
+	case *parser.UnionDef:
+		walkDecls(e.Block.Decls, fn)
+	case *parser.EnumDef:
+		walkDecls(e.Block.Decls, fn)
+	case *parser.PrototypeDef:
+		for i := range e.Params {
+			walkDecl(&e.Params[i], fn)
+		}
+		walkExpr(e.ReturnType, fn)
+	case *parser.Index:
+		walkExpr(e.Base, fn)
+	case *parser.OptionalType:
+		walkExpr(e.Type, fn)
+	case *parser.SetType:
+		walkExpr(e.Element, fn)
+	case *parser.MapType:
+		walkExpr(e.Key, fn)
+		walkExpr(e.Value, fn)
+	case *parser.TupleType:
+		for _, element := range e.Elements {
+			walkExpr(element, fn)
+		}
+	case *parser.Call:
+		walkExpr(e.Callee, fn)
+		for _, arg := range e.Args {
+			walkExpr(arg, fn)
+		}
+	case *parser.UnaryOp:
+		walkExpr(e.Operand, fn)
+	}
+}
+
+// typeRefs calls fn for every type name referenced by a type expression, values (like array sizes) and inline
+// blocks are skipped since walkDecl visits the fields of the blocks on their own
+func typeRefs(expr parser.Expr, fn func(name string, ref parser.Expr)) {
+	switch e := expr.(type) {
+	case *parser.Ident:
+		fn(e.Token.Value, e)
+	case *parser.QualifiedName:
+		fn(parser.LookupName(e), e)
+	case *parser.BinaryOp:
+		if e.Operator.Value == "." {
+			fn(parser.LookupName(e), e)
+		}
+	case *parser.UnaryOp:
+		typeRefs(e.Operand, fn)
+	case *parser.Call:
+		// type constructors like const(T)
+		for _, arg := range e.Args {
+			typeRefs(arg, fn)
+		}
+	case *parser.Index:
+		typeRefs(e.Base, fn)
+	case *parser.OptionalType:
+		typeRefs(e.Type, fn)
+	case *parser.SetType:
+		typeRefs(e.Element, fn)
+	case *parser.MapType:
+		typeRefs(e.Key, fn)
+		typeRefs(e.Value, fn)
+	case *parser.TupleType:
+		for _, element := range e.Elements {
+			typeRefs(element, fn)
+		}
+	case *parser.PrototypeDef:
+		typeRefs(e.ReturnType, fn)
+	}
+}
+
+// declTypeRefs calls fn for every type name referenced by a declaration and all its nested declarations
+func declTypeRefs(decl parser.Decl, fn func(name string, ref parser.Expr)) {
+	walkDecl(decl, func(decl parser.Decl) {
+		switch d := decl.(type) {
+		case *parser.TypeDecl:
+			typeRefs(d.Type, fn)
+		case *parser.ProcDecl:
+			typeRefs(d.Type, fn)
+		case *parser.ConstDecl:
+			typeRefs(d.Type, fn)
+		case *parser.Field:
+			typeRefs(d.Type, fn)
+		case *parser.EmbedDecl:
+			typeRefs(d.Type, fn)
+		}
+	})
+}
+
+func unwrapDecl(decl parser.Decl) parser.Decl {
+	if annotated, ok := decl.(*parser.AnnotatedDecl); ok {
+		return unwrapDecl(annotated.Decl)
+	}
+
+	return decl
+}
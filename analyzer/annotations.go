@@ -0,0 +1,212 @@
+package analyzer
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/cedmundo/SimpleSchema/lexer"
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+var (
+	// ErrUnknownAnnotation indicates that an annotation name is not declared in the registry
+	ErrUnknownAnnotation = errors.New("unknown annotation")
+
+	// ErrInvalidAnnotationValue indicates that an annotation value does not match the registered kind
+	ErrInvalidAnnotationValue = errors.New("invalid annotation value")
+)
+
+// AnnotationKind classifies the value expected by an annotation
+type AnnotationKind int
+
+const (
+	AnnotationKindAny    AnnotationKind = iota // AnnotationKindAny accepts any expression
+	AnnotationKindString                       // AnnotationKindString a string literal
+	AnnotationKindInt                          // AnnotationKindInt an integer literal of any base
+	AnnotationKindFloat                        // AnnotationKindFloat a floating point literal
+	AnnotationKindBool                         // AnnotationKindBool either true or false
+	AnnotationKindIdent                        // AnnotationKindIdent a plain identifier
+	AnnotationKindFlag                         // AnnotationKindFlag no value at all ([[ opaque ]])
+)
+
+// String returns the kind name as used on diagnostics
+func (k AnnotationKind) String() string {
+	switch k {
+	case AnnotationKindAny:
+		return "any"
+	case AnnotationKindString:
+		return "string"
+	case AnnotationKindInt:
+		return "int"
+	case AnnotationKindFloat:
+		return "float"
+	case AnnotationKindBool:
+		return "bool"
+	case AnnotationKindIdent:
+		return "identifier"
+	case AnnotationKindFlag:
+		return "flag"
+	}
+	panic("unreachable code: unhandled kind in AnnotationKind.String()")
+}
+
+// Registry declares the known annotation names along with the kind of value they expect. Namespaced annotations
+// ([[ debug_info.name = "a" ]]) belong to other tools and are accepted unless an annotation of their namespace is
+// registered, then the namespace is checked like the plain names.
+type Registry struct {
+	// Permissive accepts unknown annotations (custom ones), known annotations are still checked
+	Permissive bool
+	kinds      map[string]AnnotationKind
+	namespaces map[string]bool
+}
+
+// NewRegistry returns an empty strict registry
+func NewRegistry() *Registry {
+	return &Registry{kinds: make(map[string]AnnotationKind), namespaces: make(map[string]bool)}
+}
+
+// DefaultRegistry returns a strict registry with all the annotations understood by the toolchain
+func DefaultRegistry() *Registry {
+	r := NewRegistry()
+	r.Register("name", AnnotationKindString)
+	r.Register("binding_name", AnnotationKindString)
+	r.Register("namespace", AnnotationKindString)
+	r.Register("doc", AnnotationKindString)
+	r.Register("docs", AnnotationKindString)
+	r.Register("returns", AnnotationKindString)
+	r.Register("endianess", AnnotationKindString)
+	r.Register("c_attr", AnnotationKindString)
+	r.Register("size", AnnotationKindInt)
+	r.Register("align", AnnotationKindInt)
+	r.Register("version", AnnotationKindInt)
+	r.Register("opaque", AnnotationKindFlag)
+	r.Register("export", AnnotationKindFlag)
+	r.Register("override", AnnotationKindFlag)
+	r.Register("accessors", AnnotationKindFlag)
+	r.Register("readonly", AnnotationKindFlag)
+	r.Register("tagged", AnnotationKindFlag)
+	r.Register("flags", AnnotationKindFlag)
+	r.Register("deprecated", AnnotationKindAny)
+	return r
+}
+
+// Register declares an annotation name (it can be dotted, like json.name), replacing any previous kind
+func (r *Registry) Register(name string, kind AnnotationKind) {
+	r.kinds[name] = kind
+	if namespace, ok := annotationNamespace(name); ok {
+		r.namespaces[namespace] = true
+	}
+}
+
+// annotationNamespace returns the namespace of a dotted annotation name (debug_info of debug_info.name)
+func annotationNamespace(name string) (string, bool) {
+	dot := strings.LastIndexByte(name, '.')
+	if dot < 0 {
+		return "", false
+	}
+
+	return name[:dot], true
+}
+
+// Lookup returns the kind of registered annotation
+func (r *Registry) Lookup(name string) (AnnotationKind, bool) {
+	kind, ok := r.kinds[name]
+	return kind, ok
+}
+
+// Validate checks the annotations of every declaration in the schema, returns all the errors joined. Enum members
+// accept unknown annotations as the data of the enum tables, known annotations are still checked.
+func (r *Registry) Validate(schema *parser.Schema) error {
+	// the members are visited after their enum, so they are collected first
+	members := make(map[parser.Decl]bool)
+	walkDecls(schema.Decls, func(decl parser.Decl) {
+		var typ parser.Expr
+		switch d := decl.(type) {
+		case *parser.TypeDecl:
+			typ = d.Type
+		case *parser.Field:
+			typ = d.Type
+		}
+
+		if def, ok := typ.(*parser.EnumDef); ok {
+			for _, member := range def.Block.Decls {
+				members[member] = true
+			}
+		}
+	})
+
+	errs := make([]error, 0)
+	walkDecls(schema.Decls, func(decl parser.Decl) {
+		var annotations []*parser.Annotation
+		switch d := decl.(type) {
+		case *parser.AnnotatedDecl:
+			annotations = d.Annotations
+		case *parser.Field:
+			annotations = d.Annotations
+		}
+
+		for _, annotation := range annotations {
+			err := r.validateAnnotation(annotation, members[decl])
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	})
+
+	return errors.Join(errs...)
+}
+
+func (r *Registry) validateAnnotation(annotation *parser.Annotation, data bool) error {
+	loc := parser.ExprLoc(annotation.Name)
+	name := parser.LookupName(annotation.Name)
+	kind, ok := r.Lookup(name)
+	if !ok {
+		namespace, namespaced := annotationNamespace(name)
+		if r.Permissive || data || (namespaced && !r.namespaces[namespace]) {
+			return nil
+		}
+
+		return errorf(loc, ErrUnknownAnnotation, "`%s`", name)
+	}
+
+	if !matchesKind(annotation.Value, kind) {
+		if kind == AnnotationKindFlag {
+			return errorf(loc, ErrInvalidAnnotationValue, "`%s` is a flag and takes no value", name)
+		}
+
+		return errorf(loc, ErrInvalidAnnotationValue, "`%s` expects a %s value", name, kind)
+	}
+
+	return nil
+}
+
+func matchesKind(value parser.Expr, kind AnnotationKind) bool {
+	switch kind {
+	case AnnotationKindAny:
+		return true
+	case AnnotationKindFlag:
+		return value == nil
+	case AnnotationKindIdent:
+		_, ok := value.(*parser.Ident)
+		return ok
+	case AnnotationKindBool:
+		ident, ok := value.(*parser.Ident)
+		return ok && (ident.Token.Value == "true" || ident.Token.Value == "false")
+	}
+
+	literal, ok := value.(*parser.Literal)
+	if !ok {
+		return false
+	}
+
+	switch literal.Token.Tag {
+	case lexer.TokenTagString:
+		return kind == AnnotationKindString
+	case lexer.TokenTagFloat:
+		return kind == AnnotationKindFloat
+	case lexer.TokenTagDecInt, lexer.TokenTagBinInt, lexer.TokenTagOctInt, lexer.TokenTagHexInt:
+		return kind == AnnotationKindInt
+	}
+
+	return false
+}
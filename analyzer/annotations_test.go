@@ -0,0 +1,129 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"github.com/cedmundo/SimpleSchema/analyzer"
+	"github.com/cedmundo/SimpleSchema/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Validate(t *testing.T) {
+	cases := []struct {
+		name        string
+		input       string
+		permissive  bool
+		expectedErr error
+	}{
+		{
+			name:  "valid annotation",
+			input: "[[ name = \"vec2\" ]]\ntype vec2 float[2];",
+		},
+		{
+			name:  "valid field annotation",
+			input: "type s struct {\n[[ endianess = \"be\" ]]\nx : u32;\n}\n",
+		},
+		{
+			name:        "unknown annotation name",
+			input:       "[[ deprected = true ]]\ntype s struct {};",
+			expectedErr: analyzer.ErrUnknownAnnotation,
+		},
+		{
+			name:        "unknown field annotation name",
+			input:       "type s struct {\n[[ endianes = \"be\" ]]\nx : u32;\n}\n",
+			expectedErr: analyzer.ErrUnknownAnnotation,
+		},
+		{
+			name:        "unknown param annotation name",
+			input:       "proc f([[ dc = \"the key\" ]] key : string) -> bool;\n",
+			expectedErr: analyzer.ErrUnknownAnnotation,
+		},
+		{
+			name:        "invalid annotation value",
+			input:       "[[ name = 10 ]]\ntype s struct {};",
+			expectedErr: analyzer.ErrInvalidAnnotationValue,
+		},
+		{
+			name:  "valid flag annotation",
+			input: "[[ opaque ]]\ntype s struct {};",
+		},
+		{
+			name:        "flag annotation with value",
+			input:       "[[ opaque = true ]]\ntype s struct {};",
+			expectedErr: analyzer.ErrInvalidAnnotationValue,
+		},
+		{
+			name:        "missing annotation value",
+			input:       "[[ name ]]\ntype s struct {};",
+			expectedErr: analyzer.ErrInvalidAnnotationValue,
+		},
+		{
+			name:  "namespaced annotation of another tool",
+			input: "[[ debug_info.name = \"hello friend\" ]]\ntype s struct {};",
+		},
+		{
+			name:        "unknown annotation of a registered namespace",
+			input:       "[[ json.nmae = \"s\" ]]\ntype s struct {};",
+			expectedErr: analyzer.ErrUnknownAnnotation,
+		},
+		{
+			name:        "invalid annotation of a registered namespace",
+			input:       "[[ json.name = 1 ]]\ntype s struct {};",
+			expectedErr: analyzer.ErrInvalidAnnotationValue,
+		},
+		{
+			name:       "custom annotation in permissive mode",
+			input:      "[[ py_fmd.name = \"red\" ]]\ntype s struct {};",
+			permissive: true,
+		},
+		{
+			name:        "invalid known annotation in permissive mode",
+			input:       "[[ name = 10 ]]\ntype s struct {};",
+			permissive:  true,
+			expectedErr: analyzer.ErrInvalidAnnotationValue,
+		},
+		{
+			name:  "data annotation on enum member",
+			input: "type color enum {\n[[ label = \"red\" ]]\nRED\n}\n",
+		},
+		{
+			name:        "invalid known annotation on enum member",
+			input:       "type color enum {\n[[ doc = 1 ]]\nRED\n}\n",
+			expectedErr: analyzer.ErrInvalidAnnotationValue,
+		},
+		{
+			name:        "unknown annotation on enum",
+			input:       "[[ label = \"colors\" ]]\ntype color enum {\nRED\n}\n",
+			expectedErr: analyzer.ErrUnknownAnnotation,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := parser.NewFromString(tt.name, tt.input).Parse()
+			require.NoError(t, err)
+
+			registry := analyzer.DefaultRegistry()
+			registry.Register("json.name", analyzer.AnnotationKindString)
+			registry.Permissive = tt.permissive
+			actualErr := registry.Validate(schema)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+		})
+	}
+}
+
+func TestRegistry_Register(t *testing.T) {
+	registry := analyzer.NewRegistry()
+	registry.Register("json.name", analyzer.AnnotationKindString)
+
+	kind, ok := registry.Lookup("json.name")
+	require.True(t, ok)
+	require.Equal(t, analyzer.AnnotationKindString, kind)
+
+	_, ok = registry.Lookup("json")
+	require.False(t, ok)
+}
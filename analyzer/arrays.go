@@ -0,0 +1,62 @@
+package analyzer
+
+import (
+	"errors"
+
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+// ErrMisplacedFlexibleArray indicates that an array without size is not the trailing field of a struct
+var ErrMisplacedFlexibleArray = errors.New("misplaced flexible array")
+
+// ValidateFlexibleArrays checks that flexible array members (data : int[]) only appear as the last field of a
+// struct with at least one other field, returns all the errors joined
+func ValidateFlexibleArrays(schema *parser.Schema) error {
+	errs := make([]error, 0)
+	walkDecls(schema.Decls, func(decl parser.Decl) {
+		var typ parser.Expr
+		switch d := decl.(type) {
+		case *parser.TypeDecl:
+			typ = d.Type
+		case *parser.Field:
+			typ = d.Type
+		}
+
+		switch def := typ.(type) {
+		case *parser.StructDef:
+			errs = append(errs, validateFlexibleBlock(def.Block, true)...)
+		case *parser.UnionDef:
+			errs = append(errs, validateFlexibleBlock(def.Block, false)...)
+		}
+	})
+
+	return errors.Join(errs...)
+}
+
+func validateFlexibleBlock(block parser.Block, trailing bool) []error {
+	errs := make([]error, 0)
+	for i, decl := range block.Decls {
+		field, ok := unwrapDecl(decl).(*parser.Field)
+		if !ok || !isFlexibleArray(field.Type) {
+			continue
+		}
+
+		name := parser.LookupName(field.Name)
+		loc := parser.ExprLoc(field.Name)
+		switch {
+		case !trailing:
+			errs = append(errs, errorf(loc, ErrMisplacedFlexibleArray, "`%s` is only allowed in structs", name))
+		case i != len(block.Decls)-1:
+			errs = append(errs, errorf(loc, ErrMisplacedFlexibleArray, "`%s` must be the last field", name))
+		case i == 0:
+			errs = append(errs, errorf(loc, ErrMisplacedFlexibleArray, "`%s` needs at least one field before it", name))
+		}
+	}
+
+	return errs
+}
+
+func isFlexibleArray(expr parser.Expr) bool {
+	index, ok := expr.(*parser.Index)
+	return ok && index.Index == nil
+}
@@ -0,0 +1,60 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"github.com/cedmundo/SimpleSchema/analyzer"
+	"github.com/cedmundo/SimpleSchema/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateFlexibleArrays(t *testing.T) {
+	cases := []struct {
+		name        string
+		input       string
+		expectedErr error
+	}{
+		{
+			name:  "trailing flexible array",
+			input: "type s struct {\nlen : usize\ndata : u8[]\n}\n",
+		},
+		{
+			name:  "annotated trailing flexible array",
+			input: "type s struct {\nlen : usize\n[[ doc = \"payload\" ]]\ndata : u8[]\n}\n",
+		},
+		{
+			name:        "flexible array before another field",
+			input:       "type s struct {\ndata : u8[]\nlen : usize\n}\n",
+			expectedErr: analyzer.ErrMisplacedFlexibleArray,
+		},
+		{
+			name:        "flexible array as the only field",
+			input:       "type s struct {\ndata : u8[]\n}\n",
+			expectedErr: analyzer.ErrMisplacedFlexibleArray,
+		},
+		{
+			name:        "flexible array in a union",
+			input:       "type u union {\nlen : usize\ndata : u8[]\n}\n",
+			expectedErr: analyzer.ErrMisplacedFlexibleArray,
+		},
+		{
+			name:        "flexible array in an inline struct",
+			input:       "type s struct {\nheader : struct {\ndata : u8[]\nlen : usize\n}\n}\n",
+			expectedErr: analyzer.ErrMisplacedFlexibleArray,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := parser.NewFromString(tt.name, tt.input).Parse()
+			require.NoError(t, err)
+
+			actualErr := analyzer.ValidateFlexibleArrays(schema)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+		})
+	}
+}
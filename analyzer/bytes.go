@@ -0,0 +1,45 @@
+package analyzer
+
+import (
+	"errors"
+
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+// ErrByteStringLength indicates that a byte string (0x[CAFE]) does not have as many bytes as its array type
+var ErrByteStringLength = errors.New("byte string length mismatch")
+
+// ValidateByteStrings checks that the byte strings given as the default of a sized array field (magic : u8[4]) have
+// exactly as many bytes as the array. Sizes must be folded already (see FoldConstants), the ones that are not
+// literals are skipped. Returns all the errors joined.
+func ValidateByteStrings(schema *parser.Schema) error {
+	errs := make([]error, 0)
+	walkDecls(schema.Decls, func(decl parser.Decl) {
+		field, ok := decl.(*parser.Field)
+		if !ok {
+			return
+		}
+
+		bytes, ok := field.Value.(*parser.BytesLit)
+		if !ok {
+			return
+		}
+
+		index, ok := field.Type.(*parser.Index)
+		if !ok || index.Index == nil {
+			return
+		}
+
+		size, err := intValue(index.Index)
+		if err != nil {
+			return
+		}
+
+		if int64(len(bytes.Bytes)) != size {
+			errs = append(errs, errorf(bytes.Token.Loc, ErrByteStringLength, "`%s` has %d bytes, expected %d",
+				parser.LookupName(field.Name), len(bytes.Bytes), size))
+		}
+	})
+
+	return errors.Join(errs...)
+}
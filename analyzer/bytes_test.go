@@ -0,0 +1,53 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"github.com/cedmundo/SimpleSchema/analyzer"
+	"github.com/cedmundo/SimpleSchema/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateByteStrings(t *testing.T) {
+	cases := []struct {
+		name        string
+		input       string
+		expectedErr error
+	}{
+		{
+			name:  "matching length",
+			input: "type s struct {\nmagic : u8[4] = 0x[CAFEBABE]\n}\n",
+		},
+		{
+			name:  "folded length",
+			input: "const SIZE : int = 2;\ntype s struct {\nmagic : u8[SIZE] = 0x[CAFE]\n}\n",
+		},
+		{
+			name:        "too many bytes",
+			input:       "type s struct {\nmagic : u8[2] = 0x[CAFEBA]\n}\n",
+			expectedErr: analyzer.ErrByteStringLength,
+		},
+		{
+			name:        "too few bytes",
+			input:       "type s struct {\nmagic : u8[4] = 0x[CAFE]\n}\n",
+			expectedErr: analyzer.ErrByteStringLength,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := parser.NewFromString(tt.name, tt.input).Parse()
+			require.NoError(t, err)
+
+			_, err = analyzer.FoldConstants(schema)
+			require.NoError(t, err)
+
+			actualErr := analyzer.ValidateByteStrings(schema)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+		})
+	}
+}
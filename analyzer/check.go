@@ -0,0 +1,36 @@
+package analyzer
+
+import (
+	"errors"
+
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+// Check parses a schema and runs every analyzer pass over it without generating anything, it is meant for linters
+// and hooks. Returns the parsing error alone (the schema cannot be analyzed) or the errors of all passes joined,
+// nil means the schema is valid.
+func Check(src string, file string) error {
+	schema, err := parser.NewFromString(file, src).Parse()
+	if err != nil {
+		return err
+	}
+
+	_, resolveErr := Resolve(schema)
+	_, foldErr := FoldConstants(schema)
+	_, embedErr := ExpandEmbeds(schema)
+	return errors.Join(
+		resolveErr,
+		foldErr,
+		embedErr,
+		DefaultRegistry().Validate(schema),
+		ValidateFlexibleArrays(schema),
+		ValidateValueCycles(schema),
+		ValidateEnums(schema),
+		ValidateUnionTags(schema),
+		ValidateDecimals(schema),
+		ValidateAlignments(schema),
+		ValidateByteStrings(schema),
+		ValidateFlags(schema),
+		MarkDeprecated(schema),
+	)
+}
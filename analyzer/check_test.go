@@ -0,0 +1,85 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"github.com/cedmundo/SimpleSchema/analyzer"
+	"github.com/cedmundo/SimpleSchema/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	cases := []struct {
+		name         string
+		input        string
+		expectedErrs []error
+	}{
+		{
+			name:  "valid schema",
+			input: "[[ doc = \"a node\" ]]\ntype node struct {\nnext : *node\nvalue : vec2\n}\ntype vec2 float[2];\n",
+		},
+		{
+			name:  "tagged union with a synthesized tag",
+			input: "[[ tagged ]] type shape union { circle : float }\n",
+		},
+		{
+			name: "enum table annotations",
+			input: "type color enum {\n[[ rgb = [255, 0, 0], label = \"red\" ]]\nRED\n" +
+				"[[ rgb = [0, 255, 0], label = \"green\", deprecated ]]\nGREEN\n}\n",
+		},
+		{
+			name: "namespaced annotations",
+			input: "[[ binding_name = \"hello world\" ]]\n[[ debug_info.name = \"hello friend\" ]]\n" +
+				"type example1 struct {\n[[ endianess = \"be\" ]]\nfield1 : u32\n}\n" +
+				"type example2 enum {\n[[ py_fmd.name = \"red_color\" ]]\nRED\n}\n",
+		},
+		{
+			name:         "syntax error",
+			input:        "type = int;",
+			expectedErrs: []error{parser.ErrUnexpectedToken},
+		},
+		{
+			name:         "unresolved type",
+			input:        "type s struct { v : vec3; };",
+			expectedErrs: []error{analyzer.ErrUnresolvedSymbol},
+		},
+		{
+			name:         "duplicate type",
+			input:        "type s int;\ntype s u8;",
+			expectedErrs: []error{analyzer.ErrDuplicateSymbol},
+		},
+		{
+			name:         "unknown annotation",
+			input:        "[[ colour = 1 ]]\ntype s int;",
+			expectedErrs: []error{analyzer.ErrUnknownAnnotation},
+		},
+		{
+			name:         "value cycle",
+			input:        "type a struct { b : b; };\ntype b struct { a : a; };",
+			expectedErrs: []error{analyzer.ErrValueCycle},
+		},
+		{
+			name:         "duplicate enum value",
+			input:        "type flags enum {\nA = 0x01\nB = 0b1\n}\n",
+			expectedErrs: []error{analyzer.ErrDuplicateEnumValue},
+		},
+		{
+			name:         "errors of every pass are joined",
+			input:        "type a struct { data : u8[]; b : missing; };\ntype a int;",
+			expectedErrs: []error{analyzer.ErrMisplacedFlexibleArray, analyzer.ErrUnresolvedSymbol, analyzer.ErrDuplicateSymbol},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualErr := analyzer.Check(tt.input, tt.name)
+			if len(tt.expectedErrs) == 0 {
+				require.NoError(t, actualErr)
+				return
+			}
+
+			for _, expectedErr := range tt.expectedErrs {
+				require.ErrorIs(t, actualErr, expectedErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package analyzer
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/cedmundo/SimpleSchema/lexer"
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+var (
+	// ErrNonConstant indicates that an expression cannot be evaluated at compile time
+	ErrNonConstant = errors.New("non-constant expression")
+
+	// ErrDivisionByZero indicates that a constant expression divides by zero
+	ErrDivisionByZero = errors.New("division by zero")
+
+	// ErrConstOverflow indicates that a constant expression does not fit in 64 bits
+	ErrConstOverflow = errors.New("constant overflow")
+
+	// ErrInvalidArraySize indicates that a constant array size does not fold to a positive integer
+	ErrInvalidArraySize = errors.New("invalid array size")
+)
+
+// EvalConst folds an integer expression into its value, names are looked up in consts. Integer literals of any
+// base and the operators + - * / % << >> & | ^ ** are supported, along with the unary - + ~. Anything else is a
+// non-constant operand, powers that do not fit in 64 bits are reported with ErrConstOverflow.
+func EvalConst(expr parser.Expr, consts map[string]int64) (int64, error) {
+	switch e := expr.(type) {
+	case *parser.Literal:
+		value, err := intValue(e)
+		if err != nil {
+			return 0, errorf(e.Token.Loc, ErrNonConstant, "`%s` is not an integer", e.Token.Value)
+		}
+		return value, nil
+	case *parser.Ident:
+		value, ok := consts[e.Token.Value]
+		if !ok {
+			return 0, errorf(e.Token.Loc, ErrNonConstant, "`%s` is not a constant", e.Token.Value)
+		}
+		return value, nil
+	case *parser.UnaryOp:
+		operand, err := EvalConst(e.Operand, consts)
+		if err != nil {
+			return 0, err
+		}
+
+		switch e.Operator.Value {
+		case "-":
+			return -operand, nil
+		case "+":
+			return operand, nil
+		case "~":
+			return ^operand, nil
+		}
+
+		return 0, errorf(e.Operator.Loc, ErrNonConstant, "unsupported operator `%s`", e.Operator.Value)
+	case *parser.BinaryOp:
+		return evalBinary(e, consts)
+	}
+
+	return 0, errorf(parser.ExprLoc(expr), ErrNonConstant, "%T", expr)
+}
+
+func evalBinary(op *parser.BinaryOp, consts map[string]int64) (int64, error) {
+	left, err := EvalConst(op.Left, consts)
+	if err != nil {
+		return 0, err
+	}
+
+	right, err := EvalConst(op.Right, consts)
+	if err != nil {
+		return 0, err
+	}
+
+	switch op.Operator.Value {
+	case "+":
+		return left + right, nil
+	case "-":
+		return left - right, nil
+	case "*":
+		return left * right, nil
+	case "/", "%":
+		if right == 0 {
+			return 0, errorf(op.Operator.Loc, ErrDivisionByZero, "`%s` by zero", op.Operator.Value)
+		}
+
+		if op.Operator.Value == "/" {
+			return left / right, nil
+		}
+		return left % right, nil
+	case "<<", ">>":
+		if right < 0 {
+			return 0, errorf(op.Operator.Loc, ErrNonConstant, "negative shift count %d", right)
+		}
+
+		if op.Operator.Value == "<<" {
+			return left << right, nil
+		}
+		return left >> right, nil
+	case "&":
+		return left & right, nil
+	case "|":
+		return left | right, nil
+	case "^":
+		return left ^ right, nil
+	case "**":
+		if right < 0 {
+			return 0, errorf(op.Operator.Loc, ErrNonConstant, "negative exponent %d", right)
+		}
+
+		result, ok := pow(left, right)
+		if !ok {
+			return 0, errorf(op.Operator.Loc, ErrConstOverflow, "%d ** %d does not fit in 64 bits", left, right)
+		}
+		return result, nil
+	}
+
+	return 0, errorf(op.Operator.Loc, ErrNonConstant, "unsupported operator `%s`", op.Operator.Value)
+}
+
+// pow raises base to a non-negative exponent by squaring, reports false when the result overflows
+func pow(base, exponent int64) (int64, bool) {
+	result := int64(1)
+	for exponent > 0 {
+		if exponent&1 == 1 {
+			product, ok := mul(result, base)
+			if !ok {
+				return 0, false
+			}
+			result = product
+		}
+
+		exponent >>= 1
+		if exponent == 0 {
+			break
+		}
+
+		square, ok := mul(base, base)
+		if !ok {
+			return 0, false
+		}
+		base = square
+	}
+
+	return result, true
+}
+
+// mul multiplies two integers, reports false when the product overflows
+func mul(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+
+	product := a * b
+	if product/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+
+	return product, true
+}
+
+// FoldConstants evaluates the constants of the schema in declaration order (a constant may only use the ones
+// declared before it) and replaces the constant array sizes that are not plain literals by their value, so
+// int[SIZE * 2] becomes int[64]. Constant sizes must be positive, zero and negative ones are reported with
+// ErrInvalidArraySize. Returns the value of every valid constant and all the errors joined.
+func FoldConstants(schema *parser.Schema) (map[string]int64, error) {
+	consts := make(map[string]int64)
+	errs := make([]error, 0)
+	for _, decl := range schema.Decls {
+		constDecl, ok := unwrapDecl(decl).(*parser.ConstDecl)
+		if !ok {
+			continue
+		}
+
+		value, err := EvalConst(constDecl.Value, consts)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		consts[parser.LookupName(constDecl.Name)] = value
+	}
+
+	walkDecls(schema.Decls, func(decl parser.Decl) {
+		switch d := decl.(type) {
+		case *parser.TypeDecl:
+			errs = append(errs, foldSizes(d.Type, consts)...)
+		case *parser.ProcDecl:
+			errs = append(errs, foldSizes(d.Type, consts)...)
+		case *parser.Field:
+			errs = append(errs, foldSizes(d.Type, consts)...)
+		}
+	})
+
+	return consts, errors.Join(errs...)
+}
+
+// foldSizes replaces the array sizes of a type expression, inline blocks are skipped since walkDecl visits their
+// fields on their own
+func foldSizes(expr parser.Expr, consts map[string]int64) []error {
+	errs := make([]error, 0)
+	switch e := expr.(type) {
+	case *parser.Index:
+		errs = append(errs, foldSizes(e.Base, consts)...)
+		if e.Index == nil {
+			break
+		}
+
+		// sizes referencing other fields (values : u8[count]) are not constant and stay as written
+		value, err := EvalConst(e.Index, consts)
+		if errors.Is(err, ErrNonConstant) {
+			break
+		} else if err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		// C has no zero-length arrays, a trailing array without size (data : u8[]) is the flexible member
+		if value <= 0 {
+			errs = append(errs, errorf(parser.ExprLoc(e.Index), ErrInvalidArraySize,
+				"array size must be positive, got %d", value))
+			break
+		}
+
+		if _, ok := e.Index.(*parser.Literal); ok {
+			break
+		}
+
+		e.Index = &parser.Literal{Token: lexer.Token{
+			Tag:   lexer.TokenTagDecInt,
+			Loc:   parser.ExprLoc(e.Index),
+			Value: strconv.FormatInt(value, 10),
+		}}
+	case *parser.UnaryOp:
+		errs = append(errs, foldSizes(e.Operand, consts)...)
+	case *parser.OptionalType:
+		errs = append(errs, foldSizes(e.Type, consts)...)
+	case *parser.Call:
+		for _, arg := range e.Args {
+			errs = append(errs, foldSizes(arg, consts)...)
+		}
+	case *parser.SetType:
+		errs = append(errs, foldSizes(e.Element, consts)...)
+	case *parser.MapType:
+		errs = append(errs, foldSizes(e.Key, consts)...)
+		errs = append(errs, foldSizes(e.Value, consts)...)
+	case *parser.TupleType:
+		for _, element := range e.Elements {
+			errs = append(errs, foldSizes(element, consts)...)
+		}
+	case *parser.PrototypeDef:
+		errs = append(errs, foldSizes(e.ReturnType, consts)...)
+	}
+
+	return errs
+}
@@ -0,0 +1,206 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"github.com/cedmundo/SimpleSchema/analyzer"
+	"github.com/cedmundo/SimpleSchema/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvalConst(t *testing.T) {
+	cases := []struct {
+		name          string
+		input         string
+		consts        map[string]int64
+		expectedValue int64
+		expectedErr   error
+	}{
+		{
+			name:          "precedence",
+			input:         "2 + 4 * 8 - 6 / 3",
+			expectedValue: 32,
+		},
+		{
+			name:          "groups and modulo",
+			input:         "(2 + 5) % 4",
+			expectedValue: 3,
+		},
+		{
+			name:          "shifts",
+			input:         "1 << 4 >> 2",
+			expectedValue: 4,
+		},
+		{
+			name:          "bitwise operators",
+			input:         "0xF0 & 0x3C | 0b1 ^ 3",
+			expectedValue: 0x30 | (1 ^ 3),
+		},
+		{
+			name:          "power",
+			input:         "2 ** 3 ** 2",
+			expectedValue: 512,
+		},
+		{
+			name:          "largest power",
+			input:         "(-2) ** 63",
+			expectedValue: -1 << 63,
+		},
+		{
+			name:          "huge power of one",
+			input:         "1 ** 9000000000000",
+			expectedValue: 1,
+		},
+		{
+			name:          "unary operators",
+			input:         "-(~0) + -4",
+			expectedValue: -3,
+		},
+		{
+			name:          "constant references",
+			input:         "SIZE * 2",
+			consts:        map[string]int64{"SIZE": 32},
+			expectedValue: 64,
+		},
+		{
+			name:        "unknown name",
+			input:       "SIZE * 2",
+			expectedErr: analyzer.ErrNonConstant,
+		},
+		{
+			name:        "string operand",
+			input:       "\"four\" + 1",
+			expectedErr: analyzer.ErrNonConstant,
+		},
+		{
+			name:        "call operand",
+			input:       "len(x)",
+			expectedErr: analyzer.ErrNonConstant,
+		},
+		{
+			name:        "logical operator",
+			input:       "1 && 2",
+			expectedErr: analyzer.ErrNonConstant,
+		},
+		{
+			name:        "division by zero",
+			input:       "4 / (2 - 2)",
+			expectedErr: analyzer.ErrDivisionByZero,
+		},
+		{
+			name:        "power overflow",
+			input:       "2 ** 9000000000000",
+			expectedErr: analyzer.ErrConstOverflow,
+		},
+		{
+			name:        "power overflow by one",
+			input:       "2 ** 63",
+			expectedErr: analyzer.ErrConstOverflow,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := parser.NewFromString(tt.name, tt.input).ParseExpr()
+			require.NoError(t, err)
+
+			actualValue, actualErr := analyzer.EvalConst(expr, tt.consts)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+			require.Equal(t, tt.expectedValue, actualValue)
+		})
+	}
+}
+
+func TestFoldConstants(t *testing.T) {
+	cases := []struct {
+		name           string
+		input          string
+		expectedConsts map[string]int64
+		expectedSize   string // of the first field of the struct s, empty when it is not folded
+		expectedErr    error
+	}{
+		{
+			name:           "folds a constant array size",
+			input:          "const SIZE : int = 4 * 8;\ntype s struct {\ndata : u8[SIZE]\n}\n",
+			expectedConsts: map[string]int64{"SIZE": 32},
+			expectedSize:   "32",
+		},
+		{
+			name:           "constants use the previous ones",
+			input:          "const A : int = 2;\nconst B : int = A << 3;\ntype s struct {\ndata : u8[B + A]\n}\n",
+			expectedConsts: map[string]int64{"A": 2, "B": 16},
+			expectedSize:   "18",
+		},
+		{
+			name:           "keeps literal sizes",
+			input:          "type s struct {\ndata : u8[0x10]\n}\n",
+			expectedConsts: map[string]int64{},
+			expectedSize:   "10",
+		},
+		{
+			name:           "keeps dependant array sizes",
+			input:          "type s struct {\ndata : u8[count * 2]\ncount : u32\n}\n",
+			expectedConsts: map[string]int64{},
+		},
+		{
+			name:        "constant declared later",
+			input:       "const A : int = B;\nconst B : int = 1;\n",
+			expectedErr: analyzer.ErrNonConstant,
+		},
+		{
+			name:        "division by zero in array size",
+			input:       "type s struct {\ndata : u8[4 % 0]\n}\n",
+			expectedErr: analyzer.ErrDivisionByZero,
+		},
+		{
+			name:        "negative array size",
+			input:       "type s struct {\ndata : int[-1]\n}\n",
+			expectedErr: analyzer.ErrInvalidArraySize,
+		},
+		{
+			name:        "negative constant array size",
+			input:       "const N : int = -5;\ntype s struct {\ndata : int[N]\n}\n",
+			expectedErr: analyzer.ErrInvalidArraySize,
+		},
+		{
+			name:        "zero array size",
+			input:       "type s struct {\ndata : int[0]\n}\n",
+			expectedErr: analyzer.ErrInvalidArraySize,
+		},
+		{
+			name:        "zero folded array size",
+			input:       "const N : int = 4;\ntype s struct {\ndata : int[N - 4]\n}\n",
+			expectedErr: analyzer.ErrInvalidArraySize,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := parser.NewFromString(tt.name, tt.input).Parse()
+			require.NoError(t, err)
+
+			actualConsts, actualErr := analyzer.FoldConstants(schema)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+			require.Equal(t, tt.expectedConsts, actualConsts)
+
+			decl := schema.Decls[len(schema.Decls)-1].(*parser.TypeDecl)
+			field := decl.Type.(*parser.StructDef).Block.Decls[0].(*parser.Field)
+			size, folded := field.Type.(*parser.Index).Index.(*parser.Literal)
+			if tt.expectedSize == "" {
+				require.False(t, folded)
+				return
+			}
+
+			require.True(t, folded)
+			require.Equal(t, tt.expectedSize, size.Token.Value)
+		})
+	}
+}
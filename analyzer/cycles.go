@@ -0,0 +1,141 @@
+package analyzer
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+// ErrValueCycle indicates that types contain each other by value, such types would have an infinite size
+var ErrValueCycle = errors.New("value cycle")
+
+// valueEdge is a member of a type holding another type by value
+type valueEdge struct {
+	member string
+	target string
+}
+
+// ValidateValueCycles checks that no type contains itself by value, directly or through other types. Cycles are
+// allowed as long as they pass through a pointer (including arrays of pointers). Each cycle is reported once with
+// the member chain that forms it, returns all the errors joined.
+func ValidateValueCycles(schema *parser.Schema) error {
+	names := make([]string, 0)
+	decls := make(map[string]*parser.TypeDecl)
+	edges := make(map[string][]valueEdge)
+	for _, decl := range schema.Decls {
+		typeDecl, ok := unwrapDecl(decl).(*parser.TypeDecl)
+		if !ok {
+			continue
+		}
+
+		name := parser.LookupName(typeDecl.Name)
+		names = append(names, name)
+		decls[name] = typeDecl
+		collectValueEdges(name, typeDecl.Type, func(edge valueEdge) {
+			edges[name] = append(edges[name], edge)
+		})
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	errs := make([]error, 0)
+	state := make(map[string]int)
+	var visit func(name string, chain []valueEdge)
+	visit = func(name string, chain []valueEdge) {
+		state[name] = visiting
+		for _, edge := range edges[name] {
+			if _, ok := decls[edge.target]; !ok {
+				continue
+			}
+
+			switch state[edge.target] {
+			case visiting:
+				cycle := append(chain, edge)
+				for i, link := range cycle {
+					if strings.HasPrefix(link.member, edge.target+".") || link.member == edge.target {
+						cycle = cycle[i:]
+						break
+					}
+				}
+
+				members := make([]string, 0, len(cycle))
+				for _, link := range cycle {
+					members = append(members, link.member)
+				}
+
+				loc := parser.ExprLoc(decls[edge.target].Name)
+				errs = append(errs, errorf(loc, ErrValueCycle, "%s -> %s", strings.Join(members, " -> "), edge.target))
+			case unvisited:
+				visit(edge.target, append(chain, edge))
+			}
+		}
+		state[name] = visited
+	}
+
+	for _, name := range names {
+		if state[name] == unvisited {
+			visit(name, nil)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// collectValueEdges calls fn for every type held by value within a type expression, the member is the dotted path
+// from the declared type to the field holding it
+func collectValueEdges(member string, expr parser.Expr, fn func(valueEdge)) {
+	switch e := expr.(type) {
+	case *parser.Ident:
+		fn(valueEdge{member: member, target: e.Token.Value})
+	case *parser.QualifiedName:
+		fn(valueEdge{member: member, target: parser.LookupName(e)})
+	case *parser.BinaryOp:
+		if e.Operator.Value == "." {
+			fn(valueEdge{member: member, target: parser.LookupName(e)})
+		}
+	case *parser.UnaryOp:
+		// anything behind a pointer has a known size
+		if e.Operator.Value != "*" {
+			collectValueEdges(member, e.Operand, fn)
+		}
+	case *parser.Call:
+		for _, arg := range e.Args {
+			collectValueEdges(member, arg, fn)
+		}
+	case *parser.Index:
+		collectValueEdges(member, e.Base, fn)
+	case *parser.OptionalType:
+		// optionals hold their value unless desugared as pointers, recursive ones are written as ?*T
+		collectValueEdges(member, e.Type, fn)
+	case *parser.TupleType:
+		for _, element := range e.Elements {
+			collectValueEdges(member, element, fn)
+		}
+	case *parser.StructDef:
+		collectBlockEdges(member, e.Block, fn)
+	case *parser.UnionDef:
+		collectBlockEdges(member, e.Block, fn)
+	}
+}
+
+func collectBlockEdges(member string, block parser.Block, fn func(valueEdge)) {
+	for _, decl := range block.Decls {
+		// included fields are held by value right in the block
+		if include, ok := decl.(*parser.EmbedDecl); ok {
+			collectValueEdges(member, include.Type, fn)
+			continue
+		}
+
+		field, ok := unwrapDecl(decl).(*parser.Field)
+		if !ok || field.Type == nil {
+			continue
+		}
+
+		collectValueEdges(member+"."+parser.LookupName(field.Name), field.Type, fn)
+	}
+}
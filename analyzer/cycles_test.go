@@ -0,0 +1,70 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"github.com/cedmundo/SimpleSchema/analyzer"
+	"github.com/cedmundo/SimpleSchema/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateValueCycles(t *testing.T) {
+	cases := []struct {
+		name        string
+		input       string
+		expectedErr error
+		expectedMsg string
+	}{
+		{
+			name:  "no cycles",
+			input: "type a struct {\nx : int\n}\ntype b struct {\na : a[2]\n}\n",
+		},
+		{
+			name:  "pointer cycle",
+			input: "type a struct {\nb : b\n}\ntype b struct {\na : *a\n}\n",
+		},
+		{
+			name:  "array of pointers cycle",
+			input: "type node struct {\nchildren : (*node)[4]\n}\n",
+		},
+		{
+			name:        "self value cycle",
+			input:       "type node struct {\nnext : node\n}\n",
+			expectedErr: analyzer.ErrValueCycle,
+			expectedMsg: "node.next -> node",
+		},
+		{
+			name:        "value cycle",
+			input:       "type a struct {\nb : b\n}\ntype b struct {\nx : int\na : a\n}\n",
+			expectedErr: analyzer.ErrValueCycle,
+			expectedMsg: "a.b -> b.a -> a",
+		},
+		{
+			name:        "value cycle through arrays and inline structs",
+			input:       "type a struct {\ninner : struct {\nb : b[2]\n}\n}\ntype b union {\na : const(a)\n}\n",
+			expectedErr: analyzer.ErrValueCycle,
+			expectedMsg: "a.inner.b -> b.a -> a",
+		},
+		{
+			name:        "value cycle through a typedef",
+			input:       "type a struct {\nb : pair\n}\ntype pair a[2];\n",
+			expectedErr: analyzer.ErrValueCycle,
+			expectedMsg: "a.b -> pair -> a",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := parser.NewFromString(tt.name, tt.input).Parse()
+			require.NoError(t, err)
+
+			actualErr := analyzer.ValidateValueCycles(schema)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				require.ErrorContains(t, actualErr, tt.expectedMsg)
+				return
+			}
+
+			require.NoError(t, actualErr)
+		})
+	}
+}
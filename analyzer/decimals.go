@@ -0,0 +1,71 @@
+package analyzer
+
+import (
+	"errors"
+
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+// ErrInvalidDecimal indicates that a decimal type has no digits or more digits after the point than in total
+var ErrInvalidDecimal = errors.New("invalid decimal")
+
+// ValidateDecimals checks that every decimal type has a positive precision of at least its scale
+// (decimal<10, 2>), returns all the errors joined
+func ValidateDecimals(schema *parser.Schema) error {
+	errs := make([]error, 0)
+	walkDecls(schema.Decls, func(decl parser.Decl) {
+		switch d := decl.(type) {
+		case *parser.TypeDecl:
+			errs = append(errs, validateDecimals(d.Type)...)
+		case *parser.ProcDecl:
+			errs = append(errs, validateDecimals(d.Type)...)
+		case *parser.Field:
+			errs = append(errs, validateDecimals(d.Type)...)
+		}
+	})
+
+	return errors.Join(errs...)
+}
+
+// validateDecimals checks the decimals of a type expression, inline blocks are skipped since walkDecl visits their
+// fields on their own
+func validateDecimals(expr parser.Expr) []error {
+	errs := make([]error, 0)
+	switch e := expr.(type) {
+	case *parser.DecimalType:
+		precision, precisionErr := intValue(e.Precision)
+		scale, scaleErr := intValue(e.Scale)
+		loc := parser.ExprLoc(e.Precision)
+		switch {
+		case precisionErr != nil || scaleErr != nil:
+			errs = append(errs, errorf(loc, ErrInvalidDecimal, "precision and scale must be integers"))
+		case precision <= 0:
+			errs = append(errs, errorf(loc, ErrInvalidDecimal, "precision %d must be positive", precision))
+		case scale > precision:
+			errs = append(errs, errorf(loc, ErrInvalidDecimal, "scale %d is greater than precision %d", scale, precision))
+		}
+	case *parser.Index:
+		errs = append(errs, validateDecimals(e.Base)...)
+	case *parser.UnaryOp:
+		errs = append(errs, validateDecimals(e.Operand)...)
+	case *parser.OptionalType:
+		errs = append(errs, validateDecimals(e.Type)...)
+	case *parser.Call:
+		for _, arg := range e.Args {
+			errs = append(errs, validateDecimals(arg)...)
+		}
+	case *parser.SetType:
+		errs = append(errs, validateDecimals(e.Element)...)
+	case *parser.MapType:
+		errs = append(errs, validateDecimals(e.Key)...)
+		errs = append(errs, validateDecimals(e.Value)...)
+	case *parser.TupleType:
+		for _, element := range e.Elements {
+			errs = append(errs, validateDecimals(element)...)
+		}
+	case *parser.PrototypeDef:
+		errs = append(errs, validateDecimals(e.ReturnType)...)
+	}
+
+	return errs
+}
@@ -0,0 +1,55 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"github.com/cedmundo/SimpleSchema/analyzer"
+	"github.com/cedmundo/SimpleSchema/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateDecimals(t *testing.T) {
+	cases := []struct {
+		name        string
+		input       string
+		expectedErr error
+	}{
+		{
+			name:  "valid decimal",
+			input: "type money decimal<10, 2>;\n",
+		},
+		{
+			name:  "scale equal to precision",
+			input: "type ratio decimal<4, 4>;\n",
+		},
+		{
+			name:        "scale greater than precision",
+			input:       "type money decimal<2, 10>;\n",
+			expectedErr: analyzer.ErrInvalidDecimal,
+		},
+		{
+			name:        "zero precision",
+			input:       "type money decimal<0, 0>;\n",
+			expectedErr: analyzer.ErrInvalidDecimal,
+		},
+		{
+			name:        "invalid field decimal",
+			input:       "type s struct {\nprices : decimal<2, 3>[4]\n}\n",
+			expectedErr: analyzer.ErrInvalidDecimal,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := parser.NewFromString(tt.name, tt.input).Parse()
+			require.NoError(t, err)
+
+			actualErr := analyzer.ValidateDecimals(schema)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package analyzer
+
+import (
+	"errors"
+
+	"github.com/cedmundo/SimpleSchema/lexer"
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+// MarkDeprecated fills the deprecation metadata of every declaration annotated with [[ deprecated ]], the
+// annotation is either a flag or takes a string message. Returns all the malformed annotations joined.
+func MarkDeprecated(schema *parser.Schema) error {
+	errs := make([]error, 0)
+	walkDecls(schema.Decls, func(decl parser.Decl) {
+		annotated, ok := decl.(*parser.AnnotatedDecl)
+		if !ok {
+			return
+		}
+
+		annotation, ok := annotated.Find("deprecated")
+		if !ok {
+			return
+		}
+
+		if annotation.Value == nil {
+			annotated.Deprecated = true
+			return
+		}
+
+		literal, ok := annotation.Value.(*parser.Literal)
+		if !ok || literal.Token.Tag != lexer.TokenTagString {
+			errs = append(errs, errorf(parser.ExprLoc(annotation.Name), ErrInvalidAnnotationValue,
+				"`deprecated` is a flag or expects a string value"))
+			return
+		}
+
+		annotated.Deprecated = true
+		annotated.DeprecationMessage = literal.Token.Value
+	})
+
+	return errors.Join(errs...)
+}
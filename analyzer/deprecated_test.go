@@ -0,0 +1,67 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"github.com/cedmundo/SimpleSchema/analyzer"
+	"github.com/cedmundo/SimpleSchema/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkDeprecated(t *testing.T) {
+	cases := []struct {
+		name               string
+		input              string
+		expectedDeprecated bool
+		expectedMessage    string
+		expectedErr        error
+	}{
+		{
+			name:               "bare flag",
+			input:              "[[ deprecated ]]\ntype s int;",
+			expectedDeprecated: true,
+		},
+		{
+			name:               "with a message",
+			input:              "[[ deprecated = \"use t\" ]]\ntype s int;",
+			expectedDeprecated: true,
+			expectedMessage:    "use t",
+		},
+		{
+			name:  "other annotations",
+			input: "[[ size = 4 ]]\ntype s int;",
+		},
+		{
+			name:        "non string message",
+			input:       "[[ deprecated = 1 ]]\ntype s int;",
+			expectedErr: analyzer.ErrInvalidAnnotationValue,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := parser.NewFromString(tt.name, tt.input).Parse()
+			require.NoError(t, err)
+
+			actualErr := analyzer.MarkDeprecated(schema)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+			annotated := schema.Decls[0].(*parser.AnnotatedDecl)
+			require.Equal(t, tt.expectedDeprecated, annotated.Deprecated)
+			require.Equal(t, tt.expectedMessage, annotated.DeprecationMessage)
+		})
+	}
+}
+
+func TestMarkDeprecated_Fields(t *testing.T) {
+	schema, err := parser.NewFromString("fields", "type s struct {\n@deprecated(\"use b\")\na : int\nb : int\n}\n").Parse()
+	require.NoError(t, err)
+	require.NoError(t, analyzer.MarkDeprecated(schema))
+
+	field := schema.Decls[0].(*parser.TypeDecl).Type.(*parser.StructDef).Block.Decls[0].(*parser.AnnotatedDecl)
+	require.True(t, field.Deprecated)
+	require.Equal(t, "use b", field.DeprecationMessage)
+}
@@ -0,0 +1,86 @@
+package analyzer
+
+import (
+	"github.com/cedmundo/SimpleSchema/lexer"
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+// DesugarOptions picks the canonical forms of the sugared types
+type DesugarOptions struct {
+	// OptionalPointers rewrites ?T as *T (null when absent) instead of struct { has : bool; value : T }, optional
+	// pointers (?*T) are already nullable and stay as *T
+	OptionalPointers bool
+}
+
+// Desugar returns a copy of the schema where the sugared types are rewritten into their canonical forms, so the
+// backends do not need to know about them. Optional types (?T) become a struct pairing a presence flag and the
+// value, or a pointer (see DesugarOptions). The schema itself is left untouched.
+func Desugar(schema *parser.Schema, options DesugarOptions) *parser.Schema {
+	desugared := parser.CloneSchema(schema)
+	walkDecls(desugared.Decls, func(decl parser.Decl) {
+		// walkDecl visits the rewritten type next, so the optionals within the new fields are desugared as well
+		switch d := decl.(type) {
+		case *parser.TypeDecl:
+			d.Type = desugarType(d.Type, options)
+		case *parser.ProcDecl:
+			d.Type = desugarType(d.Type, options)
+		case *parser.ConstDecl:
+			d.Type = desugarType(d.Type, options)
+		case *parser.Field:
+			d.Type = desugarType(d.Type, options)
+		}
+	})
+
+	return desugared
+}
+
+// desugarType rewrites the optionals of a type expression, inline blocks are skipped since walkDecl visits their
+// fields on their own
+func desugarType(expr parser.Expr, options DesugarOptions) parser.Expr {
+	switch e := expr.(type) {
+	case *parser.OptionalType:
+		return desugarOptional(e, options)
+	case *parser.UnaryOp:
+		e.Operand = desugarType(e.Operand, options)
+	case *parser.Index:
+		e.Base = desugarType(e.Base, options)
+	case *parser.Call:
+		for i, arg := range e.Args {
+			e.Args[i] = desugarType(arg, options)
+		}
+	case *parser.SetType:
+		e.Element = desugarType(e.Element, options)
+	case *parser.MapType:
+		e.Key = desugarType(e.Key, options)
+		e.Value = desugarType(e.Value, options)
+	case *parser.TupleType:
+		for i, element := range e.Elements {
+			e.Elements[i] = desugarType(element, options)
+		}
+	case *parser.PrototypeDef:
+		e.ReturnType = desugarType(e.ReturnType, options)
+	}
+
+	return expr
+}
+
+func desugarOptional(optional *parser.OptionalType, options DesugarOptions) parser.Expr {
+	typ := desugarType(optional.Type, options)
+	if !options.OptionalPointers {
+		word := func(value string) *parser.Ident {
+			return &parser.Ident{Token: lexer.Token{Tag: lexer.TokenTagWord, Loc: optional.Token.Loc, Value: value}}
+		}
+
+		return &parser.StructDef{Block: parser.Block{Decls: []parser.Decl{
+			&parser.Field{Name: word("has"), Type: word("bool")},
+			&parser.Field{Name: word("value"), Type: typ},
+		}}}
+	}
+
+	if pointer, ok := typ.(*parser.UnaryOp); ok && pointer.Operator.Value == "*" && !pointer.Postfix {
+		return typ
+	}
+
+	star := lexer.Token{Tag: lexer.TokenTagPunct, Loc: optional.Token.Loc, Value: "*"}
+	return &parser.UnaryOp{Operator: star, Operand: typ}
+}
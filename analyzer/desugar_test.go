@@ -0,0 +1,86 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"github.com/cedmundo/SimpleSchema/analyzer"
+	"github.com/cedmundo/SimpleSchema/lexer"
+	"github.com/cedmundo/SimpleSchema/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDesugar(t *testing.T) {
+	word := func(value string) *parser.Ident {
+		return &parser.Ident{Token: lexer.Token{Tag: lexer.TokenTagWord, Value: value}}
+	}
+	pointer := func(typ parser.Expr) *parser.UnaryOp {
+		return &parser.UnaryOp{Operator: lexer.Token{Tag: lexer.TokenTagPunct, Value: "*"}, Operand: typ}
+	}
+	present := func(typ parser.Expr) *parser.StructDef {
+		return &parser.StructDef{Block: parser.Block{Decls: []parser.Decl{
+			&parser.Field{Name: word("has"), Type: word("bool")},
+			&parser.Field{Name: word("value"), Type: typ},
+		}}}
+	}
+
+	cases := []struct {
+		name         string
+		input        string
+		options      analyzer.DesugarOptions
+		expectedType parser.Expr // of the first field of the struct s
+	}{
+		{
+			name:         "optional as struct",
+			input:        "type s struct {\na : ?int\n}\n",
+			expectedType: present(word("int")),
+		},
+		{
+			name:         "nested optionals",
+			input:        "type s struct {\na : ??int\n}\n",
+			expectedType: present(present(word("int"))),
+		},
+		{
+			name:         "array of optionals",
+			input:        "type s struct {\na : (?u8)[4]\n}\n",
+			expectedType: &parser.Index{Base: present(word("u8")), Index: &parser.Literal{Token: lexer.Token{Tag: lexer.TokenTagDecInt, Value: "4"}}},
+		},
+		{
+			name:         "optional as pointer",
+			input:        "type s struct {\na : ?int\n}\n",
+			options:      analyzer.DesugarOptions{OptionalPointers: true},
+			expectedType: pointer(word("int")),
+		},
+		{
+			name:         "optional pointer stays a pointer",
+			input:        "type s struct {\na : ?*int\n}\n",
+			options:      analyzer.DesugarOptions{OptionalPointers: true},
+			expectedType: pointer(word("int")),
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := parser.NewFromString(tt.name, tt.input).Parse()
+			require.NoError(t, err)
+			original := parser.CloneSchema(schema)
+
+			desugared := analyzer.Desugar(schema, tt.options)
+			decl := desugared.Decls[0].(*parser.TypeDecl)
+			field := decl.Type.(*parser.StructDef).Block.Decls[0].(*parser.Field)
+			require.Empty(t, parser.Diff(tt.expectedType, field.Type))
+			require.Empty(t, parser.Diff(original, schema), "the schema is not modified")
+		})
+	}
+}
+
+func TestDesugar_ProcOptionals(t *testing.T) {
+	schema, err := parser.NewFromString("proc", "proc find(key : ?string) -> ?*int;\n").Parse()
+	require.NoError(t, err)
+
+	desugared := analyzer.Desugar(schema, analyzer.DesugarOptions{OptionalPointers: true})
+	def := desugared.Decls[0].(*parser.ProcDecl).Type.(*parser.PrototypeDef)
+	key, ok := def.Params[0].Type.(*parser.UnaryOp)
+	require.True(t, ok)
+	require.Equal(t, "string", parser.LookupName(key.Operand))
+	_, ok = def.ReturnType.(*parser.UnaryOp)
+	require.True(t, ok)
+}
@@ -0,0 +1,147 @@
+package analyzer
+
+import (
+	"errors"
+
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+var (
+	// ErrInvalidEmbed indicates that an include does not name a struct of the schema or is not within a struct
+	ErrInvalidEmbed = errors.New("invalid include")
+	// ErrFieldCollision indicates that an included field has the same name as another field of the struct
+	ErrFieldCollision = errors.New("field collision")
+)
+
+// ExpandEmbeds returns a copy of the schema where the includes of every struct (include Base) are replaced by the
+// fields of the included struct, includes within included structs are expanded as well. Includes naming anything
+// but a struct and included fields taking the name of another field are reported, returns all the errors joined.
+// Unresolved names are left to Resolve and cycles to ValidateValueCycles. The schema itself is left untouched.
+func ExpandEmbeds(schema *parser.Schema) (*parser.Schema, error) {
+	expanded := parser.CloneSchema(schema)
+	e := &embedExpander{
+		declared:  make(map[string]bool),
+		structs:   make(map[string]*parser.StructDef),
+		blocks:    make(map[string]parser.Block),
+		expanding: make(map[string]bool),
+	}
+	for _, decl := range schema.Decls {
+		typeDecl, ok := unwrapDecl(decl).(*parser.TypeDecl)
+		if !ok {
+			continue
+		}
+
+		e.declared[parser.LookupName(typeDecl.Name)] = true
+		if def, ok := typeDecl.Type.(*parser.StructDef); ok {
+			e.structs[parser.LookupName(typeDecl.Name)] = def
+		}
+	}
+
+	// walkDecl visits the expanded fields next, the includes left behind are those outside of structs
+	walkDecls(expanded.Decls, func(decl parser.Decl) {
+		switch d := decl.(type) {
+		case *parser.TypeDecl:
+			if def, ok := d.Type.(*parser.StructDef); ok {
+				def.Block = e.expandNamed(parser.LookupName(d.Name))
+			}
+		case *parser.Field:
+			if def, ok := d.Type.(*parser.StructDef); ok {
+				def.Block = e.expandBlock(parser.LookupName(d.Name), def.Block)
+			}
+		case *parser.EmbedDecl:
+			e.errs = append(e.errs, errorf(parser.ExprLoc(d.Type), ErrInvalidEmbed, "`%s` is not included in a struct",
+				parser.LookupName(d.Type)))
+		}
+	})
+
+	return expanded, errors.Join(e.errs...)
+}
+
+type embedExpander struct {
+	declared map[string]bool
+	structs  map[string]*parser.StructDef
+	// blocks caches the expanded struct blocks, so the errors of a struct are reported once however many times it
+	// is included
+	blocks    map[string]parser.Block
+	expanding map[string]bool
+	errs      []error
+}
+
+// embeddedField is a field of an expanded block, origin is the struct it was included from (empty when declared)
+type embeddedField struct {
+	decl    parser.Decl
+	origin  string
+	include *parser.EmbedDecl
+}
+
+// expandNamed returns a copy of the expanded block of a struct of the schema
+func (e *embedExpander) expandNamed(name string) parser.Block {
+	block, ok := e.blocks[name]
+	if !ok {
+		e.expanding[name] = true
+		block = e.expandBlock(name, parser.CloneExpr(e.structs[name]).(*parser.StructDef).Block)
+		e.expanding[name] = false
+		e.blocks[name] = block
+	}
+
+	return parser.CloneExpr(&parser.StructDef{Block: block}).(*parser.StructDef).Block
+}
+
+func (e *embedExpander) expandBlock(owner string, block parser.Block) parser.Block {
+	fields := make([]embeddedField, 0, len(block.Decls))
+	for _, decl := range block.Decls {
+		include, ok := decl.(*parser.EmbedDecl)
+		if !ok {
+			fields = append(fields, embeddedField{decl: decl})
+			continue
+		}
+
+		name := parser.LookupName(include.Type)
+		_, isStruct := e.structs[name]
+		if !isStruct && e.declared[name] {
+			e.errs = append(e.errs, errorf(parser.ExprLoc(include.Type), ErrInvalidEmbed, "`%s` is not a struct", name))
+		}
+
+		// a struct including itself is a value cycle, reported by ValidateValueCycles
+		if !isStruct || e.expanding[name] {
+			continue
+		}
+
+		for _, included := range e.expandNamed(name).Decls {
+			fields = append(fields, embeddedField{decl: included, origin: name, include: include})
+		}
+	}
+
+	decls := make([]parser.Decl, 0, len(fields))
+	seen := make(map[string]embeddedField)
+	for _, field := range fields {
+		decls = append(decls, field.decl)
+		plain, ok := unwrapDecl(field.decl).(*parser.Field)
+		if !ok {
+			continue
+		}
+
+		name := parser.LookupName(plain.Name)
+		first, found := seen[name]
+		if !found {
+			seen[name] = field
+			continue
+		}
+
+		// duplicates among declared fields are not about includes
+		switch {
+		case first.origin == "" && field.origin == "":
+		case first.origin == "":
+			e.errs = append(e.errs, errorf(parser.ExprLoc(field.include.Type), ErrFieldCollision,
+				"`%s` included from `%s` is already a field of `%s`", name, field.origin, owner))
+		case field.origin == "":
+			e.errs = append(e.errs, errorf(parser.ExprLoc(first.include.Type), ErrFieldCollision,
+				"`%s` included from `%s` is also a field of `%s`", name, first.origin, owner))
+		default:
+			e.errs = append(e.errs, errorf(parser.ExprLoc(field.include.Type), ErrFieldCollision,
+				"`%s` is included from both `%s` and `%s` in `%s`", name, first.origin, field.origin, owner))
+		}
+	}
+
+	return parser.Block{Decls: decls}
+}
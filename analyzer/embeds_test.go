@@ -0,0 +1,89 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"github.com/cedmundo/SimpleSchema/analyzer"
+	"github.com/cedmundo/SimpleSchema/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandEmbeds(t *testing.T) {
+	cases := []struct {
+		name           string
+		input          string
+		expectedFields []string
+		expectedErr    error
+	}{
+		{
+			name:           "include",
+			input:          "type base struct {\nid : u64\n}\ntype s struct {\ninclude base\nname : string\n}\n",
+			expectedFields: []string{"id", "name"},
+		},
+		{
+			name:           "nested includes",
+			input:          "type a struct {\nx : int\n}\ntype b struct {\ninclude a\ny : int\n}\ntype s struct {\nz : int\ninclude b\n}\n",
+			expectedFields: []string{"z", "x", "y"},
+		},
+		{
+			name:        "collision with a field",
+			input:       "type base struct {\nid : u64\n}\ntype s struct {\ninclude base\nid : u32\n}\n",
+			expectedErr: analyzer.ErrFieldCollision,
+		},
+		{
+			name:        "collision between includes",
+			input:       "type a struct {\nid : u64\n}\ntype b struct {\nid : u64\n}\ntype s struct {\ninclude a\ninclude b\n}\n",
+			expectedErr: analyzer.ErrFieldCollision,
+		},
+		{
+			name:        "include of an enum",
+			input:       "type color enum {\nRED\n}\ntype s struct {\ninclude color\n}\n",
+			expectedErr: analyzer.ErrInvalidEmbed,
+		},
+		{
+			name:        "include in a union",
+			input:       "type base struct {\nid : u64\n}\ntype u union {\ninclude base\n}\n",
+			expectedErr: analyzer.ErrInvalidEmbed,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := parser.NewFromString(tt.name, tt.input).Parse()
+			require.NoError(t, err)
+
+			expanded, actualErr := analyzer.ExpandEmbeds(schema)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+			def := expanded.Decls[len(expanded.Decls)-1].(*parser.TypeDecl).Type.(*parser.StructDef)
+			actualFields := make([]string, 0, len(def.Block.Decls))
+			for _, decl := range def.Block.Decls {
+				actualFields = append(actualFields, parser.LookupName(decl.(*parser.Field).Name))
+			}
+			require.Equal(t, tt.expectedFields, actualFields)
+
+			// the original schema keeps its includes
+			original := schema.Decls[len(schema.Decls)-1].(*parser.TypeDecl).Type.(*parser.StructDef)
+			require.Condition(t, func() bool {
+				for _, decl := range original.Block.Decls {
+					if _, ok := decl.(*parser.EmbedDecl); ok {
+						return true
+					}
+				}
+				return false
+			})
+		})
+	}
+}
+
+func TestExpandEmbeds_CycleIsLeftToValueCycles(t *testing.T) {
+	schema, err := parser.NewFromString("cycle", "type a struct {\ninclude b\n}\ntype b struct {\ninclude a\n}\n").Parse()
+	require.NoError(t, err)
+
+	_, err = analyzer.ExpandEmbeds(schema)
+	require.NoError(t, err)
+	require.ErrorIs(t, analyzer.ValidateValueCycles(schema), analyzer.ErrValueCycle)
+}
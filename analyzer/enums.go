@@ -0,0 +1,168 @@
+package analyzer
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/cedmundo/SimpleSchema/lexer"
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+var (
+	// ErrDuplicateEnumValue indicates that two members of the same enum have the same value
+	ErrDuplicateEnumValue = errors.New("duplicate enum value")
+
+	// ErrMixedEnumValues indicates that an enum has both string and integer members
+	ErrMixedEnumValues = errors.New("mixed enum values")
+)
+
+// EnumValue is the value of an enum member, members without an explicit value follow the previous one (the first
+// one is 0). Known is false when the value is not an integer literal nor follows one (A = B, A = 1 + 2), further
+// analysis is left to the backends.
+type EnumValue struct {
+	Member *parser.Field
+	Value  int64
+	Known  bool
+}
+
+// EnumValues computes the value of each member of the enum in declaration order, integer literals of any base
+// are decoded (0x10, 0b101, 017) and negative ones too
+func EnumValues(def *parser.EnumDef) []EnumValue {
+	values := make([]EnumValue, 0, len(def.Block.Decls))
+	next, known := int64(0), true
+	for _, decl := range def.Block.Decls {
+		member, ok := unwrapDecl(decl).(*parser.Field)
+		if !ok {
+			continue
+		}
+
+		if member.Value != nil {
+			value, err := intValue(member.Value)
+			next, known = value, err == nil
+		}
+
+		values = append(values, EnumValue{Member: member, Value: next, Known: known})
+		next += 1
+	}
+
+	return values
+}
+
+// IsStringEnum reports whether the members of the enum map to strings (RED = "red"), a single string member makes
+// it one, see ValidateEnums
+func IsStringEnum(def *parser.EnumDef) bool {
+	for _, decl := range def.Block.Decls {
+		member, ok := unwrapDecl(decl).(*parser.Field)
+		if ok && isStringLiteral(member.Value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isStringLiteral(expr parser.Expr) bool {
+	literal, ok := expr.(*parser.Literal)
+	return ok && literal.Token.Tag == lexer.TokenTagString
+}
+
+// ValidateEnums checks that the known values of every enum are unique and that string enums have a string value
+// on every member, members without one would be integers. Returns all the errors joined.
+func ValidateEnums(schema *parser.Schema) error {
+	errs := make([]error, 0)
+	walkDecls(schema.Decls, func(decl parser.Decl) {
+		var typ parser.Expr
+		switch d := decl.(type) {
+		case *parser.TypeDecl:
+			typ = d.Type
+		case *parser.Field:
+			typ = d.Type
+		}
+
+		def, ok := typ.(*parser.EnumDef)
+		if !ok {
+			return
+		}
+
+		if IsStringEnum(def) {
+			errs = append(errs, validateStringEnum(def)...)
+			return
+		}
+
+		seen := make(map[int64]string)
+		for _, value := range EnumValues(def) {
+			if !value.Known {
+				continue
+			}
+
+			name := parser.LookupName(value.Member.Name)
+			if previous, ok := seen[value.Value]; ok {
+				errs = append(errs, errorf(parser.ExprLoc(value.Member.Name), ErrDuplicateEnumValue,
+					"`%s` has the same value as `%s` (%d)", name, previous, value.Value))
+				continue
+			}
+
+			seen[value.Value] = name
+		}
+	})
+
+	return errors.Join(errs...)
+}
+
+func validateStringEnum(def *parser.EnumDef) []error {
+	errs := make([]error, 0)
+	seen := make(map[string]string)
+	for _, decl := range def.Block.Decls {
+		member, ok := unwrapDecl(decl).(*parser.Field)
+		if !ok {
+			continue
+		}
+
+		name := parser.LookupName(member.Name)
+		if !isStringLiteral(member.Value) {
+			errs = append(errs, errorf(parser.ExprLoc(member.Name), ErrMixedEnumValues,
+				"`%s` needs a string value, the other members of the enum are strings", name))
+			continue
+		}
+
+		value := member.Value.(*parser.Literal).Token.Value
+		if previous, ok := seen[value]; ok {
+			errs = append(errs, errorf(parser.ExprLoc(member.Name), ErrDuplicateEnumValue,
+				"`%s` has the same value as `%s` (%q)", name, previous, value))
+			continue
+		}
+
+		seen[value] = name
+	}
+
+	return errs
+}
+
+// intValue decodes an integer literal, optionally negated, the lexer strips the base prefix of the literals
+func intValue(expr parser.Expr) (int64, error) {
+	if unary, ok := expr.(*parser.UnaryOp); ok && unary.Operator.Value == "-" {
+		value, err := intValue(unary.Operand)
+		return -value, err
+	}
+
+	literal, ok := expr.(*parser.Literal)
+	if !ok {
+		return 0, strconv.ErrSyntax
+	}
+
+	base := 0
+	switch literal.Token.Tag {
+	case lexer.TokenTagDecInt:
+		base = 10
+	case lexer.TokenTagHexInt:
+		base = 16
+	case lexer.TokenTagBinInt:
+		base = 2
+	case lexer.TokenTagOctInt:
+		base = 8
+	default:
+		return 0, strconv.ErrSyntax
+	}
+
+	return strconv.ParseInt(literal.Token.Value, base, 64)
+}
@@ -0,0 +1,127 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"github.com/cedmundo/SimpleSchema/analyzer"
+	"github.com/cedmundo/SimpleSchema/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnumValues(t *testing.T) {
+	cases := []struct {
+		name           string
+		input          string
+		expectedValues []int64 // only the known ones
+		expectedKnown  []bool
+	}{
+		{
+			name:           "implicit values",
+			input:          "type e enum {\nA\nB\nC\n}\n",
+			expectedValues: []int64{0, 1, 2},
+			expectedKnown:  []bool{true, true, true},
+		},
+		{
+			name:           "flags in every base",
+			input:          "type e enum {\nA = 0x01\nB = 0b10\nC = 04\nD = 8\nE\n}\n",
+			expectedValues: []int64{1, 2, 4, 8, 9},
+			expectedKnown:  []bool{true, true, true, true, true},
+		},
+		{
+			name:           "negative values",
+			input:          "type e enum {\nA = -1\nB\n}\n",
+			expectedValues: []int64{-1, 0},
+			expectedKnown:  []bool{true, true},
+		},
+		{
+			name:           "expressions are unknown",
+			input:          "type e enum {\nA = 1 + 4\nB\nC = 0xFF\n}\n",
+			expectedValues: []int64{255},
+			expectedKnown:  []bool{false, false, true},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := parser.NewFromString(tt.name, tt.input).Parse()
+			require.NoError(t, err)
+
+			def := schema.Decls[0].(*parser.TypeDecl).Type.(*parser.EnumDef)
+			actualValues := make([]int64, 0)
+			actualKnown := make([]bool, 0)
+			for _, value := range analyzer.EnumValues(def) {
+				actualKnown = append(actualKnown, value.Known)
+				if value.Known {
+					actualValues = append(actualValues, value.Value)
+				}
+			}
+
+			require.Equal(t, tt.expectedKnown, actualKnown)
+			require.Equal(t, tt.expectedValues, actualValues)
+		})
+	}
+}
+
+func TestValidateEnums(t *testing.T) {
+	cases := []struct {
+		name        string
+		input       string
+		expectedErr error
+	}{
+		{
+			name:  "distinct flags",
+			input: "type flags enum {\nFLAG_A = 0x01\nFLAG_B = 0x02\nFLAG_C = 0x04\n}\n",
+		},
+		{
+			name:        "duplicate hex flag",
+			input:       "type flags enum {\nFLAG_A = 0x01\nFLAG_B = 0x02\nFLAG_C = 0x02\n}\n",
+			expectedErr: analyzer.ErrDuplicateEnumValue,
+		},
+		{
+			name:        "same value in different bases",
+			input:       "type flags enum {\nFLAG_A = 0x04\nFLAG_B = 0b100\n}\n",
+			expectedErr: analyzer.ErrDuplicateEnumValue,
+		},
+		{
+			name:        "implicit value colliding with an explicit one",
+			input:       "type e enum {\nA = 0x01\nB = 0\nC\n}\n",
+			expectedErr: analyzer.ErrDuplicateEnumValue,
+		},
+		{
+			name:  "string enum",
+			input: "type color enum {\nRED = \"red\"\nGREEN = \"green\"\n}\n",
+		},
+		{
+			name:        "string and integer members",
+			input:       "type color enum {\nRED = \"red\"\nGREEN = 1\n}\n",
+			expectedErr: analyzer.ErrMixedEnumValues,
+		},
+		{
+			name:        "string enum member without value",
+			input:       "type color enum {\nRED\nGREEN = \"green\"\n}\n",
+			expectedErr: analyzer.ErrMixedEnumValues,
+		},
+		{
+			name:        "duplicate string value",
+			input:       "type color enum {\nRED = \"red\"\nCRIMSON = \"red\"\n}\n",
+			expectedErr: analyzer.ErrDuplicateEnumValue,
+		},
+		{
+			name:  "unknown values are not compared",
+			input: "type e enum {\nA = 1 + 0\nB = 1 + 0\n}\n",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := parser.NewFromString(tt.name, tt.input).Parse()
+			require.NoError(t, err)
+
+			actualErr := analyzer.ValidateEnums(schema)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package analyzer
+
+import (
+	"errors"
+
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+// ErrInvalidFlag indicates that a [[ flags ]] declaration is not an enum or one of its members is not a distinct
+// power of two
+var ErrInvalidFlag = errors.New("invalid flag")
+
+// ValidateFlags checks that every enum annotated with [[ flags ]] has members whose values are distinct positive
+// powers of two, so each member is a single bit of the mask. Returns all the errors joined.
+func ValidateFlags(schema *parser.Schema) error {
+	errs := make([]error, 0)
+	walkDecls(schema.Decls, func(decl parser.Decl) {
+		annotated, ok := decl.(*parser.AnnotatedDecl)
+		if !ok {
+			return
+		}
+
+		flags, ok := annotated.Find("flags")
+		if !ok {
+			return
+		}
+
+		var def *parser.EnumDef
+		if typeDecl, ok := annotated.Decl.(*parser.TypeDecl); ok {
+			def, _ = typeDecl.Type.(*parser.EnumDef)
+		}
+
+		if def == nil {
+			errs = append(errs, errorf(parser.ExprLoc(flags.Name), ErrInvalidFlag, "only enums can be flags"))
+			return
+		}
+
+		seen := make(map[int64]string)
+		for _, value := range EnumValues(def) {
+			name := parser.LookupName(value.Member.Name)
+			loc := parser.ExprLoc(value.Member.Name)
+			if !value.Known {
+				errs = append(errs, errorf(loc, ErrInvalidFlag, "`%s` must be an integer", name))
+				continue
+			}
+
+			if value.Value <= 0 || value.Value&(value.Value-1) != 0 {
+				errs = append(errs, errorf(loc, ErrInvalidFlag, "`%s` (%d) is not a power of two", name, value.Value))
+				continue
+			}
+
+			if previous, ok := seen[value.Value]; ok {
+				errs = append(errs, errorf(loc, ErrInvalidFlag, "`%s` has the same bit as `%s`", name, previous))
+				continue
+			}
+
+			seen[value.Value] = name
+		}
+	})
+
+	return errors.Join(errs...)
+}
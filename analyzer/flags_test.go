@@ -0,0 +1,65 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"github.com/cedmundo/SimpleSchema/analyzer"
+	"github.com/cedmundo/SimpleSchema/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateFlags(t *testing.T) {
+	cases := []struct {
+		name        string
+		input       string
+		expectedErr error
+	}{
+		{
+			name:  "powers of two",
+			input: "[[ flags ]]\ntype perms enum {\nREAD = 1\nWRITE = 0x2\nEXEC = 0b100\n}\n",
+		},
+		{
+			name:  "plain enum is not checked",
+			input: "type perms enum {\nREAD\nWRITE\nEXEC\n}\n",
+		},
+		{
+			name:        "implicit values",
+			input:       "[[ flags ]]\ntype perms enum {\nREAD = 1\nWRITE\nEXEC\n}\n",
+			expectedErr: analyzer.ErrInvalidFlag,
+		},
+		{
+			name:        "zero value",
+			input:       "[[ flags ]]\ntype perms enum {\nNONE = 0\nREAD = 1\n}\n",
+			expectedErr: analyzer.ErrInvalidFlag,
+		},
+		{
+			name:        "repeated bit",
+			input:       "[[ flags ]]\ntype perms enum {\nREAD = 1\nWRITE = 1\n}\n",
+			expectedErr: analyzer.ErrInvalidFlag,
+		},
+		{
+			name:        "unknown value",
+			input:       "[[ flags ]]\ntype perms enum {\nREAD = 1\nWRITE = READ\n}\n",
+			expectedErr: analyzer.ErrInvalidFlag,
+		},
+		{
+			name:        "flags on a struct",
+			input:       "[[ flags ]]\ntype s struct {\na : int\n}\n",
+			expectedErr: analyzer.ErrInvalidFlag,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := parser.NewFromString(tt.name, tt.input).Parse()
+			require.NoError(t, err)
+
+			actualErr := analyzer.ValidateFlags(schema)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package analyzer
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/cedmundo/SimpleSchema/lexer"
+)
+
+// FormatErrorsCompact writes an error per line in the file:row:col: message format (see lexer.Location.String) so
+// CI systems can annotate the sources, errors without a location are written without prefix. Joined errors (like
+// the ones of Check) are split in their parts, except for a located error joined with its sentinels (like the
+// lexer ones) which takes a single line.
+func FormatErrorsCompact(errs []error) string {
+	out := &strings.Builder{}
+	for _, err := range errs {
+		for _, line := range compactLines(err) {
+			out.WriteString(line)
+			out.WriteRune('\n')
+		}
+	}
+
+	return out.String()
+}
+
+func compactLines(err error) []string {
+	if err == nil {
+		return nil
+	}
+
+	if parts, ok := joinedParts(err); ok {
+		if located, ok := soleLocated(parts); ok {
+			return compactLines(located)
+		}
+
+		lines := make([]string, 0, len(parts))
+		for _, part := range parts {
+			lines = append(lines, compactLines(part)...)
+		}
+
+		return lines
+	}
+
+	message := strings.Join(strings.Fields(err.Error()), " ")
+	loc, ok := errorLocation(err)
+	if !ok {
+		return []string{message}
+	}
+
+	message = strings.Replace(message, loc.String()+": ", "", 1)
+	return []string{loc.String() + ": " + message}
+}
+
+// errorLocation returns the location of an error, either the one of an analyzer Error or the one of a parser or
+// lexer error (see lexer.Error)
+func errorLocation(err error) (lexer.Location, bool) {
+	var diagnostic *Error
+	if errors.As(err, &diagnostic) {
+		return diagnostic.Loc, true
+	}
+
+	var located *lexer.Error
+	if errors.As(err, &located) {
+		return located.Loc, true
+	}
+
+	return lexer.Location{}, false
+}
+
+// joinedParts returns the errors joined by errors.Join, wrapping several errors with fmt.Errorf is a single error
+func joinedParts(err error) ([]error, bool) {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return nil, false
+	}
+
+	parts := joined.Unwrap()
+	messages := make([]string, 0, len(parts))
+	for _, part := range parts {
+		messages = append(messages, part.Error())
+	}
+
+	return parts, strings.Join(messages, "\n") == err.Error()
+}
+
+// soleLocated returns the only located error of a join whose other parts are plain sentinels, which describe it
+func soleLocated(parts []error) (error, bool) {
+	var located error
+	for _, part := range parts {
+		if _, ok := errorLocation(part); ok {
+			if located != nil {
+				return nil, false
+			}
+
+			located = part
+			continue
+		}
+
+		if _, joined := part.(interface{ Unwrap() []error }); joined || errors.Unwrap(part) != nil {
+			return nil, false
+		}
+	}
+
+	return located, located != nil
+}
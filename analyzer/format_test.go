@@ -0,0 +1,73 @@
+package analyzer_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/cedmundo/SimpleSchema/analyzer"
+	"github.com/cedmundo/SimpleSchema/lexer"
+	"github.com/cedmundo/SimpleSchema/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func parseErr(t *testing.T, input string) error {
+	t.Helper()
+	_, err := parser.NewFromString("a.ss", input).Parse()
+	require.Error(t, err)
+	return err
+}
+
+func TestFormatErrorsCompact(t *testing.T) {
+	cases := []struct {
+		name           string
+		errs           []error
+		expectedString string
+	}{
+		{
+			name:           "no errors",
+			expectedString: "",
+		},
+		{
+			name:           "unlocated error",
+			errs:           []error{errors.New("cannot open schema")},
+			expectedString: "cannot open schema\n",
+		},
+		{
+			name:           "parser error",
+			errs:           []error{parseErr(t, "options { version = 1; }\noptions { version = 2; }\n")},
+			expectedString: "a.ss:1:25: duplicate options\n",
+		},
+		{
+			name: "location wrapped by context",
+			errs: []error{fmt.Errorf("%w: %w", parser.ErrMalformedRange,
+				&lexer.Error{Loc: lexer.Location{File: "a.ss", Row: 1, Col: 3}, Msg: "low end"})},
+			expectedString: "a.ss:1:3: malformed range: low end\n",
+		},
+		{
+			name:           "lexer error joined with its sentinels",
+			errs:           []error{parseErr(t, "type a $\n")},
+			expectedString: "a.ss:0:7: invalid character: '$'\n",
+		},
+		{
+			name:           "location written in an unlocated message",
+			errs:           []error{errors.New("a.ss:1:3: low end")},
+			expectedString: "a.ss:1:3: low end\n",
+		},
+		{
+			name: "mix of located and unlocated errors",
+			errs: []error{
+				errors.New("cannot open schema"),
+				analyzer.Check("type a b\ntype a int\n", "b.ss"),
+			},
+			expectedString: "cannot open schema\n" +
+				"b.ss:1:14: duplicate symbol: `a`\n" +
+				"b.ss:0:7: unresolved symbol: `b`\n",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expectedString, analyzer.FormatErrorsCompact(tt.errs))
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package analyzer
+
+import (
+	"errors"
+	"maps"
+
+	"github.com/cedmundo/SimpleSchema/keywords"
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+var (
+	// ErrDuplicateSymbol indicates that a name is declared more than once in the same scope
+	ErrDuplicateSymbol = errors.New("duplicate symbol")
+
+	// ErrUnresolvedSymbol indicates that a type name is neither declared in the schema nor a builtin
+	ErrUnresolvedSymbol = errors.New("unresolved symbol")
+)
+
+// SymbolTable maps names to their declarations, nested scopes (like generic parameters) fall back to their parent
+type SymbolTable struct {
+	parent *SymbolTable
+	names  []string
+	decls  map[string]parser.Decl
+}
+
+// NewSymbolTable returns an empty top level scope
+func NewSymbolTable() *SymbolTable {
+	return &SymbolTable{decls: make(map[string]parser.Decl)}
+}
+
+// Nested returns an empty scope whose lookups fall back to the receiver
+func (st *SymbolTable) Nested() *SymbolTable {
+	nested := NewSymbolTable()
+	nested.parent = st
+	return nested
+}
+
+// Declare adds a name to the scope, shadowing the parent scopes is allowed but redeclaring a name is not
+func (st *SymbolTable) Declare(name string, decl parser.Decl) error {
+	if _, ok := st.decls[name]; ok {
+		return ErrDuplicateSymbol
+	}
+
+	st.names = append(st.names, name)
+	st.decls[name] = decl
+	return nil
+}
+
+// Lookup returns the declaration of a name as it appears in the schema (it may be annotated), searching from the
+// innermost scope outwards. Builtins have no declaration, use IsBuiltin for them.
+func (st *SymbolTable) Lookup(name string) (parser.Decl, bool) {
+	for scope := st; scope != nil; scope = scope.parent {
+		decl, ok := scope.decls[name]
+		if ok {
+			return decl, true
+		}
+	}
+
+	return nil, false
+}
+
+// IsBuiltin reports whether the name refers to a builtin type, a declaration with the same name shadows it
+func (st *SymbolTable) IsBuiltin(name string) bool {
+	if _, ok := st.Lookup(name); ok {
+		return false
+	}
+
+	return keywords.IsBuiltin(name)
+}
+
+// All returns the declarations visible from the scope, outer scopes first and each one in declaration order. The
+// declarations shadowed by an inner scope are left out, so every name appears once.
+func (st *SymbolTable) All() []parser.Decl {
+	return st.visible(make(map[string]bool))
+}
+
+// visible returns the declarations of the scope and its parents whose names are not declared by an inner scope
+func (st *SymbolTable) visible(shadowed map[string]bool) []parser.Decl {
+	decls := make([]parser.Decl, 0)
+	if st.parent != nil {
+		inner := maps.Clone(shadowed)
+		for _, name := range st.names {
+			inner[name] = true
+		}
+		decls = append(decls, st.parent.visible(inner)...)
+	}
+
+	for _, name := range st.names {
+		if !shadowed[name] {
+			decls = append(decls, st.decls[name])
+		}
+	}
+
+	return decls
+}
+
+// Resolve builds the symbol table of the top level types, procedures and constants of the schema and validates the
+// type names they reference, returns all the errors joined along with the table of the valid declarations.
+//
+// All the names are declared before any reference is validated, so a type may reference another one declared
+// later in the schema. Qualified names (mod.T) belong to imported modules and are not validated.
+func Resolve(schema *parser.Schema) (*SymbolTable, error) {
+	table := NewSymbolTable()
+	errs := make([]error, 0)
+	for _, decl := range schema.Decls {
+		var name parser.Expr
+		switch d := unwrapDecl(decl).(type) {
+		case *parser.TypeDecl:
+			name = d.Name
+		case *parser.ProcDecl:
+			name = d.Name
+		case *parser.ConstDecl:
+			name = d.Name
+		default:
+			continue
+		}
+
+		err := table.Declare(parser.LookupName(name), decl)
+		if err != nil {
+			errs = append(errs, errorf(parser.ExprLoc(name), err, "`%s`", parser.LookupName(name)))
+		}
+	}
+
+	for _, decl := range schema.Decls {
+		scope, paramErrs := genericScope(table, decl)
+		errs = append(errs, paramErrs...)
+		declTypeRefs(decl, func(name string, ref parser.Expr) {
+			if _, ok := ref.(*parser.Ident); !ok {
+				return
+			}
+
+			if _, ok := scope.Lookup(name); ok || scope.IsBuiltin(name) {
+				return
+			}
+
+			errs = append(errs, errorf(parser.ExprLoc(ref), ErrUnresolvedSymbol, "`%s`", name))
+		})
+	}
+
+	return table, errors.Join(errs...)
+}
+
+// genericScope returns the scope resolving the references of a declaration, its generic parameters (type List<T>)
+// are declared in a scope nested in the table. The bounds of their constraints are left to be validated later.
+func genericScope(table *SymbolTable, decl parser.Decl) (*SymbolTable, []error) {
+	var params []*parser.Ident
+	switch d := unwrapDecl(decl).(type) {
+	case *parser.TypeDecl:
+		params = d.Params
+	case *parser.ProcDecl:
+		params = d.Params
+	}
+	if len(params) == 0 {
+		return table, nil
+	}
+
+	scope := table.Nested()
+	errs := make([]error, 0)
+	for _, param := range params {
+		err := scope.Declare(param.Token.Value, decl)
+		if err != nil {
+			errs = append(errs, errorf(param.Token.Loc, err, "`%s`", param.Token.Value))
+		}
+	}
+
+	return scope, errs
+}
@@ -0,0 +1,253 @@
+package analyzer_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/cedmundo/SimpleSchema/analyzer"
+	"github.com/cedmundo/SimpleSchema/lexer"
+	"github.com/cedmundo/SimpleSchema/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func resolveString(t *testing.T, name, input string) (*parser.Schema, *analyzer.SymbolTable, error) {
+	t.Helper()
+	schema, err := parser.NewFromString(name, input).Parse()
+	require.NoError(t, err)
+
+	table, err := analyzer.Resolve(schema)
+	return schema, table, err
+}
+
+func TestResolve(t *testing.T) {
+	cases := []struct {
+		name        string
+		input       string
+		expectedErr error
+	}{
+		{
+			name:  "types and procs",
+			input: "type vec2 float[2];\n[[ size = 8 ]]\ntype s struct {};\nproc add(a : vec2) -> void;",
+		},
+		{
+			name:        "duplicate type",
+			input:       "type s struct {};\ntype s union {};",
+			expectedErr: analyzer.ErrDuplicateSymbol,
+		},
+		{
+			name:  "forward reference",
+			input: "type a struct { next : *b; };\ntype b struct { value : a[2]; };",
+		},
+		{
+			name:  "proc using a later type",
+			input: "proc make() -> s;\ntype s struct { name : string; tags : set[u8]; };",
+		},
+		{
+			name:  "enum members are not type references",
+			input: "type color enum {\nBLACK\nWHITE = 0xFFFFFF\n}\ntype pixel struct { c : color; };",
+		},
+		{
+			name:  "qualified names are left to imports",
+			input: "type s struct { v : math.vec2; };",
+		},
+		{
+			name:        "unresolved field type",
+			input:       "type s struct { v : vec3; };",
+			expectedErr: analyzer.ErrUnresolvedSymbol,
+		},
+		{
+			name:        "unresolved proc param",
+			input:       "proc f(a : missing) -> void;",
+			expectedErr: analyzer.ErrUnresolvedSymbol,
+		},
+		{
+			name:  "generic parameters",
+			input: "type list<T> where T : comparable struct { items : T[]; };\nproc first<T>(l : list) -> T;",
+		},
+		{
+			name:        "generic parameter out of its declaration",
+			input:       "type list<T> struct {};\ntype s struct { v : T; };",
+			expectedErr: analyzer.ErrUnresolvedSymbol,
+		},
+		{
+			name:        "duplicate generic parameter",
+			input:       "type pair<T, T> struct {};",
+			expectedErr: analyzer.ErrDuplicateSymbol,
+		},
+		{
+			name:        "unresolved map value",
+			input:       "type s struct { m : map[u32]missing; };",
+			expectedErr: analyzer.ErrUnresolvedSymbol,
+		},
+		{
+			name:        "proc named after a type",
+			input:       "type s struct {};\nproc s() -> void;",
+			expectedErr: analyzer.ErrDuplicateSymbol,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, actualErr := resolveString(t, tt.name, tt.input)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+		})
+	}
+}
+
+func TestSymbolTable_Lookup(t *testing.T) {
+	schema, table, err := resolveString(t, "lookup", "type vec2 float[2];\n[[ size = 8 ]]\ntype s struct {};")
+	require.NoError(t, err)
+
+	decl, ok := table.Lookup("vec2")
+	require.True(t, ok)
+	require.Same(t, schema.Decls[0], decl)
+
+	decl, ok = table.Lookup("s")
+	require.True(t, ok)
+	require.Same(t, schema.Decls[1], decl)
+
+	_, ok = table.Lookup("float")
+	require.False(t, ok)
+	require.True(t, table.IsBuiltin("float"))
+
+	_, ok = table.Lookup("missing")
+	require.False(t, ok)
+	require.False(t, table.IsBuiltin("missing"))
+	require.False(t, table.IsBuiltin("vec2"))
+}
+
+func TestSymbolTable_Nested(t *testing.T) {
+	_, table, err := resolveString(t, "nested", "type s struct {};")
+	require.NoError(t, err)
+
+	param := &parser.Field{}
+	scope := table.Nested()
+	require.NoError(t, scope.Declare("T", param))
+	require.ErrorIs(t, scope.Declare("T", param), analyzer.ErrDuplicateSymbol)
+
+	decl, ok := scope.Lookup("T")
+	require.True(t, ok)
+	require.Same(t, param, decl)
+
+	_, ok = scope.Lookup("s")
+	require.True(t, ok)
+
+	_, ok = table.Lookup("T")
+	require.False(t, ok)
+
+	// shadowing a builtin hides it
+	require.NoError(t, scope.Declare("int", param))
+	require.False(t, scope.IsBuiltin("int"))
+	require.True(t, table.IsBuiltin("int"))
+}
+
+func TestSymbolTable_All(t *testing.T) {
+	schema, table, err := resolveString(t, "all", "type c struct {};\ntype a struct {};\nproc b() -> void;")
+	require.NoError(t, err)
+
+	param := &parser.Field{}
+	scope := table.Nested()
+	require.NoError(t, scope.Declare("T", param))
+
+	expected := append(slices.Clone(schema.Decls), param)
+	for range 10 {
+		require.Equal(t, schema.Decls, table.All())
+		require.Equal(t, expected, scope.All())
+	}
+}
+
+func TestSymbolTable_AllShadowed(t *testing.T) {
+	schema, table, err := resolveString(t, "shadowed", "type T struct {};\ntype a struct {};")
+	require.NoError(t, err)
+
+	param := &parser.Field{}
+	scope := table.Nested()
+	require.NoError(t, scope.Declare("T", param))
+
+	// the parameter T hides the type T
+	require.Equal(t, []parser.Decl{schema.Decls[1], param}, scope.All())
+	require.Equal(t, schema.Decls, table.All())
+}
+
+func TestResolve_ProcTypes(t *testing.T) {
+	cases := []struct {
+		name        string
+		input       string
+		expectedErr string
+	}{
+		{
+			name:  "returns a declared struct",
+			input: "type point struct { x : int; y : int; };\nproc origin() -> point;",
+		},
+		{
+			name:  "returns void",
+			input: "proc reset(p : *point) -> void;\ntype point struct {};",
+		},
+		{
+			name:  "returns a tuple",
+			input: "type point struct {};\nproc split(p : point) -> (int, point);",
+		},
+		{
+			name:  "callback field",
+			input: "type point struct {};\ntype s struct { cb : proc(point) -> bool; };",
+		},
+		{
+			name:        "returns an unknown type",
+			input:       "proc origin() -> point;",
+			expectedErr: "returns an unknown type:0:17: unresolved symbol: `point`",
+		},
+		{
+			name:        "unknown tuple element",
+			input:       "proc split() -> (int, point);",
+			expectedErr: "unknown tuple element:0:22: unresolved symbol: `point`",
+		},
+		{
+			name:        "unknown positional param",
+			input:       "proc f(vec3) -> void;",
+			expectedErr: "unknown positional param:0:7: unresolved symbol: `vec3`",
+		},
+		{
+			name:        "unknown callback return type",
+			input:       "type s struct { cb : proc(int) -> result; };",
+			expectedErr: "unknown callback return type:0:34: unresolved symbol: `result`",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, actualErr := resolveString(t, tt.name, tt.input)
+			if tt.expectedErr != "" {
+				require.ErrorIs(t, actualErr, analyzer.ErrUnresolvedSymbol)
+				require.EqualError(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+		})
+	}
+}
+
+func TestSymbolTable_BuiltinsAgreeWithLexer(t *testing.T) {
+	table := analyzer.NewSymbolTable()
+	cases := []struct {
+		input           string
+		expectedKeyword bool
+		expectedBuiltin bool
+	}{
+		{input: "int", expectedBuiltin: true},
+		{input: "void", expectedBuiltin: true},
+		{input: "struct", expectedKeyword: true},
+		{input: "proc", expectedKeyword: true},
+		{input: "`struct`"},
+		{input: "vec2"},
+	}
+	for _, tt := range cases {
+		token, err := lexer.NewFromString("agree", tt.input).Read()
+		require.NoError(t, err)
+		require.Equal(t, tt.expectedKeyword, token.IsKeyword(), tt.input)
+		require.Equal(t, tt.expectedBuiltin, table.IsBuiltin(token.Value), tt.input)
+	}
+}
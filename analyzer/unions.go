@@ -0,0 +1,75 @@
+package analyzer
+
+import (
+	"errors"
+
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+var (
+	// ErrMissingUnionTag indicates that an inline union annotated with [[ tagged ]] does not declare its tag field
+	ErrMissingUnionTag = errors.New("missing union tag")
+
+	// ErrDuplicateUnionTag indicates that an union declares more than one tag field
+	ErrDuplicateUnionTag = errors.New("duplicate union tag")
+)
+
+// ValidateUnionTags checks that tagged unions declare exactly one tag field (union { tag kind : Kind }). Declared
+// unions annotated with [[ tagged ]] may leave it out, the compiler synthesizes an X_tag enum named after them, but
+// inline ones (v : union { ... }) have no name to synthesize it from and must declare it. Returns all the errors
+// joined.
+func ValidateUnionTags(schema *parser.Schema) error {
+	// the annotations wrap the fields, so the tagged inline unions are collected before visiting them
+	inline := make(map[*parser.UnionDef]bool)
+	walkDecls(schema.Decls, func(decl parser.Decl) {
+		wrapper, ok := decl.(*parser.AnnotatedDecl)
+		if _, tagged := wrapper.Find("tagged"); !ok || !tagged {
+			return
+		}
+
+		if field, ok := wrapper.Decl.(*parser.Field); ok {
+			if def, ok := field.Type.(*parser.UnionDef); ok {
+				inline[def] = true
+			}
+		}
+	})
+
+	errs := make([]error, 0)
+	walkDecls(schema.Decls, func(decl parser.Decl) {
+		var name, typ parser.Expr
+		switch d := decl.(type) {
+		case *parser.TypeDecl:
+			name, typ = d.Name, d.Type
+		case *parser.Field:
+			name, typ = d.Name, d.Type
+		}
+
+		def, ok := typ.(*parser.UnionDef)
+		if !ok {
+			return
+		}
+
+		var first *parser.Field
+		for _, member := range def.Block.Decls {
+			field, ok := unwrapDecl(member).(*parser.Field)
+			if !ok || !field.Tag {
+				continue
+			}
+
+			if first != nil {
+				errs = append(errs, errorf(parser.ExprLoc(field.Name), ErrDuplicateUnionTag,
+					"`%s` and `%s` are both tags", parser.LookupName(first.Name), parser.LookupName(field.Name)))
+				continue
+			}
+
+			first = field
+		}
+
+		if first == nil && inline[def] {
+			errs = append(errs, errorf(parser.ExprLoc(name), ErrMissingUnionTag,
+				"`%s` is an inline tagged union", parser.LookupName(name)))
+		}
+	})
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,62 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"github.com/cedmundo/SimpleSchema/analyzer"
+	"github.com/cedmundo/SimpleSchema/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateUnionTags(t *testing.T) {
+	cases := []struct {
+		name        string
+		input       string
+		expectedErr error
+	}{
+		{
+			name:  "tagged union with a tag",
+			input: "[[ tagged ]]\ntype u union {\ntag kind : u8\na : int\n}\n",
+		},
+		{
+			name:  "tag without annotation",
+			input: "type u union {\ntag kind : u8\na : int\n}\n",
+		},
+		{
+			name:  "plain union",
+			input: "type u union {\na : int\nb : float\n}\n",
+		},
+		{
+			name:  "tagged union without a tag",
+			input: "[[ tagged ]]\ntype u union {\na : int\nb : float\n}\n",
+		},
+		{
+			name:        "inline tagged union without a tag",
+			input:       "type s struct {\n[[ tagged ]]\nv : union {\na : int\n}\n}\n",
+			expectedErr: analyzer.ErrMissingUnionTag,
+		},
+		{
+			name:  "inline tagged union with a tag",
+			input: "type s struct {\n[[ tagged ]]\nv : union {\ntag kind : u8\na : int\n}\n}\n",
+		},
+		{
+			name:        "two tags",
+			input:       "type u union {\ntag kind : u8\ntag other : u8\na : int\n}\n",
+			expectedErr: analyzer.ErrDuplicateUnionTag,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := parser.NewFromString(tt.name, tt.input).Parse()
+			require.NoError(t, err)
+
+			actualErr := analyzer.ValidateUnionTags(schema)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package analyzer
+
+import "github.com/cedmundo/SimpleSchema/parser"
+
+// UnusedTypes returns the top level type declarations (in source order) not referenced by any other declaration,
+// a type referencing itself does not count and types annotated with [[ export ]] are always considered used
+func UnusedTypes(schema *parser.Schema) []parser.Decl {
+	used := make(map[string]bool)
+	for _, decl := range schema.Decls {
+		owner := declName(decl)
+		declTypeRefs(decl, func(name string, _ parser.Expr) {
+			if name != owner {
+				used[name] = true
+			}
+		})
+	}
+
+	unused := make([]parser.Decl, 0)
+	for _, decl := range schema.Decls {
+		typeDecl, ok := unwrapDecl(decl).(*parser.TypeDecl)
+		if !ok || used[parser.LookupName(typeDecl.Name)] {
+			continue
+		}
+
+		annotated, _ := decl.(*parser.AnnotatedDecl)
+		if _, ok := annotated.Find("export"); ok {
+			continue
+		}
+
+		unused = append(unused, decl)
+	}
+
+	return unused
+}
+
+// declName returns the name of a top level type or proc, or an empty string for any other declaration
+func declName(decl parser.Decl) string {
+	switch d := unwrapDecl(decl).(type) {
+	case *parser.TypeDecl:
+		return parser.LookupName(d.Name)
+	case *parser.ProcDecl:
+		return parser.LookupName(d.Name)
+	}
+
+	return ""
+}
@@ -0,0 +1,64 @@
+package analyzer_test
+
+import (
+	"testing"
+
+	"github.com/cedmundo/SimpleSchema/analyzer"
+	"github.com/cedmundo/SimpleSchema/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnusedTypes(t *testing.T) {
+	cases := []struct {
+		name          string
+		input         string
+		expectedNames []string
+	}{
+		{
+			name:          "unused type",
+			input:         "module m;\ntype a struct {};\ntype b struct {\nx : int\n}\n",
+			expectedNames: []string{"a", "b"},
+		},
+		{
+			name:          "type kept alive by a field",
+			input:         "type a struct {};\ntype b struct {\nx : *a\n}\n",
+			expectedNames: []string{"b"},
+		},
+		{
+			name:          "type kept alive by an inline struct",
+			input:         "type a enum { X };\ntype b struct {\ninner : struct {\nx : a[4]\n}\n}\n",
+			expectedNames: []string{"b"},
+		},
+		{
+			name:          "type kept alive by a proc",
+			input:         "type a struct {};\ntype b struct {};\nproc f(x : const(a)) -> b;",
+			expectedNames: []string{},
+		},
+		{
+			name:          "self reference does not count",
+			input:         "type node struct {\nnext : *node\n}\n",
+			expectedNames: []string{"node"},
+		},
+		{
+			name:          "exported type",
+			input:         "[[ export ]]\ntype a struct {};\ntype b struct {};",
+			expectedNames: []string{"b"},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := parser.NewFromString(tt.name, tt.input).Parse()
+			require.NoError(t, err)
+
+			actualNames := make([]string, 0)
+			for _, decl := range analyzer.UnusedTypes(schema) {
+				if annotated, ok := decl.(*parser.AnnotatedDecl); ok {
+					decl = annotated.Decl
+				}
+
+				actualNames = append(actualNames, parser.LookupName(decl.(*parser.TypeDecl).Name))
+			}
+			require.Equal(t, tt.expectedNames, actualNames)
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package compiler
+
+import (
+	"github.com/cedmundo/SimpleSchema/generator"
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+// compileAccessors makes a getter and a setter (X_get_a, X_set_a) per field of a struct annotated with
+// [[ accessors ]], fields annotated with [[ readonly ]] only get a getter. Arrays (and fixed strings) cannot be
+// assigned in C so they only get a getter returning a pointer to their first element, multidimensional arrays are
+// skipped. The optional fields (named by optionals) get a presence check as well, see optionalAccessors. The fields
+// of packed structs may be misaligned, so their scalars are copied with memcpy instead (see packedGetter).
+func compileAccessors(name string, block parser.Block, fields []generator.Field, optionals map[string]bool, packed bool) []generator.Decl {
+	decls := make([]generator.Decl, 0, len(fields)*2)
+	for i, field := range fields {
+		// anonymous members (included fields) have no name to access them by
+		if field.Name == nil {
+			continue
+		}
+
+		annotated, _ := block.Decls[i].(*parser.AnnotatedDecl)
+		_, readonly := annotated.Find("readonly")
+		if helpers := optionalAccessors(name, field, readonly); optionals[fieldName(field)] && helpers != nil {
+			decls = append(decls, helpers...)
+			continue
+		}
+
+		subscript, isArray := field.Name.(*generator.Subscript)
+		if isArray {
+			if _, nested := subscript.Base.(*generator.Subscript); !nested {
+				decls = append(decls, accessorGetter(name, field, &generator.Pointer{Type: constType(field.Type)}))
+			}
+			continue
+		}
+
+		if packed {
+			decls = append(decls, packedGetter(name, field))
+			if !readonly {
+				decls = append(decls, packedSetter(name, field))
+			}
+			continue
+		}
+
+		decls = append(decls, accessorGetter(name, field, getterType(field.Type)))
+		if !readonly {
+			decls = append(decls, accessorSetter(name, field))
+		}
+	}
+
+	return decls
+}
+
+// compileAccessorMacros makes an accessor macro per field of a struct annotated with [[ accessors ]], it expands to
+// the member itself so it both reads and assigns it (X_a(x) = 1), readonly fields cannot be enforced by a macro
+func compileAccessorMacros(name string, fields []generator.Field) []generator.Decl {
+	decls := make([]generator.Decl, 0, len(fields))
+	for _, field := range fields {
+		if field.Name == nil {
+			continue
+		}
+
+		self := &generator.Paren{Expr: &generator.Ident{Name: "x"}}
+		decls = append(decls, &generator.Define{
+			Name:   name + "_" + fieldName(field),
+			Params: []string{"x"},
+			Value:  &generator.Paren{Expr: &generator.Member{Base: self, Name: fieldName(field), Arrow: true}},
+		})
+	}
+
+	return decls
+}
+
+// getterType returns scalars by value while pointers are returned as pointers to const, so the getter cannot be
+// used to mutate what the field points to. The generator qualifies from the left, so only the innermost pointee
+// can be made const (int** stays as it is).
+func getterType(typ generator.Expr) generator.Expr {
+	pointer, ok := typ.(*generator.Pointer)
+	if !ok {
+		return typ
+	}
+
+	if _, nested := pointer.Type.(*generator.Pointer); nested {
+		return typ
+	}
+
+	return &generator.Pointer{Type: constType(pointer.Type)}
+}
+
+func constType(typ generator.Expr) generator.Expr {
+	if _, ok := typ.(*generator.Const); ok {
+		return typ
+	}
+
+	return &generator.Const{Type: typ}
+}
+
+// accessorAttrs makes the accessors safe to define in a header included by several translation units
+func accessorAttrs() []generator.Attr {
+	return []generator.Attr{&generator.Specifier{Name: "static"}, &generator.Specifier{Name: "inline"}}
+}
+
+func accessorGetter(name string, field generator.Field, typ generator.Expr) *generator.FuncDef {
+	self := &generator.Ident{Name: "self"}
+	return &generator.FuncDef{
+		Prototype: generator.Prototype{
+			Attrs: accessorAttrs(),
+			Type:  typ,
+			Name:  &generator.Ident{Name: name + "_get_" + fieldName(field)},
+			Params: []generator.Param{
+				{Type: &generator.Const{Type: &generator.Pointer{Type: &generator.Ident{Name: "struct " + name}}}, Name: self},
+			},
+		},
+		Body: []generator.Stmt{
+			&generator.Return{Value: &generator.Member{Base: self, Name: fieldName(field), Arrow: true}},
+		},
+	}
+}
+
+func accessorSetter(name string, field generator.Field) *generator.FuncDef {
+	self := &generator.Ident{Name: "self"}
+	value := &generator.Ident{Name: "value"}
+	return &generator.FuncDef{
+		Prototype: generator.Prototype{
+			Attrs: accessorAttrs(),
+			Type:  &generator.Ident{Name: "void"},
+			Name:  &generator.Ident{Name: name + "_set_" + fieldName(field)},
+			Params: []generator.Param{
+				{Type: &generator.Pointer{Type: &generator.Ident{Name: "struct " + name}}, Name: self},
+				{Type: field.Type, Name: value},
+			},
+		},
+		Body: []generator.Stmt{
+			&generator.ExprStmt{Expr: &generator.BinaryOp{
+				Operator: "=",
+				Left:     &generator.Member{Base: self, Name: fieldName(field), Arrow: true},
+				Right:    value,
+			}},
+		},
+	}
+}
+
+// packedGetter copies the field into a local before returning it, reading a misaligned member through the struct
+// pointer is undefined on strict alignment targets
+func packedGetter(name string, field generator.Field) *generator.FuncDef {
+	self := &generator.Ident{Name: "self"}
+	value := &generator.Ident{Name: "value"}
+	getter := accessorGetter(name, field, getterType(field.Type))
+	getter.Body = []generator.Stmt{
+		&generator.LocalVar{Type: field.Type, Name: value},
+		memcpyStmt(value, &generator.Member{Base: self, Name: fieldName(field), Arrow: true}, value),
+		&generator.Return{Value: value},
+	}
+	return getter
+}
+
+// packedSetter copies the value into the field instead of assigning it, see packedGetter
+func packedSetter(name string, field generator.Field) *generator.FuncDef {
+	self := &generator.Ident{Name: "self"}
+	value := &generator.Ident{Name: "value"}
+	setter := accessorSetter(name, field)
+	setter.Body = []generator.Stmt{
+		memcpyStmt(&generator.Member{Base: self, Name: fieldName(field), Arrow: true}, value, value),
+	}
+	return setter
+}
+
+// memcpyStmt copies the size of sized from src to dst (memcpy(&dst, &src, sizeof(sized)))
+func memcpyStmt(dst, src, sized generator.Expr) generator.Stmt {
+	return &generator.ExprStmt{Expr: &generator.Call{
+		Callee: &generator.Ident{Name: "memcpy"},
+		Args: []generator.Expr{
+			&generator.UnaryOp{Operator: "&", Operand: dst},
+			&generator.UnaryOp{Operator: "&", Operand: src},
+			&generator.Call{Callee: &generator.Ident{Name: "sizeof"}, Args: []generator.Expr{sized}},
+		},
+	}}
+}
+
+// isPacked reports whether the attributes of a struct pack it (__attribute__((packed)))
+func isPacked(attrs []generator.Attr) bool {
+	for _, attr := range attrs {
+		if gnu, ok := attr.(*generator.GNUAttr); ok && gnu.Name == "packed" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// optionalAccessors makes the accessors of a desugared optional field: X_has_a tells whether it is present and
+// X_get_a returns its value, asserting the presence first. Optional pointers are present when not null and their
+// value is the pointee, their setter takes a pointer (null clears them). The setter of the struct form takes the
+// value and marks it present. Returns nil for arrays, which keep the plain accessors.
+func optionalAccessors(name string, field generator.Field, readonly bool) []generator.Decl {
+	if _, isArray := field.Name.(*generator.Subscript); isArray {
+		return nil
+	}
+
+	self := &generator.Ident{Name: "self"}
+	member := &generator.Member{Base: self, Name: fieldName(field), Arrow: true}
+	var present, value, valueType generator.Expr
+	switch typ := field.Type.(type) {
+	case *generator.Pointer:
+		present = &generator.BinaryOp{Operator: "!=", Left: member, Right: &generator.Ident{Name: "NULL"}}
+		value = &generator.UnaryOp{Operator: "*", Operand: member}
+		valueType = typ.Type
+	case *generator.Struct:
+		if _, isArray := typ.Fields[1].Name.(*generator.Subscript); isArray {
+			return nil
+		}
+
+		present = &generator.Member{Base: member, Name: "has"}
+		value = &generator.Member{Base: member, Name: "value"}
+		valueType = typ.Fields[1].Type
+	default:
+		return nil
+	}
+
+	constSelf := generator.Param{
+		Type: &generator.Const{Type: &generator.Pointer{Type: &generator.Ident{Name: "struct " + name}}},
+		Name: self,
+	}
+	decls := []generator.Decl{
+		&generator.FuncDef{
+			Prototype: generator.Prototype{
+				Attrs:  accessorAttrs(),
+				Type:   &generator.Ident{Name: "bool"},
+				Name:   &generator.Ident{Name: name + "_has_" + fieldName(field)},
+				Params: []generator.Param{constSelf},
+			},
+			Body: []generator.Stmt{&generator.Return{Value: present}},
+		},
+		&generator.FuncDef{
+			Prototype: generator.Prototype{
+				Attrs:  accessorAttrs(),
+				Type:   getterType(valueType),
+				Name:   &generator.Ident{Name: name + "_get_" + fieldName(field)},
+				Params: []generator.Param{constSelf},
+			},
+			Body: []generator.Stmt{
+				&generator.ExprStmt{Expr: &generator.Call{Callee: &generator.Ident{Name: "assert"}, Args: []generator.Expr{present}}},
+				&generator.Return{Value: value},
+			},
+		},
+	}
+
+	if readonly {
+		return decls
+	}
+
+	if _, isPointer := field.Type.(*generator.Pointer); isPointer {
+		return append(decls, accessorSetter(name, field))
+	}
+
+	arg := &generator.Ident{Name: "value"}
+	return append(decls, &generator.FuncDef{
+		Prototype: generator.Prototype{
+			Attrs: accessorAttrs(),
+			Type:  &generator.Ident{Name: "void"},
+			Name:  &generator.Ident{Name: name + "_set_" + fieldName(field)},
+			Params: []generator.Param{
+				{Type: &generator.Pointer{Type: &generator.Ident{Name: "struct " + name}}, Name: self},
+				{Type: valueType, Name: arg},
+			},
+		},
+		Body: []generator.Stmt{
+			&generator.ExprStmt{Expr: &generator.BinaryOp{Operator: "=", Left: present, Right: &generator.Ident{Name: "true"}}},
+			&generator.ExprStmt{Expr: &generator.BinaryOp{Operator: "=", Left: value, Right: arg}},
+		},
+	})
+}
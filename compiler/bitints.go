@@ -0,0 +1,46 @@
+package compiler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/cedmundo/SimpleSchema/analyzer"
+	"github.com/cedmundo/SimpleSchema/generator"
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+// lowerSizedInt converts an exact width integer into a C23 _BitInt, or into the smallest fixed-width integer
+// holding it when FixedWidthInts is set. Signed _BitInt needs at least two bits (one for the sign).
+func (c *Compiler) lowerSizedInt(sized *parser.SizedIntType) (generator.Expr, error) {
+	bits, err := analyzer.EvalConst(sized.Bits, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	minBits := int64(2)
+	if sized.Unsigned {
+		minBits = 1
+	}
+
+	if bits < minBits {
+		return nil, fmt.Errorf("%s: %w: integer of %d bits", parser.ExprLoc(sized.Bits), ErrUnsupportedType, bits)
+	}
+
+	if !c.config.FixedWidthInts {
+		return &generator.BitInt{Bits: &generator.Literal{Value: strconv.FormatInt(bits, 10)}, Unsigned: sized.Unsigned}, nil
+	}
+
+	prefix := "i"
+	if sized.Unsigned {
+		prefix = "u"
+	}
+
+	for _, width := range []int64{8, 16, 32, 64} {
+		if bits <= width {
+			return c.lowerTypeName(prefix + strconv.FormatInt(width, 10)), nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s: %w: integer of %d bits does not fit in 64 bits", parser.ExprLoc(sized.Bits),
+		ErrUnsupportedType, bits)
+}
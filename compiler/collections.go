@@ -0,0 +1,95 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/cedmundo/SimpleSchema/generator"
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+// lowerSet converts a set into a length prefixed array, the helper struct (struct T_set { size_t len; T* items; })
+// is emitted once before the first declaration using it
+func (c *Compiler) lowerSet(set *parser.SetType) (generator.Expr, error) {
+	name, err := collectionName(set)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := c.lowerType(set.Element)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.collectionHelper(name, []generator.Field{
+		{Type: &generator.Pointer{Type: items}, Name: &generator.Ident{Name: "items"}},
+	}), nil
+}
+
+// lowerMap converts a map into parallel arrays of keys and values, the helper struct
+// (struct K_V_map { size_t len; K* keys; V* values; }) is emitted once before the first declaration using it
+func (c *Compiler) lowerMap(m *parser.MapType) (generator.Expr, error) {
+	name, err := collectionName(m)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := c.lowerType(m.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := c.lowerType(m.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.collectionHelper(name, []generator.Field{
+		{Type: &generator.Pointer{Type: keys}, Name: &generator.Ident{Name: "keys"}},
+		{Type: &generator.Pointer{Type: values}, Name: &generator.Ident{Name: "values"}},
+	}), nil
+}
+
+// collectionHelper queues the helper struct of a collection the first time it is used and returns its type
+func (c *Compiler) collectionHelper(name string, arrays []generator.Field) generator.Expr {
+	name = c.cName(name)
+	if !c.collections[name] {
+		c.collections[name] = true
+		fields := append([]generator.Field{{Type: c.lowerTypeName("usize"), Name: &generator.Ident{Name: "len"}}}, arrays...)
+		c.pending = append(c.pending, &generator.StructDecl{Struct: generator.Struct{
+			Name:   &generator.Ident{Name: name},
+			Fields: fields,
+		}})
+	}
+
+	return &generator.Ident{Name: "struct " + name}
+}
+
+// collectionName names the helper struct of a collection after the schema names of its types (set[u8] is u8_set,
+// map[u32]set[u8] is u32_u8_set_map), only type names and other collections can be named
+func collectionName(expr parser.Expr) (string, error) {
+	switch e := expr.(type) {
+	case *parser.Ident:
+		return e.Token.Value, nil
+	case *parser.SetType:
+		element, err := collectionName(e.Element)
+		if err != nil {
+			return "", err
+		}
+
+		return element + "_set", nil
+	case *parser.MapType:
+		key, err := collectionName(e.Key)
+		if err != nil {
+			return "", err
+		}
+
+		value, err := collectionName(e.Value)
+		if err != nil {
+			return "", err
+		}
+
+		return key + "_" + value + "_map", nil
+	}
+
+	return "", fmt.Errorf("%s: %w: %T in a collection", parser.ExprLoc(expr), ErrUnsupportedType, expr)
+}
@@ -0,0 +1,1102 @@
+// Package compiler lowers a parsed schema into C declarations ready for the generator
+package compiler
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/cedmundo/SimpleSchema/analyzer"
+	"github.com/cedmundo/SimpleSchema/generator"
+	"github.com/cedmundo/SimpleSchema/lexer"
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+var (
+	// ErrUnsupportedDecl indicates that a declaration has no C equivalent (yet)
+	ErrUnsupportedDecl = errors.New("unsupported declaration")
+
+	// ErrUnsupportedType indicates that a type expression cannot be lowered into a C type
+	ErrUnsupportedType = errors.New("unsupported type")
+
+	// ErrUnsupportedExpr indicates that a value expression cannot be lowered into a C expression
+	ErrUnsupportedExpr = errors.New("unsupported expression")
+
+	// ErrDuplicateModule indicates that a schema declares more than one module
+	ErrDuplicateModule = errors.New("duplicate module")
+
+	// ErrInvalidOption indicates that the options directive has an unknown setting or an invalid value
+	ErrInvalidOption = errors.New("invalid option")
+)
+
+// Config toggles the optional parts of the lowering
+type Config struct {
+	// StaticAsserts emits a _Static_assert after each struct annotated with its expected size ([[ size = N ]])
+	StaticAsserts bool
+
+	// DefaultValues emits a static const X_default initialized from the field defaults after each struct having any
+	DefaultValues bool
+
+	// UnionVisitors emits a tag enum, a visitor struct and a X_visit function dispatching on the tag after each union
+	UnionVisitors bool
+
+	// AccessorMacros makes [[ accessors ]] emit a #define X_a(x) ((x)->a) macro per field instead of the getter and
+	// setter functions
+	AccessorMacros bool
+
+	// UnionAccessAsserts starts the getters of tagged unions annotated with [[ accessors ]] with an assert that the
+	// member read is the active one (assert(self->tag == X_tag_a))
+	UnionAccessAsserts bool
+
+	// ExhaustiveSwitches appends a X_tag_COUNT member to the generated tag enums and a _Static_assert on it after
+	// each generated visitor (_Static_assert(X_tag_COUNT == 2, "...")). Hand-written switches can assert on the
+	// count the same way, so adding a member breaks the build until they are updated.
+	ExhaustiveSwitches bool
+
+	// RangeValidators emits a X_a_valid function after each struct per field with a range constraint (in 0..100)
+	RangeValidators bool
+
+	// RangeBounds emits a X_A_MIN and X_A_MAX macro after each struct per field with a range constraint, holding
+	// the folded bounds
+	RangeBounds bool
+
+	// TypedefEnums emits enums as typedef enum { A, B } Name; instead of enum Name { A, B };, so they are referenced
+	// without the enum keyword
+	TypedefEnums bool
+
+	// WireSizes emits a #define X_WIRE_SIZE n after each struct whose fields all have a fixed size, the packed size
+	// in bytes of its serialized form so callers can allocate buffers up front
+	WireSizes bool
+
+	// ReflectionTables emits a X_fields table after each struct describing the name, offset, kind and type of every
+	// field, along with the X_field_infos function returning it, so the target can (de)serialize structs
+	// generically. The entries are field_info structs, declared once per file along with the field_kind enum.
+	ReflectionTables bool
+
+	// EnumTables emits a X_info struct and a static X_table indexed by the members after each enum whose members have
+	// custom annotations ([[ rgb = [255, 0, 0] ]]), the annotations known to the toolchain are left out
+	EnumTables bool
+
+	// EnumValueComments writes the value of each enum member after it (RED = 4, /* 4 */ GREEN, /* 5 */), members
+	// whose value is not an integer literal nor follows one are left without comment
+	EnumValueComments bool
+
+	// FlagHelpers emits a X_flags storage typedef and the X_has, X_set and X_clear inline functions after each enum
+	// annotated with [[ flags ]]
+	FlagHelpers bool
+
+	// OptionalPointers lowers the optional types (?T) to pointers instead of struct { bool has; T value; }, see
+	// analyzer.Desugar. The struct form cannot be used in prototypes, optionals there need the pointers.
+	OptionalPointers bool
+
+	// FixedWidthInts lowers the exact width integers (int<24>) to the smallest fixed-width type holding them
+	// (int32_t) for compilers without C23 _BitInt
+	FixedWidthInts bool
+
+	// ModulePrefix prefixes the generated type and function names with the module path, since C has no namespaces
+	// (module net.http; makes net_http_Request)
+	ModulePrefix bool
+
+	// VersionSuffixes suffixes the names of the types and procs annotated with [[ version = n ]] with _vn, so a
+	// new revision of a declaration gets a new symbol (Request_v2) while both may coexist in a binary
+	VersionSuffixes bool
+
+	// PrefixSeparator joins the module path parts and the prefixed name, defaults to "_"
+	PrefixSeparator string
+
+	// PrefixCase converts each part of the module path (like naming.ToPascal), nil keeps them as written
+	PrefixCase func(string) string
+
+	// HeaderName is the header included by the source of CompileSplit, defaults to the module path (net_http.h)
+	HeaderName string
+}
+
+// Compiler lowers schemas into generator files
+type Compiler struct {
+	config   Config
+	kinds    map[string]string
+	includes map[string]bool
+	prefix   string
+	consts   map[string]int64
+	types    map[string]parser.Expr
+
+	// optionals are the names of the optional fields (?T) per struct, they are plain types once desugared
+	optionals map[string]map[string]bool
+
+	// versions are the [[ version = n ]] of the types and procs, only collected with Config.VersionSuffixes
+	versions map[string]int64
+
+	// collections are the helper structs already emitted for sets, maps and reflection tables, pending are the ones
+	// to emit before the declaration being compiled
+	collections map[string]bool
+	pending     []generator.Decl
+
+	// split is set by CompileSplit, the helpers that are not one-liners get external linkage so their bodies can
+	// move to the source file
+	split bool
+}
+
+// New returns a compiler using the given configuration
+func New(config Config) *Compiler {
+	return &Compiler{config: config}
+}
+
+// Compile lowers a schema using the default configuration
+func Compile(schema *parser.Schema) (*generator.File, error) {
+	return New(Config{}).Compile(schema)
+}
+
+// Compile lowers every declaration of the schema, declarations following a module are wrapped in its ward.
+// Types are emitted first in dependency order, followed by the rest of the declarations in source order.
+func (c *Compiler) Compile(schema *parser.Schema) (*generator.File, error) {
+	if !c.config.OptionalPointers {
+		err := validatePrototypeOptionals(schema)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c.optionals = collectOptionals(schema)
+	schema = analyzer.Desugar(schema, analyzer.DesugarOptions{OptionalPointers: c.config.OptionalPointers})
+	err := analyzer.MarkDeprecated(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	// the included fields are emitted as anonymous members, the expansion only validates them
+	_, err = analyzer.ExpandEmbeds(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	c.consts, err = analyzer.FoldConstants(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	err = analyzer.ValidateByteStrings(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	c.versions = nil
+	if c.config.VersionSuffixes {
+		c.versions, err = collectVersions(schema, c.consts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c.kinds = collectKinds(schema, c.config.TypedefEnums)
+	c.includes = make(map[string]bool)
+	c.types = make(map[string]parser.Expr)
+	c.collections = make(map[string]bool)
+	c.pending = nil
+	c.prefix = ""
+
+	var ward *generator.ModuleWard
+	var options *parser.OptionsDecl
+	typeDecls := make([]parser.Decl, 0)
+	otherDecls := make([]parser.Decl, 0)
+	for _, decl := range schema.Decls {
+		switch d := unwrapDecl(decl).(type) {
+		case *parser.ModuleDecl:
+			if ward != nil {
+				return nil, fmt.Errorf("%s: %w", parser.ExprLoc(d.Name), ErrDuplicateModule)
+			}
+
+			ward = &generator.ModuleWard{Name: wardName(parser.LookupName(d.Name))}
+			if c.config.ModulePrefix {
+				c.prefix = c.modulePrefix(parser.LookupName(d.Name))
+			}
+		case *parser.OptionsDecl:
+			options = d
+		case *parser.TypeDecl:
+			typeDecls = append(typeDecls, decl)
+			c.types[parser.LookupName(d.Name)] = d.Type
+		case *parser.ImportDecl:
+			// imports are resolved by the loader, there is nothing to emit
+		case *parser.PackageDecl:
+			// packages namespace other backends, C names are only prefixed by the module
+		default:
+			otherDecls = append(otherDecls, decl)
+		}
+	}
+
+	decls := make([]generator.Decl, 0)
+	if options != nil {
+		optionDecls, err := c.compileOptions(options)
+		if err != nil {
+			return nil, err
+		}
+		decls = append(decls, optionDecls...)
+	}
+
+	ordered, err := c.orderTypeDecls(typeDecls)
+	if err != nil {
+		return nil, err
+	}
+
+	declared := make(map[string]bool)
+	for _, node := range ordered {
+		for _, dep := range node.soft {
+			if declared[dep] {
+				continue
+			}
+
+			declared[dep] = true
+			decls = append(decls, &generator.ForwardDecl{Tag: c.kinds[dep], Name: &generator.Ident{Name: c.cName(dep)}})
+		}
+
+		lowered, err := c.compileDecl(node.decl, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		declared[node.name] = true
+		decls = append(decls, c.takePending()...)
+		decls = append(decls, lowered...)
+	}
+
+	for _, decl := range otherDecls {
+		lowered, err := c.compileDecl(decl, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		decls = append(decls, c.takePending()...)
+		decls = append(decls, lowered...)
+	}
+
+	decls = append(c.compileIncludes(), decls...)
+	if ward == nil {
+		return &generator.File{Decls: decls}, nil
+	}
+
+	ward.Decls = decls
+	return &generator.File{Decls: []generator.Decl{ward}}, nil
+}
+
+// takePending returns the helper declarations queued while compiling the last declaration
+func (c *Compiler) takePending() []generator.Decl {
+	pending := c.pending
+	c.pending = nil
+	return pending
+}
+
+func (c *Compiler) compileIncludes() []generator.Decl {
+	files := make([]string, 0, len(c.includes))
+	for file := range c.includes {
+		files = append(files, file)
+	}
+	slices.Sort(files)
+
+	decls := make([]generator.Decl, 0, len(files))
+	for _, file := range files {
+		decls = append(decls, &generator.Include{File: file})
+	}
+	return decls
+}
+
+func (c *Compiler) compileDecl(decl parser.Decl, annotated *parser.AnnotatedDecl) ([]generator.Decl, error) {
+	switch d := decl.(type) {
+	case *parser.AnnotatedDecl:
+		decls, err := c.compileDecl(d.Decl, d)
+		if err != nil {
+			return nil, err
+		}
+
+		// procs document their parameters as well, see compileProcDecl
+		if _, isProc := d.Decl.(*parser.ProcDecl); isProc {
+			return decls, nil
+		}
+
+		if doc := compileDoc(d, nil); doc != nil {
+			decls = append([]generator.Decl{doc}, decls...)
+		}
+		return decls, nil
+	case *parser.TypeDecl:
+		return c.compileTypeDecl(d, annotated)
+	case *parser.ProcDecl:
+		return c.compileProcDecl(d, annotated)
+	case *parser.ConstDecl:
+		return c.compileConstDecl(d, annotated)
+	}
+
+	return nil, fmt.Errorf("%w: %T", ErrUnsupportedDecl, decl)
+}
+
+func (c *Compiler) compileTypeDecl(decl *parser.TypeDecl, annotated *parser.AnnotatedDecl) ([]generator.Decl, error) {
+	// C has no generics, a generic type would have to be instantiated for each of its uses
+	if len(decl.Params) > 0 {
+		return nil, fmt.Errorf("%s: %w: generic type", parser.ExprLoc(decl.Name), ErrUnsupportedDecl)
+	}
+
+	name := c.cName(parser.LookupName(decl.Name))
+	body := decl.Type
+	if decl.IsAlias {
+		// aliases are always a typedef, even of an inline struct (typedef struct { ... } name;)
+		body = nil
+	}
+
+	switch def := body.(type) {
+	case *parser.StructDef:
+		return c.compileStruct(name, def, annotated, c.optionals[parser.LookupName(decl.Name)])
+	case *parser.UnionDef:
+		return c.compileUnion(name, def, annotated)
+	case *parser.EnumDef:
+		members, err := c.compileMembers(def.Block)
+		if err != nil {
+			return nil, err
+		}
+
+		attrs, err := annotatedAttrs(annotated)
+		if err != nil {
+			return nil, err
+		}
+
+		var decls []generator.Decl
+		var enumType generator.Expr
+		if c.config.TypedefEnums {
+			enum := &generator.Enum{TagAttrs: attrs, Members: members}
+			decls = []generator.Decl{&generator.Typedef{Type: enum, Name: &generator.Ident{Name: name}}}
+			enumType = &generator.Ident{Name: name}
+		} else {
+			enum := generator.Enum{TagAttrs: attrs, Name: &generator.Ident{Name: name}, Members: members}
+			decls = []generator.Decl{&generator.EnumDecl{Enum: enum}}
+			enumType = &generator.Ident{Name: "enum " + name}
+		}
+
+		if analyzer.IsStringEnum(def) {
+			table, err := compileEnumStrings(name, def)
+			if err != nil {
+				return nil, err
+			}
+
+			decls = append(decls, table)
+		}
+
+		if c.config.EnumTables {
+			table, err := c.compileEnumTable(name, def)
+			if err != nil {
+				return nil, err
+			}
+
+			decls = append(decls, table...)
+		}
+
+		if _, ok := annotated.Find("flags"); c.config.FlagHelpers && ok {
+			decls = append(decls, c.compileFlagHelpers(name, def, enumType)...)
+		}
+
+		return decls, nil
+	}
+
+	typ, declarator, err := c.lowerDeclarator(decl.Type, &generator.Ident{Name: name})
+	if err != nil {
+		return nil, err
+	}
+
+	decls := []generator.Decl{&generator.Typedef{Type: typ, Name: declarator}}
+	scale, err := decimalScale(name, decl.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	if scale != nil {
+		decls = append(decls, scale)
+	}
+
+	return decls, nil
+}
+
+func (c *Compiler) compileStruct(name string, def *parser.StructDef, annotated *parser.AnnotatedDecl,
+	optionals map[string]bool) ([]generator.Decl, error) {
+	// opaque structs only expose a handle, the body stays private to the implementation
+	if _, ok := annotated.Find("opaque"); ok {
+		return []generator.Decl{&generator.OpaqueDecl{
+			Tag:    "struct",
+			Name:   &generator.Ident{Name: name},
+			Handle: &generator.Ident{Name: name + "_handle"},
+		}}, nil
+	}
+
+	fields, err := c.compileFields(def.Block)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs, err := annotatedAttrs(annotated)
+	if err != nil {
+		return nil, err
+	}
+
+	strct := generator.Struct{TagAttrs: attrs, Name: &generator.Ident{Name: name}, Fields: fields}
+	decls := []generator.Decl{&generator.StructDecl{Struct: strct}}
+
+	scales, err := compileDecimalScales(name, def.Block)
+	if err != nil {
+		return nil, err
+	}
+	decls = append(decls, scales...)
+
+	if c.config.WireSizes {
+		decls = append(decls, c.compileWireSize(name, def)...)
+	}
+
+	size, ok := annotated.Find("size")
+	if c.config.StaticAsserts && ok {
+		value, err := c.lowerValue(size.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		decls = append(decls, &generator.StaticAssert{
+			Condition: fmt.Sprintf("sizeof(struct %s) == %s", name, value.Generate(0)),
+			Message:   fmt.Sprintf("struct %s must be %s bytes", name, value.Generate(0)),
+		})
+	}
+
+	if _, ok := annotated.Find("accessors"); ok && c.config.AccessorMacros {
+		decls = append(decls, compileAccessorMacros(name, fields)...)
+	} else if ok {
+		if len(optionals) != 0 {
+			c.includes["assert.h"] = true
+			c.includes["stdbool.h"] = true
+			if c.config.OptionalPointers {
+				c.includes["stddef.h"] = true
+			}
+		}
+
+		packed := isPacked(attrs)
+		if packed {
+			c.includes["string.h"] = true
+		}
+
+		decls = append(decls, compileAccessors(name, def.Block, fields, optionals, packed)...)
+	}
+
+	if c.config.ReflectionTables {
+		if reflection := c.compileReflection(name, fields); reflection != nil {
+			c.includes["stddef.h"] = true
+			decls = append(decls, reflection...)
+		}
+	}
+
+	if c.config.RangeValidators {
+		validators, err := c.compileRangeValidators(name, def.Block, fields)
+		if err != nil {
+			return nil, err
+		}
+
+		decls = append(decls, validators...)
+	}
+
+	if c.config.RangeBounds {
+		bounds, err := c.compileRangeBounds(name, def.Block, fields)
+		if err != nil {
+			return nil, err
+		}
+
+		decls = append(decls, bounds...)
+	}
+
+	if c.config.DefaultValues {
+		defaults, err := c.compileDefaults(name, def.Block, optionals)
+		if err != nil {
+			return nil, err
+		}
+
+		decls = append(decls, defaults...)
+	}
+
+	return decls, nil
+}
+
+// compileDefaults makes a designated initializer from the fields having a default value, fields without one are
+// omitted (and thus zeroed), structs without defaults produce nothing. The defaults of the optional fields (named
+// by optionals) mark them present, optional pointers cannot point to a default so they are rejected.
+func (c *Compiler) compileDefaults(name string, block parser.Block, optionals map[string]bool) ([]generator.Decl, error) {
+	init := &generator.StructInit{}
+	for _, decl := range block.Decls {
+		field, ok := unwrapDecl(decl).(*parser.Field)
+		if !ok || field.Value == nil {
+			continue
+		}
+
+		value, err := c.lowerValue(field.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		if optionals[parser.LookupName(field.Name)] {
+			if c.config.OptionalPointers {
+				return nil, fmt.Errorf("%s: %w: default of an optional pointer", parser.ExprLoc(field.Value),
+					ErrUnsupportedExpr)
+			}
+
+			value = &generator.StructInit{Fields: []generator.FieldInit{
+				{Name: "has", Value: &generator.Ident{Name: "true"}},
+				{Name: "value", Value: value},
+			}}
+		}
+
+		init.Fields = append(init.Fields, generator.FieldInit{Name: parser.LookupName(field.Name), Value: value})
+	}
+
+	if len(init.Fields) == 0 {
+		return nil, nil
+	}
+
+	return []generator.Decl{&generator.GlobalVar{
+		Storage: "static",
+		Type:    &generator.Const{Type: &generator.Ident{Name: "struct " + name}},
+		Name:    &generator.Ident{Name: name + "_default"},
+		Value:   init,
+	}}, nil
+}
+
+func (c *Compiler) compileFields(block parser.Block) ([]generator.Field, error) {
+	fields := make([]generator.Field, 0, len(block.Decls))
+	for _, decl := range block.Decls {
+		if include, ok := decl.(*parser.EmbedDecl); ok {
+			field, err := c.compileEmbed(include)
+			if err != nil {
+				return nil, err
+			}
+
+			fields = append(fields, field)
+			continue
+		}
+
+		field, ok := unwrapDecl(decl).(*parser.Field)
+		if !ok || field.Type == nil {
+			return nil, fmt.Errorf("%w: field without type", ErrUnsupportedDecl)
+		}
+
+		typ, name, err := c.lowerDeclarator(field.Type, &generator.Ident{Name: parser.LookupName(field.Name)})
+		if err != nil {
+			return nil, err
+		}
+
+		annotated, _ := decl.(*parser.AnnotatedDecl)
+		attrs, err := annotatedAttrs(annotated)
+		if err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, generator.Field{
+			Doc:   compileDoc(annotated, field.Leading),
+			Attrs: attrs,
+			Type:  typ,
+			Name:  name,
+		})
+	}
+
+	return fields, nil
+}
+
+// compileEmbed lowers an include to an anonymous member holding the fields of the included struct, so they are
+// accessed as fields of the including one while keeping the layout of the included struct
+func (c *Compiler) compileEmbed(include *parser.EmbedDecl) (generator.Field, error) {
+	name := parser.LookupName(include.Type)
+	def, ok := c.types[name].(*parser.StructDef)
+	if !ok {
+		return generator.Field{}, fmt.Errorf("%s: %w: include of `%s`", parser.ExprLoc(include.Type),
+			ErrUnsupportedDecl, name)
+	}
+
+	fields, err := c.compileFields(def.Block)
+	if err != nil {
+		return generator.Field{}, err
+	}
+
+	return generator.Field{Type: &generator.Struct{Fields: fields}}, nil
+}
+
+func (c *Compiler) compileMembers(block parser.Block) ([]generator.EnumMember, error) {
+	values := analyzer.EnumValues(&parser.EnumDef{Block: block})
+	members := make([]generator.EnumMember, 0, len(block.Decls))
+	for _, decl := range block.Decls {
+		field, ok := unwrapDecl(decl).(*parser.Field)
+		if !ok {
+			return nil, fmt.Errorf("%w: %T in enum", ErrUnsupportedDecl, decl)
+		}
+
+		// string values go to the name table instead (see compileEnumStrings), the members count from zero
+		member := generator.EnumMember{Name: &generator.Ident{Name: parser.LookupName(field.Name)}}
+		if literal, ok := field.Value.(*parser.Literal); ok && literal.Token.Tag == lexer.TokenTagString {
+			members = append(members, member)
+			continue
+		}
+
+		if field.Value != nil {
+			value, err := c.lowerValue(field.Value)
+			if err != nil {
+				return nil, err
+			}
+
+			member.Value = value
+		}
+
+		// the values are computed in the same order, one per field
+		if value := values[len(members)]; c.config.EnumValueComments && value.Known {
+			member.Comment = strconv.FormatInt(value.Value, 10)
+		}
+
+		members = append(members, member)
+	}
+
+	return members, nil
+}
+
+// compileEnumStrings makes the name table of a string enum (static const char* X_strings[]), indexed by the members
+// since they are emitted without values
+func compileEnumStrings(name string, def *parser.EnumDef) (generator.Decl, error) {
+	table := &generator.ArrayInit{}
+	for _, decl := range def.Block.Decls {
+		field, ok := unwrapDecl(decl).(*parser.Field)
+		if !ok {
+			return nil, fmt.Errorf("%w: %T in enum", ErrUnsupportedDecl, decl)
+		}
+
+		literal, ok := field.Value.(*parser.Literal)
+		if !ok || literal.Token.Tag != lexer.TokenTagString {
+			return nil, fmt.Errorf("%s: %w: `%s` has no string value", parser.ExprLoc(field.Name), ErrUnsupportedExpr,
+				parser.LookupName(field.Name))
+		}
+
+		table.Elems = append(table.Elems, &generator.Literal{Value: literalText(literal.Token)})
+	}
+
+	return &generator.GlobalVar{
+		Storage: "static",
+		Type:    &generator.Pointer{Type: &generator.Const{Type: &generator.Ident{Name: "char"}}},
+		Name:    &generator.Subscript{Base: &generator.Ident{Name: name + "_strings"}},
+		Value:   table,
+	}, nil
+}
+
+// compileConstDecl emits a static const variable initialized with the folded value of the constant
+func (c *Compiler) compileConstDecl(decl *parser.ConstDecl, annotated *parser.AnnotatedDecl) ([]generator.Decl, error) {
+	typ, err := c.lowerType(decl.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs, err := annotatedAttrs(annotated)
+	if err != nil {
+		return nil, err
+	}
+
+	// plain literals keep the radix they were written in (0xFF stays 0xFF), expressions are folded
+	name := parser.LookupName(decl.Name)
+	value := strconv.FormatInt(c.consts[name], 10)
+	if literal, ok := decl.Value.(*parser.Literal); ok {
+		value = literalText(literal.Token)
+	}
+
+	return []generator.Decl{&generator.GlobalVar{
+		Attrs:   attrs,
+		Storage: "static",
+		Type:    &generator.Const{Type: typ},
+		Name:    &generator.Ident{Name: c.cName(name)},
+		Value:   &generator.Literal{Value: value},
+	}}, nil
+}
+
+func (c *Compiler) compileProcDecl(decl *parser.ProcDecl, annotated *parser.AnnotatedDecl) ([]generator.Decl, error) {
+	if len(decl.Params) > 0 {
+		return nil, fmt.Errorf("%s: %w: generic proc", parser.ExprLoc(decl.Name), ErrUnsupportedDecl)
+	}
+
+	def, ok := decl.Type.(*parser.PrototypeDef)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w: proc without prototype", parser.ExprLoc(decl.Name), ErrUnsupportedDecl)
+	}
+
+	returnType, err := c.lowerType(def.ReturnType)
+	if err != nil {
+		return nil, err
+	}
+
+	params := make([]generator.Param, 0, len(def.Params))
+	for _, field := range def.Params {
+		param := generator.Param{}
+		if field.Name == nil {
+			param.Type, err = c.lowerType(field.Type)
+		} else {
+			param.Type, param.Name, err = c.lowerDeclarator(field.Type, &generator.Ident{Name: parser.LookupName(field.Name)})
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		params = append(params, param)
+	}
+
+	attrs, err := annotatedAttrs(annotated)
+	if err != nil {
+		return nil, err
+	}
+
+	name := c.cName(parser.LookupName(decl.Name))
+	proto := generator.Prototype{
+		Attrs:  attrs,
+		Type:   returnType,
+		Name:   &generator.Ident{Name: name},
+		Params: params,
+	}
+
+	decls := []generator.Decl{&generator.PrototypeDecl{Prototype: proto}}
+	if doc := compileProcDoc(def, annotated); doc != nil {
+		decls = append([]generator.Decl{doc}, decls...)
+	}
+
+	paramDecls := make([]parser.Decl, 0, len(def.Params))
+	for i := range def.Params {
+		paramDecls = append(paramDecls, &def.Params[i])
+	}
+
+	scales, err := compileDecimalScales(name, parser.Block{Decls: paramDecls})
+	if err != nil {
+		return nil, err
+	}
+	decls = append(decls, scales...)
+
+	return decls, nil
+}
+
+// compileDoc makes a documentation block from the doc (or docs) annotation, falling back to the comments written
+// right before the declaration. Returns nil for undocumented declarations.
+func compileDoc(annotated *parser.AnnotatedDecl, leading []lexer.Token) *generator.DocComment {
+	if text, ok := annotationText(annotated, "doc", "docs"); ok {
+		return &generator.DocComment{Text: text}
+	}
+
+	if len(leading) == 0 {
+		return nil
+	}
+
+	lines := make([]string, 0, len(leading))
+	for _, comment := range leading {
+		line := strings.TrimPrefix(comment.Value, "#")
+		lines = append(lines, strings.TrimPrefix(line, " "))
+	}
+
+	return &generator.DocComment{Text: strings.Join(lines, "\n")}
+}
+
+// compileProcDoc documents a proc like compileDoc and adds a doxygen @param line per documented parameter
+// ([[ doc = "..." ]] key : string) and a @return line from the returns annotation. Returns nil for undocumented
+// procs.
+func compileProcDoc(def *parser.PrototypeDef, annotated *parser.AnnotatedDecl) *generator.DocComment {
+	tags := make([]string, 0, len(def.Params)+1)
+	for _, param := range def.Params {
+		text, ok := annotationText(&parser.AnnotatedDecl{Annotations: param.Annotations}, "doc", "docs")
+		if ok && param.Name != nil {
+			tags = append(tags, "@param "+parser.LookupName(param.Name)+" "+text)
+		}
+	}
+
+	if text, ok := annotationText(annotated, "returns"); ok {
+		tags = append(tags, "@return "+text)
+	}
+
+	doc := compileDoc(annotated, nil)
+	if len(tags) == 0 {
+		return doc
+	}
+
+	// the tags are separated from the description by an empty line
+	if doc != nil {
+		tags = append([]string{doc.Text, ""}, tags...)
+	}
+
+	return &generator.DocComment{Text: strings.Join(tags, "\n")}
+}
+
+// annotationText returns the value of the first string annotation found among names
+func annotationText(annotated *parser.AnnotatedDecl, names ...string) (string, bool) {
+	for _, name := range names {
+		annotation, ok := annotated.Find(name)
+		if !ok {
+			continue
+		}
+
+		literal, ok := annotation.Value.(*parser.Literal)
+		if ok && literal.Token.Tag == lexer.TokenTagString {
+			return literal.Token.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// modulePrefix flattens a module path into the prefix of its names (net.http -> net_http_)
+func (c *Compiler) modulePrefix(module string) string {
+	separator := c.config.PrefixSeparator
+	if separator == "" {
+		separator = "_"
+	}
+
+	parts := strings.Split(module, ".")
+	if c.config.PrefixCase != nil {
+		for i, part := range parts {
+			parts[i] = c.config.PrefixCase(part)
+		}
+	}
+
+	return strings.Join(parts, separator) + separator
+}
+
+// cName returns the C name of a top level type or procedure, see Config.ModulePrefix and Config.VersionSuffixes
+func (c *Compiler) cName(name string) string {
+	if version, ok := c.versions[name]; ok {
+		return c.prefix + name + "_v" + strconv.FormatInt(version, 10)
+	}
+
+	return c.prefix + name
+}
+
+// collectVersions maps each top level type and proc annotated with [[ version = n ]] to its version
+func collectVersions(schema *parser.Schema, consts map[string]int64) (map[string]int64, error) {
+	versions := make(map[string]int64)
+	for _, decl := range schema.Decls {
+		annotated, ok := decl.(*parser.AnnotatedDecl)
+		if !ok {
+			continue
+		}
+
+		var name parser.Expr
+		switch d := unwrapDecl(decl).(type) {
+		case *parser.TypeDecl:
+			name = d.Name
+		case *parser.ProcDecl:
+			name = d.Name
+		default:
+			continue
+		}
+
+		annotation, ok := annotated.Find("version")
+		if !ok {
+			continue
+		}
+
+		version, err := analyzer.EvalConst(annotation.Value, consts)
+		if err != nil || version < 0 {
+			return nil, fmt.Errorf("%s: %w: version expects a non negative integer", parser.ExprLoc(annotation.Name),
+				analyzer.ErrInvalidAnnotationValue)
+		}
+
+		versions[parser.LookupName(name)] = version
+	}
+
+	return versions, nil
+}
+
+// annotatedAttrs lowers the annotations that map to GNU attributes: the deprecation metadata (see
+// analyzer.MarkDeprecated), the alignment ([[ align = 16 ]] makes aligned(16)) and every [[ c_attr = "x" ]], whose
+// value is passed through as written so it may carry arguments ([[ c_attr = "aligned(8)" ]])
+func annotatedAttrs(annotated *parser.AnnotatedDecl) ([]generator.Attr, error) {
+	if annotated == nil {
+		return nil, nil
+	}
+
+	var attrs []generator.Attr
+	if annotated.Deprecated {
+		attr := &generator.GNUAttr{Name: "deprecated"}
+		if annotated.DeprecationMessage != "" {
+			attr.Args = []generator.Expr{&generator.Literal{Value: strconv.Quote(annotated.DeprecationMessage)}}
+		}
+
+		attrs = append(attrs, attr)
+	}
+
+	if align, ok := annotated.Find("align"); ok {
+		literal, ok := align.Value.(*parser.Literal)
+		if !ok {
+			return nil, fmt.Errorf("%s: %w: align expects an integer", parser.ExprLoc(align.Name),
+				analyzer.ErrInvalidAnnotationValue)
+		}
+
+		size := &generator.Literal{Value: literalText(literal.Token)}
+		attrs = append(attrs, &generator.GNUAttr{Name: "aligned", Args: []generator.Expr{size}})
+	}
+
+	for _, annotation := range annotated.Annotations {
+		if parser.LookupName(annotation.Name) != "c_attr" {
+			continue
+		}
+
+		literal, ok := annotation.Value.(*parser.Literal)
+		if !ok || literal.Token.Tag != lexer.TokenTagString {
+			return nil, fmt.Errorf("%s: %w: c_attr expects a string", parser.ExprLoc(annotation.Name),
+				analyzer.ErrInvalidAnnotationValue)
+		}
+
+		attrs = append(attrs, &generator.GNUAttr{Name: literal.Token.Value})
+	}
+
+	return attrs, nil
+}
+
+// validatePrototypeOptionals rejects the optionals (?T) within prototypes when they are desugared as structs, C
+// cannot declare the anonymous struct in a parameter list (nor return it) so they need Config.OptionalPointers
+func validatePrototypeOptionals(schema *parser.Schema) error {
+	for _, decl := range schema.Decls {
+		var optional *parser.OptionalType
+		switch d := unwrapDecl(decl).(type) {
+		case *parser.TypeDecl:
+			optional = prototypeOptional(d.Type, false)
+		case *parser.ProcDecl:
+			optional = prototypeOptional(d.Type, false)
+		}
+
+		if optional != nil {
+			return fmt.Errorf("%s: %w: optional within a prototype, only supported as a pointer (OptionalPointers)",
+				optional.Token.Loc, ErrUnsupportedType)
+		}
+	}
+
+	return nil
+}
+
+// prototypeOptional returns the first optional found within the parameters or the return type of a prototype
+func prototypeOptional(expr parser.Expr, inPrototype bool) *parser.OptionalType {
+	var parts []parser.Expr
+	switch e := expr.(type) {
+	case *parser.OptionalType:
+		if inPrototype {
+			return e
+		}
+		parts = []parser.Expr{e.Type}
+	case *parser.PrototypeDef:
+		for _, param := range e.Params {
+			if optional := prototypeOptional(param.Type, true); optional != nil {
+				return optional
+			}
+		}
+		return prototypeOptional(e.ReturnType, true)
+	case *parser.UnaryOp:
+		parts = []parser.Expr{e.Operand}
+	case *parser.Index:
+		parts = []parser.Expr{e.Base}
+	case *parser.Call:
+		parts = e.Args
+	case *parser.TupleType:
+		parts = e.Elements
+	case *parser.SetType:
+		parts = []parser.Expr{e.Element}
+	case *parser.MapType:
+		parts = []parser.Expr{e.Key, e.Value}
+	case *parser.StructDef:
+		parts = blockTypes(e.Block)
+	case *parser.UnionDef:
+		parts = blockTypes(e.Block)
+	}
+
+	for _, part := range parts {
+		if optional := prototypeOptional(part, inPrototype); optional != nil {
+			return optional
+		}
+	}
+
+	return nil
+}
+
+// blockTypes returns the types of the fields of a block
+func blockTypes(block parser.Block) []parser.Expr {
+	types := make([]parser.Expr, 0, len(block.Decls))
+	for _, decl := range block.Decls {
+		if field, ok := unwrapDecl(decl).(*parser.Field); ok && field.Type != nil {
+			types = append(types, field.Type)
+		}
+	}
+
+	return types
+}
+
+// collectOptionals maps each top level struct name to the names of its optional fields
+func collectOptionals(schema *parser.Schema) map[string]map[string]bool {
+	optionals := make(map[string]map[string]bool)
+	for _, decl := range schema.Decls {
+		typeDecl, ok := unwrapDecl(decl).(*parser.TypeDecl)
+		if !ok {
+			continue
+		}
+
+		def, ok := typeDecl.Type.(*parser.StructDef)
+		if !ok {
+			continue
+		}
+
+		for _, decl := range def.Block.Decls {
+			field, ok := unwrapDecl(decl).(*parser.Field)
+			if !ok {
+				continue
+			}
+
+			if _, optional := field.Type.(*parser.OptionalType); optional {
+				name := parser.LookupName(typeDecl.Name)
+				if optionals[name] == nil {
+					optionals[name] = make(map[string]bool)
+				}
+				optionals[name][parser.LookupName(field.Name)] = true
+			}
+		}
+	}
+
+	return optionals
+}
+
+// collectKinds maps each top level type name to its C tag (struct, union or enum), typedefs (and aliases) have no
+// tag and neither do enums when they are emitted as typedefs
+func collectKinds(schema *parser.Schema, typedefEnums bool) map[string]string {
+	kinds := make(map[string]string)
+	for _, decl := range schema.Decls {
+		typeDecl, ok := unwrapDecl(decl).(*parser.TypeDecl)
+		if !ok {
+			continue
+		}
+
+		// aliases are typedefs even of inline structs, they are referenced without tag
+		name := parser.LookupName(typeDecl.Name)
+		if typeDecl.IsAlias {
+			kinds[name] = ""
+			continue
+		}
+
+		switch typeDecl.Type.(type) {
+		case *parser.StructDef:
+			kinds[name] = "struct"
+		case *parser.UnionDef:
+			kinds[name] = "union"
+		case *parser.EnumDef:
+			if !typedefEnums {
+				kinds[name] = "enum"
+				break
+			}
+
+			kinds[name] = ""
+		default:
+			kinds[name] = ""
+		}
+	}
+
+	return kinds
+}
+
+func unwrapDecl(decl parser.Decl) parser.Decl {
+	if annotated, ok := decl.(*parser.AnnotatedDecl); ok {
+		return unwrapDecl(annotated.Decl)
+	}
+
+	return decl
+}
+
+func wardName(module string) string {
+	return strings.ToUpper(strings.ReplaceAll(module, ".", "_")) + "_H"
+}
@@ -0,0 +1,1338 @@
+package compiler_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cedmundo/SimpleSchema/analyzer"
+	"github.com/cedmundo/SimpleSchema/compiler"
+	"github.com/cedmundo/SimpleSchema/naming"
+	"github.com/cedmundo/SimpleSchema/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func compileString(t *testing.T, name, input string, config compiler.Config) (string, error) {
+	t.Helper()
+	schema, err := parser.NewFromString(name, input).Parse()
+	require.NoError(t, err)
+
+	file, err := compiler.New(config).Compile(schema)
+	if err != nil {
+		return "", err
+	}
+
+	return file.Generate(0), nil
+}
+
+func TestCompiler_Compile(t *testing.T) {
+	cases := []struct {
+		name           string
+		input          string
+		config         compiler.Config
+		expectedString string
+		expectedErr    error
+	}{
+		{
+			name:           "module ward",
+			input:          "module hello;",
+			expectedString: "#ifndef HELLO_H\n#define HELLO_H\n#endif /* HELLO_H */\n\n",
+		},
+		{
+			name:           "struct with builtin fields",
+			input:          "type s struct {\na : u32\nb : float[4]\n}\n",
+			expectedString: "#include <stdint.h>\nstruct s {\n  uint32_t a;\n  float b[4];\n};\n",
+		},
+		{
+			name:           "struct referencing other types",
+			input:          "type a struct {}\ntype b struct {\nx : a\ny : *a\n}\n",
+			expectedString: "struct a {};\nstruct b {\n  struct a x;\n  struct a* y;\n};\n",
+		},
+		{
+			name:           "struct with fixed strings",
+			input:          "type s struct {\nname : string<32>\ntags : string<8>[4]\n}\n",
+			expectedString: "struct s {\n  char name[32];\n  char tags[4][8];\n};\n",
+		},
+		{
+			name:        "struct with a string without size",
+			input:       "type s struct {\nname : string\n}\n",
+			expectedErr: compiler.ErrUnsupportedType,
+		},
+		{
+			name:           "struct with a type shadowing a builtin",
+			input:          "type string struct {}\ntype s struct {\nname : string\n}\n",
+			expectedString: "struct string {};\nstruct s {\n  struct string name;\n};\n",
+		},
+		{
+			name:           "struct with inline types",
+			input:          "type s struct {\nmode : enum { A; B; }\np : struct { x : int; }\nv : union { i : int; f : float; }\n}\n",
+			expectedString: "struct s {\n  enum {\n    A,\n    B,\n  } mode;\n  struct {\n    int x;\n  } p;\n  union {\n    int i;\n    float f;\n  } v;\n};\n",
+		},
+		{
+			name:           "union",
+			input:          "type u union {\na : int\nb : float\n}\n",
+			expectedString: "union u {\n  int a;\n  float b;\n};\n",
+		},
+		{
+			name:           "enum",
+			input:          "type color enum {\nBLACK\nWHITE = 0xFFFFFF\n}\n",
+			expectedString: "enum color {\n  BLACK,\n  WHITE = 0xFFFFFF,\n};\n",
+		},
+		{
+			name:           "typedef array",
+			input:          "type vec2 float[2];",
+			expectedString: "typedef float vec2[2];\n",
+		},
+		{
+			name:           "proc",
+			input:          "type vec2 float[2];\nproc add(a : const(vec2), b : vec2) -> void;",
+			expectedString: "typedef float vec2[2];\nvoid add(const vec2 a, vec2 b);\n",
+		},
+		{
+			name:           "size annotation without static asserts",
+			input:          "[[ size = 4 ]]\ntype s struct {\na : int\n}\n",
+			expectedString: "struct s {\n  int a;\n};\n",
+		},
+		{
+			name:           "size annotation with static asserts",
+			input:          "[[ size = 4 ]]\ntype s struct {\na : int\n}\n",
+			config:         compiler.Config{StaticAsserts: true},
+			expectedString: "struct s {\n  int a;\n};\n_Static_assert(sizeof(struct s) == 4, \"struct s must be 4 bytes\");\n",
+		},
+		{
+			name:           "struct with flexible array",
+			input:          "type s struct {\nlen : usize\ndata : u8[]\n}\n",
+			expectedString: "#include <stddef.h>\n#include <stdint.h>\nstruct s {\n  size_t len;\n  uint8_t data[];\n};\n",
+		},
+		{
+			name:           "default values are ignored without the flag",
+			input:          "type s struct {\na : int = 1\n}\n",
+			expectedString: "struct s {\n  int a;\n};\n",
+		},
+		{
+			name:           "fully initialized defaults",
+			input:          "type s struct {\na : int = 1\nb : int = -2\n}\n",
+			config:         compiler.Config{DefaultValues: true},
+			expectedString: "struct s {\n  int a;\n  int b;\n};\nstatic const struct s s_default = {.a = 1, .b = -2};\n",
+		},
+		{
+			name:           "partially initialized defaults",
+			input:          "type s struct {\na : int\nb : int = 0x10\n}\n",
+			config:         compiler.Config{DefaultValues: true},
+			expectedString: "struct s {\n  int a;\n  int b;\n};\nstatic const struct s s_default = {.b = 0x10};\n",
+		},
+		{
+			name:           "byte string defaults",
+			input:          "type s struct {\nmagic : u8[4] = 0x[CAFEbabe]\n}\n",
+			config:         compiler.Config{DefaultValues: true},
+			expectedString: "#include <stdint.h>\nstruct s {\n  uint8_t magic[4];\n};\nstatic const struct s s_default = {.magic = {0xCA, 0xFE, 0xBA, 0xBE}};\n",
+		},
+		{
+			name:        "byte string default longer than the array",
+			input:       "type s struct {\nmagic : u8[2] = 0x[CAFEBA]\n}\n",
+			config:      compiler.Config{DefaultValues: true},
+			expectedErr: analyzer.ErrByteStringLength,
+		},
+		{
+			name:           "struct without defaults",
+			input:          "type s struct {\na : int\n}\n",
+			config:         compiler.Config{DefaultValues: true},
+			expectedString: "struct s {\n  int a;\n};\n",
+		},
+		{
+			name:           "documented type",
+			input:          "[[ doc = \"A point.\\nIn screen space.\" ]]\ntype point struct {\nx : int\n}\n",
+			expectedString: "/**\n * A point.\n * In screen space.\n */\nstruct point {\n  int x;\n};\n",
+		},
+		{
+			name:           "documented fields",
+			input:          "type point struct {\n# horizontal offset\nx : int\n[[ docs = \"vertical offset\" ]]\ny : int\n}\n",
+			expectedString: "struct point {\n  /**\n   * horizontal offset\n   */\n  int x;\n  /**\n   * vertical offset\n   */\n  int y;\n};\n",
+		},
+		{
+			name:           "opaque struct",
+			input:          "[[ opaque ]]\ntype s struct {\na : int\n}\nproc free(self : *s) -> void;\n",
+			expectedString: "struct s;\ntypedef struct s* s_handle;\nvoid free(struct s* self);\n",
+		},
+		{
+			name:           "deprecated struct",
+			input:          "[[ deprecated ]]\ntype s struct {\na : int\n}\n",
+			expectedString: "struct __attribute__((deprecated)) s {\n  int a;\n};\n",
+		},
+		{
+			name:           "deprecated field and proc",
+			input:          "type s struct {\n[[ deprecated = \"use b\" ]]\na : int\nb : int\n}\n@deprecated\nproc f() -> void;\n",
+			expectedString: "struct s {\n  __attribute__((deprecated(\"use b\"))) int a;\n  int b;\n};\n__attribute__((deprecated)) void f();\n",
+		},
+		{
+			name:           "c attributes",
+			input:          "[[ c_attr = \"packed\" ]]\ntype s struct {\n[[ c_attr = \"aligned(8)\", c_attr = \"unused\" ]]\na : int\n}\n",
+			expectedString: "struct __attribute__((packed)) s {\n  __attribute__((aligned(8))) __attribute__((unused)) int a;\n};\n",
+		},
+		{
+			name:           "c attributes on unions enums and procs",
+			input:          "[[ c_attr = \"packed\" ]]\ntype u union {\na : int\n}\n[[ c_attr = \"packed\" ]]\ntype e enum {\nA\n}\n[[ deprecated, c_attr = \"pure\" ]]\nproc f() -> int;\n",
+			expectedString: "union __attribute__((packed)) u {\n  int a;\n};\nenum __attribute__((packed)) e {\n  A,\n};\n__attribute__((deprecated)) __attribute__((pure)) int f();\n",
+		},
+		{
+			name:   "char defaults",
+			input:  "type s struct {\nc : char = 'A'\nq : char = '\\''\nnl : char = '\\n'\nb : char = '\\xC0'\n}\n",
+			config: compiler.Config{DefaultValues: true},
+			expectedString: "struct s {\n  char c;\n  char q;\n  char nl;\n  char b;\n};\n" +
+				"static const struct s s_default = {.c = 'A', .q = '\\'', .nl = '\\n', .b = '\\xc0'};\n",
+		},
+		{
+			name:        "non ASCII char default",
+			input:       "type s struct {\nc : char = 'é'\n}\n",
+			config:      compiler.Config{DefaultValues: true},
+			expectedErr: compiler.ErrUnsupportedExpr,
+		},
+		{
+			name:           "qualified types",
+			input:          "type vec2 struct {\nx : float\n}\ntype s struct {\np : math.vec2\nq : *math.vec2\nr : math.vec2[2]\n}\n",
+			expectedString: "struct vec2 {\n  float x;\n};\nstruct s {\n  struct vec2 p;\n  struct vec2* q;\n  struct vec2 r[2];\n};\n",
+		},
+		{
+			name:           "aligned struct",
+			input:          "[[ align = 16 ]]\ntype s struct {\n[[ align = 8 ]]\na : int\n}\n",
+			expectedString: "struct __attribute__((aligned(16))) s {\n  __attribute__((aligned(8))) int a;\n};\n",
+		},
+		{
+			name:           "aligned struct with radix prefixes",
+			input:          "[[ align = 0x40 ]]\ntype s struct {\n[[ align = 0o10 ]]\na : int\n[[ align = 0b100 ]]\nb : int\n}\n",
+			expectedString: "struct __attribute__((aligned(0x40))) s {\n  __attribute__((aligned(010))) int a;\n  __attribute__((aligned(0b100))) int b;\n};\n",
+		},
+		{
+			name:        "alignment without integer",
+			input:       "[[ align = wide ]]\ntype s struct {\na : int\n}\n",
+			expectedErr: analyzer.ErrInvalidAnnotationValue,
+		},
+		{
+			name:        "c attribute without string",
+			input:       "[[ c_attr = packed ]]\ntype s struct {\na : int\n}\n",
+			expectedErr: analyzer.ErrInvalidAnnotationValue,
+		},
+		{
+			name:        "power operator",
+			input:       "type s struct {\nx : int = 2 ** 4\n}\n",
+			config:      compiler.Config{DefaultValues: true},
+			expectedErr: compiler.ErrUnsupportedExpr,
+		},
+		{
+			name:           "folded array sizes",
+			input:          "const SIZE : u32 = 4 * 8;\ntype s struct {\nx : int[2 ** 4]\ny : u8[SIZE << 1]\n}\n",
+			expectedString: "#include <stdint.h>\nstruct s {\n  int x[16];\n  uint8_t y[64];\n};\nstatic const uint32_t SIZE = 32;\n",
+		},
+		{
+			name:   "literal radix",
+			input:  "const MASK : u8 = 0xFF;\nconst BITS : u8 = 0b101;\ntype s struct {\nx : u8 = 0x1F\ny : u8 = 0b11\n}\n",
+			config: compiler.Config{DefaultValues: true},
+			expectedString: "#include <stdint.h>\nstruct s {\n  uint8_t x;\n  uint8_t y;\n};\n" +
+				"static const struct s s_default = {.x = 0x1F, .y = 0b11};\n" +
+				"static const uint8_t MASK = 0xFF;\nstatic const uint8_t BITS = 0b101;\n",
+		},
+		{
+			name:        "non-constant constant",
+			input:       "const SIZE : int = count;\n",
+			expectedErr: analyzer.ErrNonConstant,
+		},
+		{
+			name:           "pointer to pointer",
+			input:          "type s struct {\nx : **char\n}\n",
+			expectedString: "struct s {\n  char** x;\n};\n",
+		},
+		{
+			name:        "duplicate module",
+			input:       "module a;\nmodule b;",
+			expectedErr: compiler.ErrDuplicateModule,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualString, actualErr := compileString(t, tt.name, tt.input, tt.config)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+			require.Equal(t, tt.expectedString, actualString)
+		})
+	}
+}
+
+func TestCompiler_CompileOrder(t *testing.T) {
+	cases := []struct {
+		name           string
+		input          string
+		expectedString string
+		expectedErr    error
+	}{
+		{
+			name:           "keeps source order",
+			input:          "type a struct {}\ntype b struct {\nx : a\n}\n",
+			expectedString: "struct a {};\nstruct b {\n  struct a x;\n};\n",
+		},
+		{
+			name:           "reorders dependencies",
+			input:          "type b struct {\nx : a[2]\n}\ntype a struct {\ny : c\n}\ntype c int;",
+			expectedString: "typedef int c;\nstruct a {\n  c y;\n};\nstruct b {\n  struct a x[2];\n};\n",
+		},
+		{
+			name:           "types before procs",
+			input:          "proc f(x : *a) -> void;\ntype a struct {}\n",
+			expectedString: "struct a {};\nvoid f(struct a* x);\n",
+		},
+		{
+			name:           "pointer cycle with forward declaration",
+			input:          "type a struct {\nnext : *b\n}\ntype b struct {\nprev : *a\n}\n",
+			expectedString: "struct b;\nstruct a {\n  struct b* next;\n};\nstruct b {\n  struct a* prev;\n};\n",
+		},
+		{
+			name:           "mixed cycle with forward declaration",
+			input:          "type a struct {\nnext : *b\n}\ntype b struct {\nvalue : a\n}\n",
+			expectedString: "struct b;\nstruct a {\n  struct b* next;\n};\nstruct b {\n  struct a value;\n};\n",
+		},
+		{
+			name:           "self reference through pointer",
+			input:          "type node struct {\nnext : *node\n}\n",
+			expectedString: "struct node {\n  struct node* next;\n};\n",
+		},
+		{
+			name:        "value cycle",
+			input:       "type a struct {\nx : b\n}\ntype b struct {\ny : a\n}\n",
+			expectedErr: compiler.ErrCyclicType,
+		},
+		{
+			name:        "value self reference",
+			input:       "type a struct {\nx : a\n}\n",
+			expectedErr: compiler.ErrCyclicType,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualString, actualErr := compileString(t, tt.name, tt.input, compiler.Config{})
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+			require.Equal(t, tt.expectedString, actualString)
+		})
+	}
+}
+
+func TestCompiler_CompileEnumStyles(t *testing.T) {
+	input := "type color enum {\nRED\nGREEN = 2\n}\ntype pixel struct {\nc : color\n}\n"
+	cases := []struct {
+		name           string
+		config         compiler.Config
+		expectedString string
+	}{
+		{
+			name:           "tagged enum",
+			expectedString: "enum color {\n  RED,\n  GREEN = 2,\n};\nstruct pixel {\n  enum color c;\n};\n",
+		},
+		{
+			name:           "typedef enum",
+			config:         compiler.Config{TypedefEnums: true},
+			expectedString: "typedef enum {\n  RED,\n  GREEN = 2,\n} color;\nstruct pixel {\n  color c;\n};\n",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualString, err := compileString(t, tt.name, input, tt.config)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedString, actualString)
+		})
+	}
+}
+
+func TestCompiler_CompileEnumValueComments(t *testing.T) {
+	input := "type color enum {\nRED\nGREEN = 2\nBLUE\nALPHA = 0x10\nOTHER = RED\nLAST\n}\n"
+	expected := "enum color {\n  RED, /* 0 */\n  GREEN = 2, /* 2 */\n  BLUE, /* 3 */\n  ALPHA = 0x10, /* 16 */\n" +
+		"  OTHER = RED,\n  LAST,\n};\n"
+
+	actualString, err := compileString(t, "value comments", input, compiler.Config{EnumValueComments: true})
+	require.NoError(t, err)
+	require.Equal(t, expected, actualString)
+
+	actualString, err = compileString(t, "without comments", input, compiler.Config{})
+	require.NoError(t, err)
+	require.NotContains(t, actualString, "/*")
+}
+
+func TestCompiler_CompileProcParamDocs(t *testing.T) {
+	input := "[[ doc = \"Looks up a value.\", returns = \"whether the key was found\" ]]\n" +
+		"proc lookup([[ doc = \"the key to find\" ]] key : *char, [[ doc = \"receives the value\" ]] value : *int) -> bool;\n"
+	expectedString := `#include <stdbool.h>
+/**
+ * Looks up a value.
+ *
+ * @param key the key to find
+ * @param value receives the value
+ * @return whether the key was found
+ */
+bool lookup(char* key, int* value);
+`
+
+	actualString, err := compileString(t, "proc param docs", input, compiler.Config{})
+	require.NoError(t, err)
+	require.Equal(t, expectedString, actualString)
+
+	// undocumented params are skipped and the description is optional
+	input = "proc clear(map : *int, [[ doc = \"the key to remove\" ]] key : *char) -> void;\n"
+	actualString, err = compileString(t, "partial param docs", input, compiler.Config{})
+	require.NoError(t, err)
+	require.Equal(t, "/**\n * @param key the key to remove\n */\nvoid clear(int* map, char* key);\n", actualString)
+}
+
+func TestCompiler_CompileStringEnums(t *testing.T) {
+	input := "type color enum {\nRED = \"red\"\nGREEN = \"green\"\n}\n"
+	expectedString := `enum color {
+  RED,
+  GREEN,
+};
+static const char* color_strings[] = {
+  "red",
+  "green",
+};
+`
+
+	actualString, err := compileString(t, "string enum", input, compiler.Config{})
+	require.NoError(t, err)
+	require.Equal(t, expectedString, actualString)
+
+	_, err = compileString(t, "mixed enum", "type color enum {\nRED = \"red\"\nGREEN = 1\n}\n", compiler.Config{})
+	require.ErrorIs(t, err, compiler.ErrUnsupportedExpr)
+}
+
+func TestCompiler_CompileEnumTables(t *testing.T) {
+	input := "type color enum {\n[[ rgb = [255, 0, 0], label = \"red\" ]]\nRED\n" +
+		"[[ rgb = [0, 255, 0], label = \"green\", deprecated ]]\nGREEN\n}\n"
+	expectedString := `enum color {
+  RED,
+  GREEN,
+};
+struct color_info {
+  int rgb[3];
+  const char* label;
+};
+static const struct color_info color_table[] = {
+  [RED] = {.rgb = {255, 0, 0}, .label = "red"},
+  [GREEN] = {.rgb = {0, 255, 0}, .label = "green"},
+};
+`
+
+	actualString, err := compileString(t, "enum table", input, compiler.Config{EnumTables: true})
+	require.NoError(t, err)
+	require.Equal(t, expectedString, actualString)
+
+	// members without data still index the table
+	input = "type color enum {\n[[ warm ]]\nRED\nBLUE\n}\n"
+	actualString, err = compileString(t, "member without data", input, compiler.Config{EnumTables: true})
+	require.NoError(t, err)
+	require.Contains(t, actualString, "  [RED] = {.warm = true},\n  [BLUE] = {0},\n")
+	require.Contains(t, actualString, "#include <stdbool.h>")
+
+	actualString, err = compileString(t, "plain enum", "type color enum {\nRED\n}\n", compiler.Config{EnumTables: true})
+	require.NoError(t, err)
+	require.NotContains(t, actualString, "color_table")
+
+	input = "type color enum {\n[[ rgb = [255, 0, 0] ]]\nRED\n[[ rgb = \"green\" ]]\nGREEN\n}\n"
+	_, err = compileString(t, "mismatched data", input, compiler.Config{EnumTables: true})
+	require.ErrorIs(t, err, compiler.ErrUnsupportedExpr)
+}
+
+func TestCompiler_CompileFlagHelpers(t *testing.T) {
+	input := "[[ flags ]]\ntype perms enum {\nREAD = 1\nWRITE = 2\nEXEC = 4\n}\n"
+	expectedString := `#include <stdbool.h>
+#include <stdint.h>
+enum perms {
+  READ = 1,
+  WRITE = 2,
+  EXEC = 4,
+};
+typedef uint8_t perms_flags;
+static inline bool perms_has(perms_flags flags, enum perms flag) {
+  return (flags & flag) != 0;
+}
+static inline perms_flags perms_set(perms_flags flags, enum perms flag) {
+  return flags | flag;
+}
+static inline perms_flags perms_clear(perms_flags flags, enum perms flag) {
+  return flags & (~flag);
+}
+`
+
+	actualString, err := compileString(t, "flag helpers", input, compiler.Config{FlagHelpers: true})
+	require.NoError(t, err)
+	require.Equal(t, expectedString, actualString)
+
+	// typedef enums are referenced without the keyword
+	actualString, err = compileString(t, "typedef flag helpers", input, compiler.Config{FlagHelpers: true, TypedefEnums: true})
+	require.NoError(t, err)
+	require.Contains(t, actualString, "static inline bool perms_has(perms_flags flags, perms flag) {")
+
+	// the storage is widened to fit explicit values
+	wide := "[[ flags ]]\ntype big enum {\nLOW = 1\nHIGH = 0x10000\n}\n"
+	actualString, err = compileString(t, "wide flag helpers", wide, compiler.Config{FlagHelpers: true})
+	require.NoError(t, err)
+	require.Contains(t, actualString, "typedef uint32_t big_flags;")
+
+	actualString, err = compileString(t, "without flag helpers", input, compiler.Config{})
+	require.NoError(t, err)
+	require.NotContains(t, actualString, "perms_flags")
+}
+
+func TestCompiler_CompileAliases(t *testing.T) {
+	cases := []struct {
+		name           string
+		input          string
+		expectedString string
+	}{
+		{
+			name:           "alias of a named type",
+			input:          "type size = u64\n",
+			expectedString: "#include <stdint.h>\ntypedef uint64_t size;\n",
+		},
+		{
+			name:           "alias of an inline struct",
+			input:          "type point = struct {\nx : int\n}\ntype line struct {\na : point\n}\n",
+			expectedString: "typedef struct {\n  int x;\n} point;\nstruct line {\n  point a;\n};\n",
+		},
+		{
+			name:           "struct definition",
+			input:          "type point struct {\nx : int\n}\ntype line struct {\na : point\n}\n",
+			expectedString: "struct point {\n  int x;\n};\nstruct line {\n  struct point a;\n};\n",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualString, err := compileString(t, tt.name, tt.input, compiler.Config{})
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedString, actualString)
+		})
+	}
+}
+
+func TestCompiler_CompileWireSizes(t *testing.T) {
+	cases := []struct {
+		name           string
+		input          string
+		expectedString string
+	}{
+		{
+			name:  "fixed size struct",
+			input: "type point struct {\nx : f32\ny : f32\nid : u16\nflags : u8[3]\n}\n",
+			expectedString: "#include <stdint.h>\nstruct point {\n  float x;\n  float y;\n  uint16_t id;\n  uint8_t flags[3];\n};\n" +
+				"#define point_WIRE_SIZE 13\n",
+		},
+		{
+			name: "nested fixed size types",
+			input: "const N : int = 2;\ntype money decimal<10, 2>;\ntype point struct {\nx : i32\ny : i32\n}\n" +
+				"type shape struct {\npoints : point[N * 2]\nprice : money\nname : string<8>\n}\n",
+			expectedString: "#include <stdint.h>\ntypedef int64_t money;\nstatic const int money_scale = 2;\n" +
+				"struct point {\n  int32_t x;\n  int32_t y;\n};\n#define point_WIRE_SIZE 8\n" +
+				"struct shape {\n  struct point points[4];\n  money price;\n  char name[8];\n};\n#define shape_WIRE_SIZE 48\n" +
+				"static const int N = 2;\n",
+		},
+		{
+			name: "variable length fields",
+			input: "type a struct {\nname : *char\n}\ntype b struct {\nnext : *b\n}\ntype c struct {\nn : int\n}\n" +
+				"type d struct {\nlen : u8\nitems : u8[]\n}\n",
+			expectedString: "#include <stdint.h>\nstruct a {\n  char* name;\n};\nstruct b {\n  struct b* next;\n};\n" +
+				"struct c {\n  int n;\n};\nstruct d {\n  uint8_t len;\n  uint8_t items[];\n};\n",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualString, err := compileString(t, tt.name, tt.input, compiler.Config{WireSizes: true})
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedString, actualString)
+		})
+	}
+}
+
+func TestCompiler_CompileCollections(t *testing.T) {
+	cases := []struct {
+		name           string
+		input          string
+		expectedString string
+		expectedErr    error
+	}{
+		{
+			name:  "set field",
+			input: "type s struct {\ntags : set[u8]\nmore : set[u8]\n}\n",
+			expectedString: "#include <stddef.h>\n#include <stdint.h>\n" +
+				"struct u8_set {\n  size_t len;\n  uint8_t* items;\n};\n" +
+				"struct s {\n  struct u8_set tags;\n  struct u8_set more;\n};\n",
+		},
+		{
+			name:  "map field",
+			input: "type point struct {\nx : int\n}\ntype s struct {\nby_id : map[u32]point\n}\n",
+			expectedString: "#include <stddef.h>\n#include <stdint.h>\n" +
+				"struct point {\n  int x;\n};\n" +
+				"struct u32_point_map {\n  size_t len;\n  uint32_t* keys;\n  struct point* values;\n};\n" +
+				"struct s {\n  struct u32_point_map by_id;\n};\n",
+		},
+		{
+			name:  "nested collections",
+			input: "type index map[int]set[int];\n",
+			expectedString: "#include <stddef.h>\n" +
+				"struct int_set {\n  size_t len;\n  int* items;\n};\n" +
+				"struct int_int_set_map {\n  size_t len;\n  int* keys;\n  struct int_set* values;\n};\n" +
+				"typedef struct int_int_set_map index;\n",
+		},
+		{
+			name:        "set of pointers",
+			input:       "type s struct {\nrefs : set[*int]\n}\n",
+			expectedErr: compiler.ErrUnsupportedType,
+		},
+		{
+			name:        "generic type",
+			input:       "type list<T> struct {\nitems : T[]\n}\n",
+			expectedErr: compiler.ErrUnsupportedDecl,
+		},
+		{
+			name:        "generic proc",
+			input:       "proc max<T> where T : ordered (a: T, b: T) -> T\n",
+			expectedErr: compiler.ErrUnsupportedDecl,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualString, actualErr := compileString(t, tt.name, tt.input, compiler.Config{})
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+			require.Equal(t, tt.expectedString, actualString)
+		})
+	}
+}
+
+func TestCompiler_CompileSizedInts(t *testing.T) {
+	cases := []struct {
+		name           string
+		input          string
+		config         compiler.Config
+		expectedString string
+		expectedErr    error
+	}{
+		{
+			name:           "bit int typedef",
+			input:          "type u24 int<24>;\n",
+			expectedString: "typedef _BitInt(24) u24;\n",
+		},
+		{
+			name:           "bit int fields",
+			input:          "type pixel struct {\nrgb : uint<24>\nalpha : uint<8>[2]\n}\n",
+			expectedString: "struct pixel {\n  unsigned _BitInt(24) rgb;\n  unsigned _BitInt(8) alpha[2];\n};\n",
+		},
+		{
+			name:           "fixed-width fallback",
+			input:          "type pixel struct {\nrgb : uint<24>\ndelta : int<12>\n}\n",
+			config:         compiler.Config{FixedWidthInts: true},
+			expectedString: "#include <stdint.h>\nstruct pixel {\n  uint32_t rgb;\n  int16_t delta;\n};\n",
+		},
+		{
+			name:        "fixed-width fallback too wide",
+			input:       "type big int<65>;\n",
+			config:      compiler.Config{FixedWidthInts: true},
+			expectedErr: compiler.ErrUnsupportedType,
+		},
+		{
+			name:        "signed bit int without value bits",
+			input:       "type bit int<1>;\n",
+			expectedErr: compiler.ErrUnsupportedType,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualString, actualErr := compileString(t, tt.name, tt.input, tt.config)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+			require.Equal(t, tt.expectedString, actualString)
+		})
+	}
+}
+
+func TestCompiler_CompileOptionalAccessors(t *testing.T) {
+	input := "[[ accessors ]]\ntype user struct {\nage : ?u32\nid : u64\n}\n"
+	expectedString := `#include <assert.h>
+#include <stdbool.h>
+#include <stddef.h>
+#include <stdint.h>
+struct user {
+  uint32_t* age;
+  uint64_t id;
+};
+static inline bool user_has_age(const struct user* self) {
+  return self->age != NULL;
+}
+static inline uint32_t user_get_age(const struct user* self) {
+  assert(self->age != NULL);
+  return *self->age;
+}
+static inline void user_set_age(struct user* self, uint32_t* value) {
+  self->age = value;
+}
+static inline uint64_t user_get_id(const struct user* self) {
+  return self->id;
+}
+static inline void user_set_id(struct user* self, uint64_t value) {
+  self->id = value;
+}
+`
+
+	actualString, err := compileString(t, "optional pointer accessors", input, compiler.Config{OptionalPointers: true})
+	require.NoError(t, err)
+	require.Equal(t, expectedString, actualString)
+
+	// the struct form is present through its flag, the setter marks it
+	actualString, err = compileString(t, "optional struct accessors", input, compiler.Config{})
+	require.NoError(t, err)
+	require.Contains(t, actualString, "static inline bool user_has_age(const struct user* self) {\n  return self->age.has;\n}\n")
+	require.Contains(t, actualString, "  assert(self->age.has);\n  return self->age.value;\n")
+	require.Contains(t, actualString, "  self->age.has = true;\n  self->age.value = value;\n")
+}
+
+func TestCompiler_CompileEmbeds(t *testing.T) {
+	// the included struct is declared after, it is still emitted first
+	input := "type pixel struct {\ninclude point\ncolor : u32\n}\ntype point struct {\nx : float\ny : float\n}\n"
+	expectedString := `#include <stdint.h>
+struct point {
+  float x;
+  float y;
+};
+struct pixel {
+  struct {
+    float x;
+    float y;
+  };
+  uint32_t color;
+};
+`
+
+	actualString, err := compileString(t, "includes", input, compiler.Config{})
+	require.NoError(t, err)
+	require.Equal(t, expectedString, actualString)
+
+	input = "type point struct {\nx : float\n}\ntype pixel struct {\ninclude point\nx : u32\n}\n"
+	_, err = compileString(t, "colliding include", input, compiler.Config{})
+	require.ErrorIs(t, err, analyzer.ErrFieldCollision)
+}
+
+func TestCompiler_CompileOptionals(t *testing.T) {
+	input := "type user struct {\nage : ?u8\nfriend : ?*user\n}\n"
+	expectedString := "#include <stdbool.h>\n#include <stdint.h>\n" +
+		"struct user {\n  struct {\n    bool has;\n    uint8_t value;\n  } age;\n" +
+		"  struct {\n    bool has;\n    struct user* value;\n  } friend;\n};\n"
+
+	actualString, err := compileString(t, "optional structs", input, compiler.Config{})
+	require.NoError(t, err)
+	require.Equal(t, expectedString, actualString)
+
+	actualString, err = compileString(t, "optional pointers", input, compiler.Config{OptionalPointers: true})
+	require.NoError(t, err)
+	require.Equal(t, "#include <stdint.h>\nstruct user {\n  uint8_t* age;\n  struct user* friend;\n};\n", actualString)
+}
+
+func TestCompiler_CompileOptionalDefaults(t *testing.T) {
+	input := "type s struct {\na : ?int = 3\nb : int = 1\n}\n"
+	actualString, err := compileString(t, "optional default", input, compiler.Config{DefaultValues: true})
+	require.NoError(t, err)
+	require.Contains(t, actualString, "static const struct s s_default = {.a = {.has = true, .value = 3}, .b = 1};\n")
+
+	_, err = compileString(t, "optional pointer default", input, compiler.Config{DefaultValues: true, OptionalPointers: true})
+	require.ErrorIs(t, err, compiler.ErrUnsupportedExpr)
+}
+
+func TestCompiler_CompilePrototypeOptionals(t *testing.T) {
+	cases := []string{
+		"proc find(key : ?int) -> int;\n",
+		"proc find(key : int) -> ?int;\n",
+		"type find_fn proc(key : ?int) -> int;\n",
+		"type s struct {\nfind : *proc(key : ?int) -> int\n}\n",
+	}
+	for _, input := range cases {
+		_, err := compileString(t, input, input, compiler.Config{})
+		require.ErrorIs(t, err, compiler.ErrUnsupportedType, input)
+	}
+
+	// optional pointers can be passed around
+	actualString, err := compileString(t, "optional pointer params", "proc find(key : ?int) -> ?int;\n",
+		compiler.Config{OptionalPointers: true})
+	require.NoError(t, err)
+	require.Equal(t, "int* find(int* key);\n", actualString)
+}
+
+func TestCompiler_CompileDecimals(t *testing.T) {
+	cases := []struct {
+		name           string
+		input          string
+		expectedString string
+		expectedErr    error
+	}{
+		{
+			name:           "decimal typedef",
+			input:          "type money decimal<10, 2>;\n",
+			expectedString: "#include <stdint.h>\ntypedef int64_t money;\nstatic const int money_scale = 2;\n",
+		},
+		{
+			name:  "decimal fields",
+			input: "type item struct {\nprice : decimal<10,2>\nrate : decimal<5, 4>[3]\ncount : int\n}\n",
+			expectedString: "#include <stdint.h>\nstruct item {\n  int64_t price;\n  int32_t rate[3];\n  int count;\n};\n" +
+				"static const int item_price_scale = 2;\nstatic const int item_rate_scale = 4;\n",
+		},
+		{
+			name:  "decimal fields of inline structs",
+			input: "type item struct {\nprice : decimal<10, 2>\nextra : struct {\nfee : decimal<5, 1>\n}\n}\n",
+			expectedString: "#include <stdint.h>\nstruct item {\n  int64_t price;\n  struct {\n    int32_t fee;\n  } extra;\n};\n" +
+				"static const int item_price_scale = 2;\nstatic const int item_extra_fee_scale = 1;\n",
+		},
+		{
+			name:           "decimal union members",
+			input:          "type amount union {\ncash : decimal<10, 2>\npoints : int\n}\n",
+			expectedString: "#include <stdint.h>\nunion amount {\n  int64_t cash;\n  int points;\n};\nstatic const int amount_cash_scale = 2;\n",
+		},
+		{
+			name:           "decimal proc params",
+			input:          "proc pay(total : decimal<10, 2>, int) -> void;\n",
+			expectedString: "#include <stdint.h>\nvoid pay(int64_t total, int);\nstatic const int pay_total_scale = 2;\n",
+		},
+		{
+			name:        "decimal too wide",
+			input:       "type big decimal<20, 2>;\n",
+			expectedErr: compiler.ErrUnsupportedType,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualString, actualErr := compileString(t, tt.name, tt.input, compiler.Config{})
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+			require.Equal(t, tt.expectedString, actualString)
+		})
+	}
+}
+
+func TestCompiler_CompileUnionVisitors(t *testing.T) {
+	input := "type shape union {\ncircle : float\nbox : float[2]\n}\n"
+	expectedString := `union shape {
+  float circle;
+  float box[2];
+};
+enum shape_tag {
+  shape_tag_circle,
+  shape_tag_box,
+};
+struct shape_visitor {
+  void* ctx;
+  void (*circle)(const float* value, void* ctx);
+  void (*box)(const float* value, void* ctx);
+};
+static inline void shape_visit(const union shape* self, enum shape_tag tag, const struct shape_visitor* visitor) {
+  switch (tag) {
+  case shape_tag_circle:
+    visitor->circle(&self->circle, visitor->ctx);
+    break;
+  case shape_tag_box:
+    visitor->box(self->box, visitor->ctx);
+    break;
+  }
+}
+`
+
+	actualString, err := compileString(t, "union visitors", input, compiler.Config{UnionVisitors: true})
+	require.NoError(t, err)
+	require.Equal(t, expectedString, actualString)
+}
+
+func TestCompiler_CompileExhaustiveSwitches(t *testing.T) {
+	input := "type shape union {\ncircle : float\nbox : float[2]\n}\n"
+	config := compiler.Config{UnionVisitors: true, ExhaustiveSwitches: true}
+	actualString, err := compileString(t, "exhaustive switches", input, config)
+	require.NoError(t, err)
+	require.Contains(t, actualString, "enum shape_tag {\n  shape_tag_circle,\n  shape_tag_box,\n  shape_tag_COUNT,\n};\n")
+	require.Contains(t, actualString, "  case shape_tag_COUNT:\n    break;\n  }\n")
+	require.True(t, strings.HasSuffix(actualString,
+		"}\n_Static_assert(shape_tag_COUNT == 2, \"shape_visit does not handle every member of enum shape_tag\");\n"))
+
+	// tagged unions get the count member too, there is no switch to guard
+	actualString, err = compileString(t, "exhaustive tagged union", "[[ tagged ]]\n"+input, compiler.Config{ExhaustiveSwitches: true})
+	require.NoError(t, err)
+	require.Contains(t, actualString, "  shape_tag_COUNT,\n")
+	require.NotContains(t, actualString, "_Static_assert")
+
+	actualString, err = compileString(t, "without guards", input, compiler.Config{UnionVisitors: true})
+	require.NoError(t, err)
+	require.NotContains(t, actualString, "COUNT")
+	require.NotContains(t, actualString, "_Static_assert")
+}
+
+func TestCompiler_CompileTaggedUnion(t *testing.T) {
+	input := "[[ tagged ]]\ntype shape union {\ncircle : float\nbox : float[2]\n}\n"
+	expectedString := `union shape {
+  float circle;
+  float box[2];
+};
+enum shape_tag {
+  shape_tag_circle,
+  shape_tag_box,
+};
+struct shape_tagged {
+  enum shape_tag tag;
+  union shape value;
+};
+`
+
+	actualString, err := compileString(t, "tagged union", input, compiler.Config{})
+	require.NoError(t, err)
+	require.Equal(t, expectedString, actualString)
+
+	// the tag enum is shared with the visitors
+	actualString, err = compileString(t, "tagged union with visitors", input, compiler.Config{UnionVisitors: true})
+	require.NoError(t, err)
+	require.Equal(t, 1, strings.Count(actualString, "enum shape_tag {"))
+	require.Contains(t, actualString, "struct shape_tagged {")
+	require.Contains(t, actualString, "struct shape_visitor {")
+}
+
+func TestCompiler_CompileTaggedUnionAccessors(t *testing.T) {
+	input := "[[ tagged, accessors ]]\ntype shape union {\ncircle : float\nbox : float[2]\n}\n"
+	expectedString := `#include <assert.h>
+union shape {
+  float circle;
+  float box[2];
+};
+enum shape_tag {
+  shape_tag_circle,
+  shape_tag_box,
+};
+struct shape_tagged {
+  enum shape_tag tag;
+  union shape value;
+};
+static inline float shape_get_circle(const struct shape_tagged* self) {
+  assert(self->tag == shape_tag_circle);
+  return self->value.circle;
+}
+static inline void shape_set_circle(struct shape_tagged* self, float value) {
+  self->tag = shape_tag_circle;
+  self->value.circle = value;
+}
+static inline const float* shape_get_box(const struct shape_tagged* self) {
+  assert(self->tag == shape_tag_box);
+  return self->value.box;
+}
+`
+
+	actualString, err := compileString(t, "union accessors", input, compiler.Config{UnionAccessAsserts: true})
+	require.NoError(t, err)
+	require.Equal(t, expectedString, actualString)
+
+	actualString, err = compileString(t, "union accessors without asserts", input, compiler.Config{})
+	require.NoError(t, err)
+	require.Contains(t, actualString, "shape_get_circle")
+	require.NotContains(t, actualString, "assert")
+
+	// the values of a declared tag are unknown, so it is neither asserted nor set
+	input = "type kind enum {\nCIRCLE\n}\n[[ accessors ]]\ntype shape union {\ntag k : kind\ncircle : float\n}\n"
+	actualString, err = compileString(t, "declared tag accessors", input, compiler.Config{UnionAccessAsserts: true})
+	require.NoError(t, err)
+	require.Contains(t, actualString, "static inline float shape_get_circle(const struct shape_tagged* self) {\n  return self->value.circle;\n}\n")
+	require.NotContains(t, actualString, "shape_set_circle")
+	require.NotContains(t, actualString, "assert")
+}
+
+func TestCompiler_CompileUnionTagField(t *testing.T) {
+	input := "type kind enum {\nCIRCLE\nBOX\n}\ntype shape union {\ntag k : kind\ncircle : float\nbox : float[2]\n}\n"
+	expectedString := `enum kind {
+  CIRCLE,
+  BOX,
+};
+union shape {
+  float circle;
+  float box[2];
+};
+struct shape_tagged {
+  enum kind k;
+  union shape value;
+};
+`
+
+	actualString, err := compileString(t, "union tag field", input, compiler.Config{})
+	require.NoError(t, err)
+	require.Equal(t, expectedString, actualString)
+}
+
+func TestCompiler_CompileReflectionTables(t *testing.T) {
+	input := "type point struct {\nx : float\nbox : u8[2]\n}\ntype line struct {\na : point\nb : *point\nn : usize\n}\n"
+	expectedString := `#include <stddef.h>
+#include <stdint.h>
+enum field_kind {
+  field_kind_int,
+  field_kind_uint,
+  field_kind_float,
+  field_kind_bool,
+  field_kind_char,
+  field_kind_enum,
+  field_kind_struct,
+  field_kind_union,
+  field_kind_pointer,
+  field_kind_array,
+  field_kind_other,
+};
+struct field_info {
+  const char* name;
+  size_t offset;
+  enum field_kind kind;
+  const char* type;
+};
+struct point {
+  float x;
+  uint8_t box[2];
+};
+static const struct field_info point_fields[] = {
+  {.name = "x", .offset = offsetof(struct point, x), .kind = field_kind_float, .type = "float"},
+  {.name = "box", .offset = offsetof(struct point, box), .kind = field_kind_array, .type = "uint8_t[2]"},
+};
+static inline const struct field_info* point_field_infos(size_t* count) {
+  (*count) = 2;
+  return point_fields;
+}
+struct line {
+  struct point a;
+  struct point* b;
+  size_t n;
+};
+static const struct field_info line_fields[] = {
+  {.name = "a", .offset = offsetof(struct line, a), .kind = field_kind_struct, .type = "struct point"},
+  {.name = "b", .offset = offsetof(struct line, b), .kind = field_kind_pointer, .type = "struct point*"},
+  {.name = "n", .offset = offsetof(struct line, n), .kind = field_kind_uint, .type = "size_t"},
+};
+static inline const struct field_info* line_field_infos(size_t* count) {
+  (*count) = 3;
+  return line_fields;
+}
+`
+
+	actualString, err := compileString(t, "reflection tables", input, compiler.Config{ReflectionTables: true})
+	require.NoError(t, err)
+	require.Equal(t, expectedString, actualString)
+
+	actualString, err = compileString(t, "empty struct", "type empty struct {\n}\n", compiler.Config{ReflectionTables: true})
+	require.NoError(t, err)
+	require.NotContains(t, actualString, "field_info")
+}
+
+func TestCompiler_CompileRangeValidators(t *testing.T) {
+	input := "type s struct {\nscore : int in 0..100\nname : string<8>\nlevels : u8[4] in 1..0x0F\n}\n"
+	expectedString := "#include <stdbool.h>\n#include <stdint.h>\n" +
+		"struct s {\n  int score;\n  char name[8];\n  uint8_t levels[4];\n};\n" +
+		"static inline bool s_score_valid(int value) {\n  return (value >= 0) && (value <= 100);\n}\n" +
+		"static inline bool s_levels_valid(uint8_t value) {\n  return (value >= 1) && (value <= 0x0F);\n}\n"
+
+	actualString, err := compileString(t, "range validators", input, compiler.Config{RangeValidators: true})
+	require.NoError(t, err)
+	require.Equal(t, expectedString, actualString)
+
+	// validators are opt-in
+	actualString, err = compileString(t, "range validators disabled", input, compiler.Config{})
+	require.NoError(t, err)
+	require.NotContains(t, actualString, "_valid")
+}
+
+func TestCompiler_CompileRangeBounds(t *testing.T) {
+	input := "const LIMIT : int = 50;\ntype s struct {\nscore : int in 0..100\nlevel : i8 in -LIMIT..LIMIT * 2\n}\n"
+	expectedString := "#include <stdint.h>\n" +
+		"struct s {\n  int score;\n  int8_t level;\n};\n" +
+		"#define S_SCORE_MIN 0\n#define S_SCORE_MAX 100\n" +
+		"#define S_LEVEL_MIN (-50)\n#define S_LEVEL_MAX 100\n"
+
+	actualString, err := compileString(t, "range bounds", input, compiler.Config{RangeBounds: true})
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(actualString, expectedString), actualString)
+
+	// the bounds are opt-in
+	actualString, err = compileString(t, "range bounds disabled", input, compiler.Config{})
+	require.NoError(t, err)
+	require.NotContains(t, actualString, "_MIN")
+}
+
+func TestCompiler_CompileAccessors(t *testing.T) {
+	input := "[[ accessors ]]\ntype s struct {\na : int\n[[ readonly ]]\nid : u32\ndata : u8[4]\n}\n"
+	expectedString := "#include <stdint.h>\n" +
+		"struct s {\n  int a;\n  uint32_t id;\n  uint8_t data[4];\n};\n" +
+		"static inline int s_get_a(const struct s* self) {\n  return self->a;\n}\n" +
+		"static inline void s_set_a(struct s* self, int value) {\n  self->a = value;\n}\n" +
+		"static inline uint32_t s_get_id(const struct s* self) {\n  return self->id;\n}\n" +
+		"static inline const uint8_t* s_get_data(const struct s* self) {\n  return self->data;\n}\n"
+
+	actualString, err := compileString(t, "accessors", input, compiler.Config{})
+	require.NoError(t, err)
+	require.Equal(t, expectedString, actualString)
+}
+
+func TestCompiler_CompilePackedAccessors(t *testing.T) {
+	input := "[[ accessors, c_attr = \"packed\" ]]\ntype header struct {\nkind : u8\n[[ readonly ]]\nlength : u32\n}\n"
+	expectedString := "#include <stdint.h>\n#include <string.h>\n" +
+		"struct __attribute__((packed)) header {\n  uint8_t kind;\n  uint32_t length;\n};\n" +
+		"static inline uint8_t header_get_kind(const struct header* self) {\n" +
+		"  uint8_t value;\n  memcpy(&value, &self->kind, sizeof(value));\n  return value;\n}\n" +
+		"static inline void header_set_kind(struct header* self, uint8_t value) {\n" +
+		"  memcpy(&self->kind, &value, sizeof(value));\n}\n" +
+		"static inline uint32_t header_get_length(const struct header* self) {\n" +
+		"  uint32_t value;\n  memcpy(&value, &self->length, sizeof(value));\n  return value;\n}\n"
+
+	actualString, err := compileString(t, "packed accessors", input, compiler.Config{})
+	require.NoError(t, err)
+	require.Equal(t, expectedString, actualString)
+}
+
+func TestCompiler_CompileAccessorTypes(t *testing.T) {
+	cases := []struct {
+		name           string
+		input          string
+		expectedGetter string
+		expectedSetter string
+	}{
+		{
+			name:           "scalar by value",
+			input:          "[[ accessors ]]\ntype s struct {\nv : int\n}\n",
+			expectedGetter: "static inline int s_get_v(const struct s* self)",
+			expectedSetter: "static inline void s_set_v(struct s* self, int value)",
+		},
+		{
+			name:           "pointer to const",
+			input:          "[[ accessors ]]\ntype s struct {\nv : *int\n}\n",
+			expectedGetter: "static inline const int* s_get_v(const struct s* self)",
+			expectedSetter: "static inline void s_set_v(struct s* self, int* value)",
+		},
+		{
+			name:           "pointer to pointer is left as is",
+			input:          "[[ accessors ]]\ntype s struct {\nv : **int\n}\n",
+			expectedGetter: "static inline int** s_get_v(const struct s* self)",
+			expectedSetter: "static inline void s_set_v(struct s* self, int** value)",
+		},
+		{
+			name:           "fixed string",
+			input:          "[[ accessors ]]\ntype s struct {\nv : string<16>\n}\n",
+			expectedGetter: "static inline const char* s_get_v(const struct s* self)",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualString, err := compileString(t, tt.name, tt.input, compiler.Config{})
+			require.NoError(t, err)
+			require.Contains(t, actualString, tt.expectedGetter)
+			if tt.expectedSetter != "" {
+				require.Contains(t, actualString, tt.expectedSetter)
+			} else {
+				require.NotContains(t, actualString, "s_set_v")
+			}
+		})
+	}
+}
+
+func TestCompiler_CompileAccessorMacros(t *testing.T) {
+	input := "[[ accessors ]]\ntype s struct {\na : int\n[[ readonly ]]\nid : u32\n}\n"
+	expectedString := "#include <stdint.h>\n" +
+		"struct s {\n  int a;\n  uint32_t id;\n};\n" +
+		"#define s_a(x) ((x)->a)\n" +
+		"#define s_id(x) ((x)->id)\n"
+
+	actualString, err := compileString(t, "accessor macros", input, compiler.Config{AccessorMacros: true})
+	require.NoError(t, err)
+	require.Equal(t, expectedString, actualString)
+
+	// only annotated structs get accessors
+	actualString, err = compileString(t, "no accessors", "type s struct {\na : int\n}\n", compiler.Config{AccessorMacros: true})
+	require.NoError(t, err)
+	require.NotContains(t, actualString, "#define")
+}
+
+func TestCompiler_PackageDoesNotNamespace(t *testing.T) {
+	// the guard and the prefix come from the module alone
+	input := "package web.api;\nmodule net.http;\ntype s struct {}\n"
+	expectedString := "#ifndef NET_HTTP_H\n#define NET_HTTP_H\nstruct net_http_s {};\n#endif /* NET_HTTP_H */\n\n"
+
+	actualString, err := compileString(t, "package", input, compiler.Config{ModulePrefix: true})
+	require.NoError(t, err)
+	require.Equal(t, expectedString, actualString)
+}
+
+func TestCompiler_VersionSuffixes(t *testing.T) {
+	input := "type Header struct {\nlen : u32\n}\n[[ version = 2 ]]\ntype Request struct {\nheader : Header\n}\n" +
+		"[[ version = 3 ]]\nproc send(r : *Request) -> int;\nproc close(r : *Request) -> void;\n"
+	expectedString := "#include <stdint.h>\n" +
+		"struct Header {\n  uint32_t len;\n};\n" +
+		"struct Request_v2 {\n  struct Header header;\n};\n" +
+		"int send_v3(struct Request_v2* r);\n" +
+		"void close(struct Request_v2* r);\n"
+
+	actualString, err := compileString(t, "version suffixes", input, compiler.Config{VersionSuffixes: true})
+	require.NoError(t, err)
+	require.Equal(t, expectedString, actualString)
+
+	// the suffixes are opt-in
+	actualString, err = compileString(t, "version suffixes disabled", input, compiler.Config{})
+	require.NoError(t, err)
+	require.NotContains(t, actualString, "_v")
+
+	_, err = compileString(t, "invalid version", "[[ version = \"2\" ]]\ntype s struct {}\n", compiler.Config{VersionSuffixes: true})
+	require.ErrorIs(t, err, analyzer.ErrInvalidAnnotationValue)
+}
+
+func TestCompiler_ModulePrefix(t *testing.T) {
+	input := "module net.http;\ntype Request struct {\nheaders : *Header\n}\ntype Header struct {}\ntype Status u16;\nproc send(r : *Request) -> Status;\n"
+	cases := []struct {
+		name           string
+		config         compiler.Config
+		expectedString string
+	}{
+		{
+			name:   "default style",
+			config: compiler.Config{ModulePrefix: true},
+			expectedString: "#ifndef NET_HTTP_H\n#define NET_HTTP_H\n#include <stdint.h>\n" +
+				"struct net_http_Header;\n" +
+				"struct net_http_Request {\n  struct net_http_Header* headers;\n};\n" +
+				"struct net_http_Header {};\n" +
+				"typedef uint16_t net_http_Status;\n" +
+				"net_http_Status net_http_send(struct net_http_Request* r);\n" +
+				"#endif /* NET_HTTP_H */\n\n",
+		},
+		{
+			name:   "custom separator and casing",
+			config: compiler.Config{ModulePrefix: true, PrefixSeparator: "__", PrefixCase: naming.ToPascal},
+			expectedString: "#ifndef NET_HTTP_H\n#define NET_HTTP_H\n#include <stdint.h>\n" +
+				"struct Net__Http__Header;\n" +
+				"struct Net__Http__Request {\n  struct Net__Http__Header* headers;\n};\n" +
+				"struct Net__Http__Header {};\n" +
+				"typedef uint16_t Net__Http__Status;\n" +
+				"Net__Http__Status Net__Http__send(struct Net__Http__Request* r);\n" +
+				"#endif /* NET_HTTP_H */\n\n",
+		},
+		{
+			name:   "disabled",
+			config: compiler.Config{},
+			expectedString: "#ifndef NET_HTTP_H\n#define NET_HTTP_H\n#include <stdint.h>\n" +
+				"struct Header;\n" +
+				"struct Request {\n  struct Header* headers;\n};\n" +
+				"struct Header {};\n" +
+				"typedef uint16_t Status;\n" +
+				"Status send(struct Request* r);\n" +
+				"#endif /* NET_HTTP_H */\n\n",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualString, err := compileString(t, tt.name, input, tt.config)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedString, actualString)
+		})
+	}
+}
+
+func TestCompiler_CompileSplit(t *testing.T) {
+	input := "module shapes;\n[[ accessors ]]\ntype box struct {\nw : int\n}\ntype shape union {\ncircle : float\n}\nproc area(s : *shape) -> float;\n"
+	schema, err := parser.NewFromString("split", input).Parse()
+	require.NoError(t, err)
+
+	header, source, err := compiler.New(compiler.Config{UnionVisitors: true}).CompileSplit(schema)
+	require.NoError(t, err)
+
+	headerString := header.Generate(0)
+	require.True(t, strings.HasPrefix(headerString, "#ifndef SHAPES_H\n"))
+	require.Contains(t, headerString, "float area(union shape* s);")
+	require.Contains(t, headerString, "\nvoid shape_visit(const union shape* self, enum shape_tag tag, const struct shape_visitor* visitor);\n")
+	require.NotContains(t, headerString, "switch")
+	require.Contains(t, headerString, "static inline int box_get_w(const struct box* self) {")
+
+	sourceString := source.Generate(0)
+	require.True(t, strings.HasPrefix(sourceString, "#include \"shapes.h\"\n"))
+	require.Contains(t, sourceString, "\nvoid shape_visit(const union shape* self, enum shape_tag tag, const struct shape_visitor* visitor) {\n  switch (tag) {\n")
+	require.NotContains(t, sourceString, "static")
+	require.NotContains(t, sourceString, "area")
+	require.NotContains(t, sourceString, "box_get_w")
+
+	// the single header output keeps the visit function static inline
+	file, err := compiler.New(compiler.Config{UnionVisitors: true}).Compile(schema)
+	require.NoError(t, err)
+	require.Contains(t, file.Generate(0), "static inline void shape_visit(")
+}
+
+func TestCompiler_CompileSplitWithoutModule(t *testing.T) {
+	schema, err := parser.NewFromString("split without module", "type s struct {\na : int\n}\n").Parse()
+	require.NoError(t, err)
+
+	header, source, err := compiler.CompileSplit(schema)
+	require.NoError(t, err)
+	require.Equal(t, "#ifndef SCHEMA_H\n#define SCHEMA_H\nstruct s {\n  int a;\n};\n#endif /* SCHEMA_H */\n\n", header.Generate(0))
+	require.Equal(t, "#include \"schema.h\"\n", source.Generate(0))
+
+	header, source, err = compiler.New(compiler.Config{HeaderName: "types.h"}).CompileSplit(schema)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(header.Generate(0), "#ifndef TYPES_H\n"))
+	require.Equal(t, "#include \"types.h\"\n", source.Generate(0))
+}
+
+func TestCompiler_CompileOptions(t *testing.T) {
+	cases := []struct {
+		name           string
+		input          string
+		expectedString string
+		expectedErr    error
+	}{
+		{
+			name:           "namespace and version",
+			input:          "options { namespace = \"foo\"; version = 2; }\ntype s struct {\na : int\n}\n",
+			expectedString: "static const int foo_schema_version = 2;\nstruct foo_s {\n  int a;\n};\n",
+		},
+		{
+			name:  "namespace takes precedence over the module",
+			input: "module net.http;\ntype s int;\noptions { namespace = \"web\"; }\n",
+			expectedString: "#ifndef NET_HTTP_H\n#define NET_HTTP_H\ntypedef int web_s;\n" +
+				"#endif /* NET_HTTP_H */\n\n",
+		},
+		{
+			name:           "version from a constant",
+			input:          "const V : int = 3;\noptions { version = V + 1; }\n",
+			expectedString: "static const int schema_version = 4;\nstatic const int V = 3;\n",
+		},
+		{
+			name:        "unknown option",
+			input:       "options { colour = 1; }\n",
+			expectedErr: compiler.ErrInvalidOption,
+		},
+		{
+			name:        "namespace is not a string",
+			input:       "options { namespace = 1; }\n",
+			expectedErr: compiler.ErrInvalidOption,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualString, actualErr := compileString(t, tt.name, tt.input, compiler.Config{})
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+			require.Equal(t, tt.expectedString, actualString)
+		})
+	}
+}
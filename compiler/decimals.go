@@ -0,0 +1,110 @@
+package compiler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/cedmundo/SimpleSchema/analyzer"
+	"github.com/cedmundo/SimpleSchema/generator"
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+// lowerDecimal picks the smallest integer holding every digit of a decimal, values are stored scaled (12.34 is
+// 1234 in a decimal<10, 2>)
+func (c *Compiler) lowerDecimal(decimal *parser.DecimalType) (generator.Expr, error) {
+	precision, err := analyzer.EvalConst(decimal.Precision, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case precision <= 9:
+		return c.lowerTypeName("i32"), nil
+	case precision <= 18:
+		return c.lowerTypeName("i64"), nil
+	}
+
+	return nil, fmt.Errorf("%s: %w: decimal precision %d does not fit in 64 bits", parser.ExprLoc(decimal.Precision),
+		ErrUnsupportedType, precision)
+}
+
+// decimalScale makes the constant holding the scale of a decimal type, or an array of them, named after its
+// typedef or field (static const int X_scale = 2;), returns nil for any other type
+func decimalScale(name string, typ parser.Expr) (generator.Decl, error) {
+	for {
+		index, ok := typ.(*parser.Index)
+		if !ok {
+			break
+		}
+		typ = index.Base
+	}
+
+	decimal, ok := typ.(*parser.DecimalType)
+	if !ok {
+		return nil, nil
+	}
+
+	scale, err := analyzer.EvalConst(decimal.Scale, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &generator.GlobalVar{
+		Storage: "static",
+		Type:    &generator.Const{Type: &generator.Ident{Name: "int"}},
+		Name:    &generator.Ident{Name: name + "_scale"},
+		Value:   &generator.Literal{Value: strconv.FormatInt(scale, 10)},
+	}, nil
+}
+
+// compileDecimalScales makes the scale constants of the decimal fields of a struct, union or parameter list
+// (static const int X_a_scale), the fields of inline structs and unions are named after their path (X_a_b_scale).
+// Unnamed parameters have no scale.
+func compileDecimalScales(name string, block parser.Block) ([]generator.Decl, error) {
+	decls := make([]generator.Decl, 0)
+	for _, decl := range block.Decls {
+		field, ok := unwrapDecl(decl).(*parser.Field)
+		if !ok {
+			continue
+		}
+
+		if field.Name == nil {
+			continue
+		}
+
+		fieldName := name + "_" + parser.LookupName(field.Name)
+		typ := field.Type
+		for {
+			index, ok := typ.(*parser.Index)
+			if !ok {
+				break
+			}
+			typ = index.Base
+		}
+
+		var inline parser.Block
+		switch def := typ.(type) {
+		case *parser.StructDef:
+			inline = def.Block
+		case *parser.UnionDef:
+			inline = def.Block
+		}
+
+		nested, err := compileDecimalScales(fieldName, inline)
+		if err != nil {
+			return nil, err
+		}
+		decls = append(decls, nested...)
+
+		scale, err := decimalScale(fieldName, field.Type)
+		if err != nil {
+			return nil, err
+		}
+
+		if scale != nil {
+			decls = append(decls, scale)
+		}
+	}
+
+	return decls, nil
+}
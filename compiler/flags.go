@@ -0,0 +1,64 @@
+package compiler
+
+import (
+	"math/bits"
+
+	"github.com/cedmundo/SimpleSchema/analyzer"
+	"github.com/cedmundo/SimpleSchema/generator"
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+// compileFlagHelpers makes the storage typedef of a [[ flags ]] enum (X_flags) and the X_has, X_set and X_clear
+// helpers testing, adding and removing a member from a mask. The storage is the smallest unsigned integer with a
+// bit per member, widened when an explicit value does not fit in it.
+func (c *Compiler) compileFlagHelpers(name string, def *parser.EnumDef, enumType generator.Expr) []generator.Decl {
+	width := len(def.Block.Decls)
+	for _, value := range analyzer.EnumValues(def) {
+		if value.Known && value.Value > 0 {
+			width = max(width, bits.Len64(uint64(value.Value)))
+		}
+	}
+
+	storage := "uint64_t"
+	switch {
+	case width <= 8:
+		storage = "uint8_t"
+	case width <= 16:
+		storage = "uint16_t"
+	case width <= 32:
+		storage = "uint32_t"
+	}
+
+	c.includes["stdint.h"] = true
+	c.includes["stdbool.h"] = true
+	flagsType := &generator.Ident{Name: name + "_flags"}
+	flags := &generator.Ident{Name: "flags"}
+	flag := &generator.Ident{Name: "flag"}
+	params := []generator.Param{{Type: flagsType, Name: flags}, {Type: enumType, Name: flag}}
+	helper := func(suffix string, typ generator.Expr, value generator.Expr) *generator.FuncDef {
+		return &generator.FuncDef{
+			Prototype: generator.Prototype{
+				Attrs:  accessorAttrs(),
+				Type:   typ,
+				Name:   &generator.Ident{Name: name + "_" + suffix},
+				Params: params,
+			},
+			Body: []generator.Stmt{&generator.Return{Value: value}},
+		}
+	}
+
+	return []generator.Decl{
+		&generator.Typedef{Type: &generator.Ident{Name: storage}, Name: flagsType},
+		helper("has", &generator.Ident{Name: "bool"}, &generator.BinaryOp{
+			Operator: "!=",
+			Left:     &generator.BinaryOp{Operator: "&", Left: flags, Right: flag},
+			Right:    &generator.Literal{Value: "0"},
+		}),
+		helper("set", flagsType, &generator.BinaryOp{Operator: "|", Left: flags, Right: flag}),
+		helper("clear", flagsType, &generator.BinaryOp{
+			Operator: "&",
+			Left:     flags,
+			Right:    &generator.UnaryOp{Operator: "~", Operand: flag},
+		}),
+	}
+}
@@ -0,0 +1,53 @@
+package compiler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/cedmundo/SimpleSchema/analyzer"
+	"github.com/cedmundo/SimpleSchema/generator"
+	"github.com/cedmundo/SimpleSchema/lexer"
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+// compileOptions applies the settings of the options directive: namespace prefixes the generated names like a
+// module path does with Config.ModulePrefix (it takes precedence over the module) and version emits a
+// static const int schema_version. Returns the declarations to emit before everything else.
+func (c *Compiler) compileOptions(options *parser.OptionsDecl) ([]generator.Decl, error) {
+	hasVersion := false
+	version := int64(0)
+	for _, option := range options.Block.Options {
+		name := parser.LookupName(option.Name)
+		switch name {
+		case "namespace":
+			namespace, ok := option.Value.(*parser.Literal)
+			if !ok || namespace.Token.Tag != lexer.TokenTagString || namespace.Token.Value == "" {
+				return nil, fmt.Errorf("%s: %w: namespace must be a non empty string", parser.ExprLoc(option.Value),
+					ErrInvalidOption)
+			}
+
+			c.prefix = c.modulePrefix(namespace.Token.Value)
+		case "version":
+			value, err := analyzer.EvalConst(option.Value, c.consts)
+			if err != nil {
+				return nil, fmt.Errorf("%w: version: %w", ErrInvalidOption, err)
+			}
+
+			version, hasVersion = value, true
+		default:
+			return nil, fmt.Errorf("%s: %w: unknown option `%s`", parser.ExprLoc(option.Name), ErrInvalidOption, name)
+		}
+	}
+
+	if !hasVersion {
+		return nil, nil
+	}
+
+	// named once the namespace is applied, wherever it is written
+	return []generator.Decl{&generator.GlobalVar{
+		Storage: "static",
+		Type:    &generator.Const{Type: &generator.Ident{Name: "int"}},
+		Name:    &generator.Ident{Name: c.cName("schema_version")},
+		Value:   &generator.Literal{Value: strconv.FormatInt(version, 10)},
+	}}, nil
+}
@@ -0,0 +1,144 @@
+package compiler
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+// ErrCyclicType indicates that types contain each other by value, so no declaration order is valid
+var ErrCyclicType = errors.New("cyclic type")
+
+// typeNode is a type declaration along with the names of the types it references
+type typeNode struct {
+	decl parser.Decl
+	name string
+	// hard are the types that must be complete before this declaration (fields by value, typedefs)
+	hard []string
+	// soft are the struct and union types only referenced through pointers, a forward declaration is enough
+	soft []string
+}
+
+// orderTypeDecls sorts type declarations so each one comes after the types it requires complete, keeping
+// source order otherwise. Types only referenced through pointers are not reordered, they get a forward
+// declaration instead.
+func (c *Compiler) orderTypeDecls(decls []parser.Decl) ([]typeNode, error) {
+	nodes := make(map[string]*typeNode)
+	sources := make([]*typeNode, 0, len(decls))
+	for _, decl := range decls {
+		typeDecl := unwrapDecl(decl).(*parser.TypeDecl)
+		node := &typeNode{decl: decl, name: parser.LookupName(typeDecl.Name)}
+		c.collectDeps(node, typeDecl.Type, false)
+		nodes[node.name] = node
+		sources = append(sources, node)
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int)
+	ordered := make([]typeNode, 0, len(sources))
+	var visit func(node *typeNode, chain []string) error
+	visit = func(node *typeNode, chain []string) error {
+		chain = append(chain, node.name)
+		switch state[node.name] {
+		case visited:
+			return nil
+		case visiting:
+			loc := parser.ExprLoc(unwrapDecl(node.decl).(*parser.TypeDecl).Name)
+			cycle := chain[slices.Index(chain, node.name):]
+			return fmt.Errorf("%s: %w: %s", loc, ErrCyclicType, strings.Join(cycle, " -> "))
+		}
+
+		state[node.name] = visiting
+		for _, dep := range node.hard {
+			depNode, ok := nodes[dep]
+			if !ok {
+				continue
+			}
+
+			err := visit(depNode, chain)
+			if err != nil {
+				return err
+			}
+		}
+
+		state[node.name] = visited
+		ordered = append(ordered, *node)
+		return nil
+	}
+
+	for _, node := range sources {
+		err := visit(node, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
+
+// collectDeps appends the type names referenced by expr, behindPointer marks references through a pointer
+func (c *Compiler) collectDeps(node *typeNode, expr parser.Expr, behindPointer bool) {
+	switch e := expr.(type) {
+	case *parser.Ident:
+		name := e.Token.Value
+		kind, ok := c.kinds[name]
+		if !ok {
+			return
+		}
+
+		if behindPointer && (kind == "struct" || kind == "union") {
+			if name != node.name {
+				node.soft = append(node.soft, name)
+			}
+			return
+		}
+
+		node.hard = append(node.hard, name)
+	case *parser.QualifiedName:
+		c.collectDeps(node, e.Name, behindPointer)
+	case *parser.UnaryOp:
+		c.collectDeps(node, e.Operand, behindPointer || e.Operator.Value == "*")
+	case *parser.Index:
+		c.collectDeps(node, e.Base, behindPointer)
+	case *parser.Call:
+		for _, arg := range e.Args {
+			c.collectDeps(node, arg, behindPointer)
+		}
+	case *parser.SetType:
+		// collections hold their items through pointers
+		c.collectDeps(node, e.Element, true)
+	case *parser.MapType:
+		c.collectDeps(node, e.Key, true)
+		c.collectDeps(node, e.Value, true)
+	case *parser.StructDef:
+		c.collectBlockDeps(node, e.Block, behindPointer)
+	case *parser.UnionDef:
+		c.collectBlockDeps(node, e.Block, behindPointer)
+	case *parser.PrototypeDef:
+		for _, param := range e.Params {
+			c.collectDeps(node, param.Type, true)
+		}
+		c.collectDeps(node, e.ReturnType, true)
+	}
+}
+
+func (c *Compiler) collectBlockDeps(node *typeNode, block parser.Block, behindPointer bool) {
+	for _, decl := range block.Decls {
+		switch d := unwrapDecl(decl).(type) {
+		case *parser.Field:
+			if d.Type != nil {
+				c.collectDeps(node, d.Type, behindPointer)
+			}
+		case *parser.EmbedDecl:
+			c.collectDeps(node, d.Type, behindPointer)
+		}
+	}
+}
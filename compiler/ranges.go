@@ -0,0 +1,97 @@
+package compiler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/cedmundo/SimpleSchema/analyzer"
+	"github.com/cedmundo/SimpleSchema/generator"
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+// compileRangeValidators makes a X_a_valid function per field with a range constraint (a : int in 0..100), it
+// reports whether a value is within the inclusive bounds. Array fields validate their elements.
+func (c *Compiler) compileRangeValidators(name string, block parser.Block, fields []generator.Field) ([]generator.Decl, error) {
+	decls := make([]generator.Decl, 0)
+	for i, decl := range block.Decls {
+		field, ok := unwrapDecl(decl).(*parser.Field)
+		if !ok || field.Range == nil {
+			continue
+		}
+
+		low, err := c.lowerValue(field.Range.Low)
+		if err != nil {
+			return nil, err
+		}
+
+		high, err := c.lowerValue(field.Range.High)
+		if err != nil {
+			return nil, err
+		}
+
+		c.includes["stdbool.h"] = true
+		value := &generator.Ident{Name: "value"}
+		decls = append(decls, &generator.FuncDef{
+			Prototype: generator.Prototype{
+				Attrs:  accessorAttrs(),
+				Type:   &generator.Ident{Name: "bool"},
+				Name:   &generator.Ident{Name: name + "_" + fieldName(fields[i]) + "_valid"},
+				Params: []generator.Param{{Type: fields[i].Type, Name: value}},
+			},
+			Body: []generator.Stmt{
+				&generator.Return{Value: &generator.BinaryOp{
+					Operator: "&&",
+					Left:     &generator.BinaryOp{Operator: ">=", Left: value, Right: low},
+					Right:    &generator.BinaryOp{Operator: "<=", Left: value, Right: high},
+				}},
+			},
+		})
+	}
+
+	return decls, nil
+}
+
+// compileRangeBounds makes a X_A_MIN and X_A_MAX macro per field with a range constraint, so the bounds can be
+// referenced by the consumers. Integer bounds are folded to their value, any other bound is lowered as written.
+func (c *Compiler) compileRangeBounds(name string, block parser.Block, fields []generator.Field) ([]generator.Decl, error) {
+	decls := make([]generator.Decl, 0)
+	for i, decl := range block.Decls {
+		field, ok := unwrapDecl(decl).(*parser.Field)
+		if !ok || field.Range == nil {
+			continue
+		}
+
+		low, err := c.lowerBound(field.Range.Low)
+		if err != nil {
+			return nil, err
+		}
+
+		high, err := c.lowerBound(field.Range.High)
+		if err != nil {
+			return nil, err
+		}
+
+		prefix := strings.ToUpper(name + "_" + fieldName(fields[i]))
+		decls = append(decls,
+			&generator.Define{Name: prefix + "_MIN", Value: low},
+			&generator.Define{Name: prefix + "_MAX", Value: high},
+		)
+	}
+
+	return decls, nil
+}
+
+// lowerBound folds a range bound, negative values are wrapped in parenthesis so the macro expands safely
+func (c *Compiler) lowerBound(bound parser.Expr) (generator.Expr, error) {
+	value, err := analyzer.EvalConst(bound, c.consts)
+	if err != nil {
+		return c.lowerValue(bound)
+	}
+
+	literal := &generator.Literal{Value: strconv.FormatInt(value, 10)}
+	if value < 0 {
+		return &generator.Paren{Expr: literal}, nil
+	}
+
+	return literal, nil
+}
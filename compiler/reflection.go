@@ -0,0 +1,146 @@
+package compiler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/cedmundo/SimpleSchema/generator"
+)
+
+// fieldKinds are the members of the field_kind enum, in order, fieldKind picks the one of a lowered field
+var fieldKinds = []string{"int", "uint", "float", "bool", "char", "enum", "struct", "union", "pointer", "array", "other"}
+
+// compileReflection makes the metadata table of a struct for runtime introspection: a static X_fields table with a
+// field_info entry per field (name, offsetof, kind and the C spelling of its type) and an X_field_infos accessor
+// returning it along with its length. The field_info struct and the field_kind enum are shared by every table and
+// queued once per file. Anonymous members have no name to report and are left out, structs without named fields
+// get no table.
+func (c *Compiler) compileReflection(name string, fields []generator.Field) []generator.Decl {
+	table := &generator.ArrayInit{}
+	for _, field := range fields {
+		if field.Name == nil {
+			continue
+		}
+
+		table.Elems = append(table.Elems, &generator.StructInit{Fields: []generator.FieldInit{
+			{Name: "name", Value: &generator.Literal{Value: strconv.Quote(fieldName(field))}},
+			{Name: "offset", Value: &generator.Call{
+				Callee: &generator.Ident{Name: "offsetof"},
+				Args:   []generator.Expr{&generator.Ident{Name: "struct " + name}, &generator.Ident{Name: fieldName(field)}},
+			}},
+			{Name: "kind", Value: &generator.Ident{Name: c.cName("field_kind") + "_" + fieldKind(field)}},
+			{Name: "type", Value: &generator.Literal{Value: strconv.Quote(fieldTypeTag(field))}},
+		}})
+	}
+
+	if len(table.Elems) == 0 {
+		return nil
+	}
+
+	infoType := c.fieldInfoType()
+	sizeType := &generator.Ident{Name: "size_t"}
+	tableName := &generator.Ident{Name: name + "_fields"}
+	count := &generator.Ident{Name: "count"}
+	return []generator.Decl{
+		&generator.GlobalVar{
+			Storage: "static",
+			Type:    &generator.Const{Type: infoType},
+			Name:    &generator.Subscript{Base: tableName},
+			Value:   table,
+		},
+		&generator.FuncDef{
+			Prototype: generator.Prototype{
+				Attrs:  accessorAttrs(),
+				Type:   &generator.Pointer{Type: &generator.Const{Type: infoType}},
+				Name:   &generator.Ident{Name: name + "_field_infos"},
+				Params: []generator.Param{{Type: &generator.Pointer{Type: sizeType}, Name: count}},
+			},
+			Body: []generator.Stmt{
+				&generator.ExprStmt{Expr: &generator.BinaryOp{
+					Operator: "=",
+					Left:     &generator.UnaryOp{Operator: "*", Operand: count},
+					Right:    &generator.Literal{Value: strconv.Itoa(len(table.Elems))},
+				}},
+				&generator.Return{Value: tableName},
+			},
+		},
+	}
+}
+
+// fieldInfoType queues the field_kind enum and the field_info struct the first time a table is made and returns
+// the struct type
+func (c *Compiler) fieldInfoType() generator.Expr {
+	name := c.cName("field_info")
+	kind := c.cName("field_kind")
+	if !c.collections[name] {
+		c.collections[name] = true
+		members := make([]generator.EnumMember, 0, len(fieldKinds))
+		for _, member := range fieldKinds {
+			members = append(members, generator.EnumMember{Name: &generator.Ident{Name: kind + "_" + member}})
+		}
+
+		str := &generator.Pointer{Type: &generator.Const{Type: &generator.Ident{Name: "char"}}}
+		c.pending = append(c.pending,
+			&generator.EnumDecl{Enum: generator.Enum{Name: &generator.Ident{Name: kind}, Members: members}},
+			&generator.StructDecl{Struct: generator.Struct{
+				Name: &generator.Ident{Name: name},
+				Fields: []generator.Field{
+					{Type: str, Name: &generator.Ident{Name: "name"}},
+					{Type: &generator.Ident{Name: "size_t"}, Name: &generator.Ident{Name: "offset"}},
+					{Type: &generator.Ident{Name: "enum " + kind}, Name: &generator.Ident{Name: "kind"}},
+					{Type: str, Name: &generator.Ident{Name: "type"}},
+				},
+			}},
+		)
+	}
+
+	return &generator.Ident{Name: "struct " + name}
+}
+
+// fieldKind classifies a lowered field by its C type, the typedefs of the schema are reported as other
+func fieldKind(field generator.Field) string {
+	if _, isArray := field.Name.(*generator.Subscript); isArray {
+		return "array"
+	}
+
+	typ := field.Type
+	if constant, ok := typ.(*generator.Const); ok {
+		typ = constant.Type
+	}
+
+	if _, ok := typ.(*generator.Pointer); ok {
+		return "pointer"
+	}
+
+	ident, ok := typ.(*generator.Ident)
+	if !ok {
+		return "other"
+	}
+
+	switch ident.Name {
+	case "int", "int8_t", "int16_t", "int32_t", "int64_t", "ptrdiff_t":
+		return "int"
+	case "unsigned int", "uint8_t", "uint16_t", "uint32_t", "uint64_t", "size_t":
+		return "uint"
+	case "float", "double":
+		return "float"
+	case "bool":
+		return "bool"
+	case "char":
+		return "char"
+	}
+
+	for _, tag := range []string{"enum", "struct", "union"} {
+		if strings.HasPrefix(ident.Name, tag+" ") {
+			return tag
+		}
+	}
+
+	return "other"
+}
+
+// fieldTypeTag spells the type of a field as it would be declared without its name (float[2] for float box[2])
+func fieldTypeTag(field generator.Field) string {
+	dims := strings.TrimPrefix(field.Name.Generate(0), fieldName(field))
+	return field.Type.Generate(0) + dims
+}
@@ -0,0 +1,79 @@
+package compiler
+
+import (
+	"strings"
+
+	"github.com/cedmundo/SimpleSchema/generator"
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+// CompileSplit lowers a schema into a header and a source file using the default configuration
+func CompileSplit(schema *parser.Schema) (*generator.File, *generator.File, error) {
+	return New(Config{}).CompileSplit(schema)
+}
+
+// CompileSplit lowers a schema like Compile but moves the bodies of the functions having external linkage into
+// a source file including the header, the header keeps their prototypes. The union visit functions get external
+// linkage here (they are static inline in a single header), while the one-line helpers (like the accessors) stay
+// static inline in the header since every translation unit needs their definitions. The header is always wrapped
+// in a ward, named after the module or after HeaderName when the schema has none.
+func (c *Compiler) CompileSplit(schema *parser.Schema) (*generator.File, *generator.File, error) {
+	c.split = true
+	file, err := c.Compile(schema)
+	c.split = false
+	if err != nil {
+		return nil, nil, err
+	}
+
+	headerName := c.headerName(schema)
+	ward := &generator.ModuleWard{Name: wardName(strings.TrimSuffix(headerName, ".h")), Decls: file.Decls}
+	if len(file.Decls) == 1 {
+		if moduleWard, ok := file.Decls[0].(*generator.ModuleWard); ok {
+			ward = moduleWard
+		}
+	}
+
+	headerDecls := make([]generator.Decl, 0, len(ward.Decls))
+	sourceDecls := []generator.Decl{&generator.Include{File: headerName, Relative: true}}
+	for _, decl := range ward.Decls {
+		def, ok := decl.(*generator.FuncDef)
+		if !ok || isStatic(def.Prototype) {
+			headerDecls = append(headerDecls, decl)
+			continue
+		}
+
+		headerDecls = append(headerDecls, &generator.PrototypeDecl{Prototype: def.Prototype})
+		sourceDecls = append(sourceDecls, def)
+	}
+
+	ward.Decls = headerDecls
+	header := &generator.File{Decls: []generator.Decl{ward}}
+	source := &generator.File{Decls: sourceDecls}
+	return header, source, nil
+}
+
+// headerName returns the file included by the split source, HeaderName or the module path (module net.http;
+// makes net_http.h) and schema.h without either
+func (c *Compiler) headerName(schema *parser.Schema) string {
+	if c.config.HeaderName != "" {
+		return c.config.HeaderName
+	}
+
+	for _, decl := range schema.Decls {
+		if module, ok := unwrapDecl(decl).(*parser.ModuleDecl); ok {
+			return strings.ReplaceAll(parser.LookupName(module.Name), ".", "_") + ".h"
+		}
+	}
+
+	return "schema.h"
+}
+
+func isStatic(proto generator.Prototype) bool {
+	for _, attr := range proto.Attrs {
+		if specifier, ok := attr.(*generator.Specifier); ok && specifier.Name == "static" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,152 @@
+package compiler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cedmundo/SimpleSchema/analyzer"
+	"github.com/cedmundo/SimpleSchema/generator"
+	"github.com/cedmundo/SimpleSchema/lexer"
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+// tableColumn is a field of the info struct of an enum, made from the annotations sharing a name
+type tableColumn struct {
+	name string
+	typ  generator.Expr
+	// length is the size of the longest list given to the column, zero for scalars
+	length int
+}
+
+// compileEnumTable makes the data table of an enum whose members are annotated with custom annotations
+// ([[ rgb = [255, 0, 0], label = "red" ]]): a struct X_info with a field per annotation name and a static X_table
+// indexed by the members. The annotations known to the toolchain (see analyzer.DefaultRegistry) are left out.
+// Every member has an entry so any of them indexes the table, the annotations a member lacks are zeroed. Enums
+// without custom annotations get no table.
+func (c *Compiler) compileEnumTable(name string, def *parser.EnumDef) ([]generator.Decl, error) {
+	registry := analyzer.DefaultRegistry()
+	columns := make([]*tableColumn, 0)
+	byName := make(map[string]*tableColumn)
+	table := &generator.ArrayInit{}
+	for _, decl := range def.Block.Decls {
+		member, ok := unwrapDecl(decl).(*parser.Field)
+		if !ok {
+			continue
+		}
+
+		var annotations []*parser.Annotation
+		if annotated, ok := decl.(*parser.AnnotatedDecl); ok {
+			annotations = annotated.Annotations
+		}
+
+		init := &generator.StructInit{}
+		for _, annotation := range annotations {
+			key := parser.LookupName(annotation.Name)
+			if _, known := registry.Lookup(key); known {
+				continue
+			}
+
+			typ, length, err := tableColumnType(annotation.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w: `%s`", parser.ExprLoc(annotation.Name), err, key)
+			}
+
+			// dotted annotations (json.name) become json_name
+			column, ok := byName[key]
+			if !ok {
+				column = &tableColumn{name: strings.ReplaceAll(key, ".", "_"), typ: typ}
+				columns = append(columns, column)
+				byName[key] = column
+			} else if column.typ.Generate(0) != typ.Generate(0) || (column.length == 0) != (length == 0) {
+				return nil, fmt.Errorf("%s: %w: `%s` has a different type than in the previous members",
+					parser.ExprLoc(annotation.Name), ErrUnsupportedExpr, key)
+			}
+			column.length = max(column.length, length)
+
+			value := generator.Expr(&generator.Ident{Name: "true"})
+			if annotation.Value != nil {
+				value, err = c.lowerValue(annotation.Value)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			init.Fields = append(init.Fields, generator.FieldInit{Name: column.name, Value: value})
+		}
+
+		index := &generator.Ident{Name: parser.LookupName(member.Name)}
+		table.Elems = append(table.Elems, &generator.IndexInit{Index: index, Value: init})
+	}
+
+	if len(columns) == 0 {
+		return nil, nil
+	}
+
+	fields := make([]generator.Field, 0, len(columns))
+	for _, column := range columns {
+		field := generator.Field{Type: column.typ, Name: &generator.Ident{Name: column.name}}
+		if column.length != 0 {
+			count := &generator.Literal{Value: strconv.Itoa(column.length)}
+			field.Name = &generator.Subscript{Base: field.Name, Index: count}
+		}
+
+		if column.typ.Generate(0) == "bool" {
+			c.includes["stdbool.h"] = true
+		}
+
+		fields = append(fields, field)
+	}
+
+	info := generator.Struct{Name: &generator.Ident{Name: name + "_info"}, Fields: fields}
+	return []generator.Decl{
+		&generator.StructDecl{Struct: info},
+		&generator.GlobalVar{
+			Storage: "static",
+			Type:    &generator.Const{Type: &generator.Ident{Name: "struct " + name + "_info"}},
+			Name:    &generator.Subscript{Base: &generator.Ident{Name: name + "_table"}},
+			Value:   table,
+		},
+	}, nil
+}
+
+// tableColumnType infers the C type of an annotation value, lists take the type of their first element and return
+// their length (nested lists are not supported). Flags (annotations without value) are booleans.
+func tableColumnType(value parser.Expr) (generator.Expr, int, error) {
+	switch v := value.(type) {
+	case nil:
+		return &generator.Ident{Name: "bool"}, 0, nil
+	case *parser.Literal:
+		switch v.Token.Tag {
+		case lexer.TokenTagString:
+			return &generator.Pointer{Type: &generator.Const{Type: &generator.Ident{Name: "char"}}}, 0, nil
+		case lexer.TokenTagDecInt, lexer.TokenTagHexInt, lexer.TokenTagBinInt, lexer.TokenTagOctInt:
+			return &generator.Ident{Name: "int"}, 0, nil
+		case lexer.TokenTagFloat:
+			return &generator.Ident{Name: "double"}, 0, nil
+		case lexer.TokenTagChar:
+			return &generator.Ident{Name: "char"}, 0, nil
+		}
+	case *parser.Ident:
+		if v.Token.Value == "true" || v.Token.Value == "false" {
+			return &generator.Ident{Name: "bool"}, 0, nil
+		}
+	case *parser.UnaryOp:
+		if v.Operator.Value == "-" {
+			return tableColumnType(v.Operand)
+		}
+	case *parser.ListLit:
+		if len(v.Elements) == 0 {
+			break
+		}
+
+		if _, nested := v.Elements[0].(*parser.ListLit); nested {
+			break
+		}
+
+		typ, _, err := tableColumnType(v.Elements[0])
+		return typ, len(v.Elements), err
+	}
+
+	return nil, 0, ErrUnsupportedExpr
+}
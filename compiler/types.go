@@ -0,0 +1,255 @@
+package compiler
+
+import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/cedmundo/SimpleSchema/generator"
+	"github.com/cedmundo/SimpleSchema/keywords"
+	"github.com/cedmundo/SimpleSchema/lexer"
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+// builtinType is the C equivalent of a schema builtin type and the header declaring it, Size is its width in
+// bytes on the wire (zero when it depends on the platform)
+type builtinType struct {
+	Name    string
+	Include string
+	Size    int64
+}
+
+var builtinTypes = map[string]builtinType{
+	"void":   {Name: "void"},
+	"char":   {Name: "char", Size: 1},
+	"int":    {Name: "int"},
+	"uint":   {Name: "unsigned int"},
+	"float":  {Name: "float", Size: 4},
+	"double": {Name: "double", Size: 8},
+	"f32":    {Name: "float", Size: 4},
+	"f64":    {Name: "double", Size: 8},
+	"bool":   {Name: "bool", Include: "stdbool.h", Size: 1},
+	"byte":   {Name: "uint8_t", Include: "stdint.h", Size: 1},
+	"i8":     {Name: "int8_t", Include: "stdint.h", Size: 1},
+	"i16":    {Name: "int16_t", Include: "stdint.h", Size: 2},
+	"i32":    {Name: "int32_t", Include: "stdint.h", Size: 4},
+	"i64":    {Name: "int64_t", Include: "stdint.h", Size: 8},
+	"u8":     {Name: "uint8_t", Include: "stdint.h", Size: 1},
+	"u16":    {Name: "uint16_t", Include: "stdint.h", Size: 2},
+	"u32":    {Name: "uint32_t", Include: "stdint.h", Size: 4},
+	"u64":    {Name: "uint64_t", Include: "stdint.h", Size: 8},
+	"usize":  {Name: "size_t", Include: "stddef.h"},
+	"isize":  {Name: "ptrdiff_t", Include: "stddef.h"},
+}
+
+// lowerType converts a schema type expression into a C type
+func (c *Compiler) lowerType(expr parser.Expr) (generator.Expr, error) {
+	switch e := expr.(type) {
+	case *parser.Ident:
+		return c.lowerTypeRef(e.Token)
+	case *parser.QualifiedName:
+		// the loader merges the imported declarations into the schema, so the type is declared by its own name
+		return c.lowerTypeRef(e.Name.Token)
+	case *parser.UnaryOp:
+		if e.Operator.Value != "*" {
+			break
+		}
+
+		typ, err := c.lowerType(e.Operand)
+		if err != nil {
+			return nil, err
+		}
+
+		return &generator.Pointer{Type: typ}, nil
+	case *parser.Call:
+		if parser.LookupName(e.Callee) != "const" || len(e.Args) != 1 {
+			break
+		}
+
+		typ, err := c.lowerType(e.Args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return &generator.Const{Type: typ}, nil
+	case *parser.StructDef:
+		fields, err := c.compileFields(e.Block)
+		if err != nil {
+			return nil, err
+		}
+
+		return &generator.Struct{Fields: fields}, nil
+	case *parser.UnionDef:
+		fields, err := c.compileFields(e.Block)
+		if err != nil {
+			return nil, err
+		}
+
+		return &generator.Union{Fields: fields}, nil
+	case *parser.EnumDef:
+		members, err := c.compileMembers(e.Block)
+		if err != nil {
+			return nil, err
+		}
+
+		return &generator.Enum{Members: members}, nil
+	case *parser.SetType:
+		return c.lowerSet(e)
+	case *parser.MapType:
+		return c.lowerMap(e)
+	case *parser.DecimalType:
+		return c.lowerDecimal(e)
+	case *parser.SizedIntType:
+		return c.lowerSizedInt(e)
+	}
+
+	return nil, fmt.Errorf("%s: %w: %T", parser.ExprLoc(expr), ErrUnsupportedType, expr)
+}
+
+// lowerTypeRef lowers a type referenced by name, the builtins without a C equivalent (a string without a size) are
+// rejected unless a declaration shadows them
+func (c *Compiler) lowerTypeRef(token lexer.Token) (generator.Expr, error) {
+	name := token.Value
+	if _, declared := c.kinds[name]; !declared && keywords.IsBuiltin(name) {
+		if _, ok := builtinTypes[name]; !ok {
+			return nil, fmt.Errorf("%s: %w: %s has no C equivalent", token.Loc, ErrUnsupportedType, name)
+		}
+	}
+
+	return c.lowerTypeName(name), nil
+}
+
+// lowerTypeName maps builtins to their C names and prefixes user types with their tag (and module, see cName)
+func (c *Compiler) lowerTypeName(name string) generator.Expr {
+	if builtin, ok := builtinTypes[name]; ok {
+		if builtin.Include != "" {
+			c.includes[builtin.Include] = true
+		}
+
+		return &generator.Ident{Name: builtin.Name}
+	}
+
+	kind, ok := c.kinds[name]
+	if !ok {
+		return &generator.Ident{Name: name}
+	}
+
+	if kind != "" {
+		return &generator.Ident{Name: kind + " " + c.cName(name)}
+	}
+
+	return &generator.Ident{Name: c.cName(name)}
+}
+
+// lowerDeclarator converts a type expression for a named entity, arrays are moved into the name (int x[4]) and
+// so are fixed strings (char x[32])
+func (c *Compiler) lowerDeclarator(expr parser.Expr, name generator.Expr) (generator.Expr, generator.Expr, error) {
+	switch e := expr.(type) {
+	case *parser.Index:
+		if e.Index == nil {
+			return c.lowerDeclarator(e.Base, &generator.Subscript{Base: name})
+		}
+
+		size, err := c.lowerValue(e.Index)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return c.lowerDeclarator(e.Base, &generator.Subscript{Base: name, Index: size})
+	case *parser.FixedStringType:
+		size, err := c.lowerValue(e.Size)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return &generator.Ident{Name: "char"}, &generator.Subscript{Base: name, Index: size}, nil
+	}
+
+	typ, err := c.lowerType(expr)
+	return typ, name, err
+}
+
+// lowerValue converts a schema value expression into a C expression
+func (c *Compiler) lowerValue(expr parser.Expr) (generator.Expr, error) {
+	switch e := expr.(type) {
+	case *parser.Literal:
+		// C characters are single bytes, wider ones would make multibyte character constants
+		if _, size := utf8.DecodeRuneInString(e.Token.Value); e.Token.Tag == lexer.TokenTagChar && size > 1 {
+			return nil, fmt.Errorf("%s: %w: `%s` is not an ASCII character", e.Token.Loc, ErrUnsupportedExpr,
+				e.Token.Value)
+		}
+
+		return &generator.Literal{Value: literalText(e.Token)}, nil
+	case *parser.Ident:
+		return &generator.Ident{Name: e.Token.Value}, nil
+	case *parser.UnaryOp:
+		operand, err := c.lowerValue(e.Operand)
+		if err != nil {
+			return nil, err
+		}
+
+		return &generator.UnaryOp{Operator: e.Operator.Value, Operand: operand, Postfix: e.Postfix}, nil
+	case *parser.ListLit:
+		list := &generator.ArrayInit{Inline: true}
+		for _, element := range e.Elements {
+			value, err := c.lowerValue(element)
+			if err != nil {
+				return nil, err
+			}
+
+			list.Elems = append(list.Elems, value)
+		}
+
+		return list, nil
+	case *parser.BytesLit:
+		bytes := &generator.ArrayInit{Inline: true}
+		for _, b := range e.Bytes {
+			bytes.Elems = append(bytes.Elems, &generator.Literal{Value: fmt.Sprintf("0x%02X", b)})
+		}
+
+		return bytes, nil
+	case *parser.BinaryOp:
+		// C has no power operator
+		if e.Operator.Value == "**" {
+			return nil, fmt.Errorf("%s: %w: power operator", e.Operator.Loc, ErrUnsupportedExpr)
+		}
+
+		left, err := c.lowerValue(e.Left)
+		if err != nil {
+			return nil, err
+		}
+
+		right, err := c.lowerValue(e.Right)
+		if err != nil {
+			return nil, err
+		}
+
+		return &generator.BinaryOp{Operator: e.Operator.Value, Left: left, Right: right}, nil
+	}
+
+	return nil, fmt.Errorf("%s: %w: %T", parser.ExprLoc(expr), ErrUnsupportedExpr, expr)
+}
+
+// literalText restores the C prefix of numeric literals and quotes strings and characters
+func literalText(token lexer.Token) string {
+	switch token.Tag {
+	case lexer.TokenTagHexInt:
+		return "0x" + token.Value
+	case lexer.TokenTagBinInt:
+		return "0b" + token.Value
+	case lexer.TokenTagOctInt:
+		return "0" + token.Value
+	case lexer.TokenTagString:
+		return strconv.Quote(token.Value)
+	case lexer.TokenTagChar:
+		// byte escapes ('\xC0') are not valid runes
+		r, size := utf8.DecodeRuneInString(token.Value)
+		if r == utf8.RuneError && size == 1 {
+			return fmt.Sprintf(`'\x%02x'`, token.Value[0])
+		}
+
+		return strconv.QuoteRune(r)
+	}
+
+	return token.Value
+}
@@ -0,0 +1,21 @@
+package compiler
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/cedmundo/SimpleSchema/keywords"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltinTypes(t *testing.T) {
+	// string sizes fixed strings (string<32>), which are lowered apart as char arrays
+	names := slices.DeleteFunc(slices.Clone(keywords.Builtins), func(name string) bool { return name == "string" })
+	for _, name := range names {
+		require.Contains(t, builtinTypes, name)
+	}
+
+	for name := range builtinTypes {
+		require.Contains(t, names, name)
+	}
+}
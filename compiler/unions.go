@@ -0,0 +1,268 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/cedmundo/SimpleSchema/generator"
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+func (c *Compiler) compileUnion(name string, def *parser.UnionDef, annotated *parser.AnnotatedDecl) ([]generator.Decl, error) {
+	// a declared tag field (tag kind : Kind) is moved out of the union into the tagged struct
+	members, tagDecls := parser.Block{}, parser.Block{}
+	for _, decl := range def.Block.Decls {
+		if field, ok := unwrapDecl(decl).(*parser.Field); ok && field.Tag {
+			tagDecls.Decls = append(tagDecls.Decls, decl)
+			continue
+		}
+
+		members.Decls = append(members.Decls, decl)
+	}
+
+	fields, err := c.compileFields(members)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := c.compileFields(tagDecls)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs, err := annotatedAttrs(annotated)
+	if err != nil {
+		return nil, err
+	}
+
+	union := generator.Union{TagAttrs: attrs, Name: &generator.Ident{Name: name}, Fields: fields}
+	decls := []generator.Decl{&generator.UnionDecl{Union: union}}
+
+	scales, err := compileDecimalScales(name, def.Block)
+	if err != nil {
+		return nil, err
+	}
+	decls = append(decls, scales...)
+
+	// both tagged unions without a tag field and visitors need the tag enum, it is emitted once
+	_, tagged := annotated.Find("tagged")
+	tagged = tagged || len(tags) > 0
+	if (tagged && len(tags) == 0) || c.config.UnionVisitors {
+		decls = append(decls, unionTagEnum(name, fields, c.config.ExhaustiveSwitches))
+	}
+
+	if tagged {
+		decls = append(decls, unionTaggedStruct(name, tags))
+	}
+
+	if _, ok := annotated.Find("accessors"); ok && tagged {
+		decls = append(decls, c.unionAccessors(name, members, fields, len(tags) == 0)...)
+	}
+
+	if c.config.UnionVisitors {
+		decls = append(decls, unionVisitor(name, fields), c.unionVisit(name, fields))
+		if c.config.ExhaustiveSwitches {
+			decls = append(decls, &generator.StaticAssert{
+				Condition: fmt.Sprintf("%s == %d", unionTagCount(name), len(fields)),
+				Message:   fmt.Sprintf("%s_visit does not handle every member of enum %s_tag", name, name),
+			})
+		}
+	}
+
+	return decls, nil
+}
+
+// unionTaggedStruct makes the struct pairing a tagged union and its discriminant, either the declared tag field
+// or the tag enum (struct X_tagged { enum X_tag tag; union X value; })
+func unionTaggedStruct(name string, tags []generator.Field) *generator.StructDecl {
+	tag := generator.Field{Type: &generator.Ident{Name: "enum " + name + "_tag"}, Name: &generator.Ident{Name: "tag"}}
+	if len(tags) > 0 {
+		tag = tags[0]
+	}
+
+	return &generator.StructDecl{Struct: generator.Struct{
+		Name: &generator.Ident{Name: name + "_tagged"},
+		Fields: []generator.Field{
+			tag,
+			{Type: &generator.Ident{Name: "union " + name}, Name: &generator.Ident{Name: "value"}},
+		},
+	}}
+}
+
+// unionAccessors makes a getter and a setter per member of a tagged union annotated with [[ accessors ]], they
+// take the tagged struct (X_get_a(const struct X_tagged* self)) and the setters update the tag as well. With
+// UnionAccessAsserts the getters assert that the member is the active one first. A declared tag field has values
+// of its own enum, unknown to the compiler, so neither the setters nor the asserts touch it.
+func (c *Compiler) unionAccessors(name string, block parser.Block, fields []generator.Field, ownTag bool) []generator.Decl {
+	self := &generator.Ident{Name: "self"}
+	value := &generator.Member{Base: self, Name: "value", Arrow: true}
+	tag := &generator.Member{Base: self, Name: "tag", Arrow: true}
+	tagged := &generator.Ident{Name: "struct " + name + "_tagged"}
+
+	decls := make([]generator.Decl, 0, len(fields)*2)
+	for i, field := range fields {
+		// arrays cannot be assigned, like in compileAccessors they only get a getter
+		typ := getterType(field.Type)
+		subscript, isArray := field.Name.(*generator.Subscript)
+		if isArray {
+			if _, nested := subscript.Base.(*generator.Subscript); nested {
+				continue
+			}
+			typ = &generator.Pointer{Type: constType(field.Type)}
+		}
+
+		active := &generator.Ident{Name: unionTagName(name, field)}
+		member := &generator.Member{Base: value, Name: fieldName(field)}
+		body := make([]generator.Stmt, 0, 2)
+		if c.config.UnionAccessAsserts && ownTag {
+			c.includes["assert.h"] = true
+			body = append(body, &generator.ExprStmt{Expr: &generator.Call{
+				Callee: &generator.Ident{Name: "assert"},
+				Args:   []generator.Expr{&generator.BinaryOp{Operator: "==", Left: tag, Right: active}},
+			}})
+		}
+
+		decls = append(decls, &generator.FuncDef{
+			Prototype: generator.Prototype{
+				Attrs:  accessorAttrs(),
+				Type:   typ,
+				Name:   &generator.Ident{Name: name + "_get_" + fieldName(field)},
+				Params: []generator.Param{{Type: &generator.Const{Type: &generator.Pointer{Type: tagged}}, Name: self}},
+			},
+			Body: append(body, &generator.Return{Value: member}),
+		})
+
+		annotated, _ := block.Decls[i].(*parser.AnnotatedDecl)
+		if _, readonly := annotated.Find("readonly"); readonly || !ownTag || isArray {
+			continue
+		}
+
+		arg := &generator.Ident{Name: "value"}
+		decls = append(decls, &generator.FuncDef{
+			Prototype: generator.Prototype{
+				Attrs: accessorAttrs(),
+				Type:  &generator.Ident{Name: "void"},
+				Name:  &generator.Ident{Name: name + "_set_" + fieldName(field)},
+				Params: []generator.Param{
+					{Type: &generator.Pointer{Type: tagged}, Name: self},
+					{Type: field.Type, Name: arg},
+				},
+			},
+			Body: []generator.Stmt{
+				&generator.ExprStmt{Expr: &generator.BinaryOp{Operator: "=", Left: tag, Right: active}},
+				&generator.ExprStmt{Expr: &generator.BinaryOp{Operator: "=", Left: member, Right: arg}},
+			},
+		})
+	}
+
+	return decls
+}
+
+// unionTagEnum makes the discriminant enum of an union, with one member per field (enum X_tag { X_tag_a }), the
+// count member is appended last (X_tag_COUNT) so switches over the tag can assert they handle all of them
+func unionTagEnum(name string, fields []generator.Field, count bool) *generator.EnumDecl {
+	members := make([]generator.EnumMember, 0, len(fields)+1)
+	for _, field := range fields {
+		members = append(members, generator.EnumMember{Name: &generator.Ident{Name: unionTagName(name, field)}})
+	}
+
+	if count {
+		members = append(members, generator.EnumMember{Name: &generator.Ident{Name: unionTagCount(name)}})
+	}
+
+	return &generator.EnumDecl{Enum: generator.Enum{Name: &generator.Ident{Name: name + "_tag"}, Members: members}}
+}
+
+// unionVisitor makes the struct holding a context and one callback per union member
+func unionVisitor(name string, fields []generator.Field) *generator.StructDecl {
+	callbacks := []generator.Field{{
+		Type: &generator.Pointer{Type: &generator.Ident{Name: "void"}},
+		Name: &generator.Ident{Name: "ctx"},
+	}}
+
+	for _, field := range fields {
+		callbacks = append(callbacks, generator.Field{
+			Type: &generator.Ident{Name: "void"},
+			Name: &generator.FuncPointer{
+				Name: &generator.Ident{Name: fieldName(field)},
+				Params: []generator.Param{
+					{Type: &generator.Const{Type: &generator.Pointer{Type: field.Type}}, Name: &generator.Ident{Name: "value"}},
+					{Type: &generator.Pointer{Type: &generator.Ident{Name: "void"}}, Name: &generator.Ident{Name: "ctx"}},
+				},
+			},
+		})
+	}
+
+	return &generator.StructDecl{Struct: generator.Struct{Name: &generator.Ident{Name: name + "_visitor"}, Fields: callbacks}}
+}
+
+// unionVisit makes the function dispatching the active member of an union to its visitor callback, the count member
+// of the tag enum is not a member so it has an empty case (switches on enums must name every value under -Wswitch)
+func (c *Compiler) unionVisit(name string, fields []generator.Field) *generator.FuncDef {
+	self := &generator.Ident{Name: "self"}
+	visitor := &generator.Ident{Name: "visitor"}
+	cases := make([]generator.Case, 0, len(fields))
+	for _, field := range fields {
+		var value generator.Expr = &generator.Member{Base: self, Name: fieldName(field), Arrow: true}
+		if _, isArray := field.Name.(*generator.Subscript); !isArray {
+			value = &generator.UnaryOp{Operator: "&", Operand: value}
+		}
+
+		call := &generator.Call{
+			Callee: &generator.Member{Base: visitor, Name: fieldName(field), Arrow: true},
+			Args:   []generator.Expr{value, &generator.Member{Base: visitor, Name: "ctx", Arrow: true}},
+		}
+		cases = append(cases, generator.Case{
+			Value: &generator.Ident{Name: unionTagName(name, field)},
+			Body:  []generator.Stmt{&generator.ExprStmt{Expr: call}, &generator.Break{}},
+		})
+	}
+
+	if c.config.ExhaustiveSwitches {
+		cases = append(cases, generator.Case{
+			Value: &generator.Ident{Name: unionTagCount(name)},
+			Body:  []generator.Stmt{&generator.Break{}},
+		})
+	}
+
+	// within a single header the visit function is defined in every translation unit including it
+	attrs := accessorAttrs()
+	if c.split {
+		attrs = nil
+	}
+
+	return &generator.FuncDef{
+		Prototype: generator.Prototype{
+			Attrs: attrs,
+			Type:  &generator.Ident{Name: "void"},
+			Name:  &generator.Ident{Name: name + "_visit"},
+			Params: []generator.Param{
+				{Type: &generator.Const{Type: &generator.Pointer{Type: &generator.Ident{Name: "union " + name}}}, Name: self},
+				{Type: &generator.Ident{Name: "enum " + name + "_tag"}, Name: &generator.Ident{Name: "tag"}},
+				{Type: &generator.Const{Type: &generator.Pointer{Type: &generator.Ident{Name: "struct " + name + "_visitor"}}}, Name: visitor},
+			},
+		},
+		Body: []generator.Stmt{&generator.Switch{Value: &generator.Ident{Name: "tag"}, Cases: cases}},
+	}
+}
+
+func unionTagName(name string, field generator.Field) string {
+	return name + "_tag_" + fieldName(field)
+}
+
+func unionTagCount(name string) string {
+	return name + "_tag_COUNT"
+}
+
+// fieldName returns the plain name of a lowered field, without array subscripts
+func fieldName(field generator.Field) string {
+	name := field.Name
+	for {
+		subscript, ok := name.(*generator.Subscript)
+		if !ok {
+			break
+		}
+		name = subscript.Base
+	}
+
+	return name.Generate(0)
+}
@@ -0,0 +1,105 @@
+package compiler
+
+import (
+	"strconv"
+
+	"github.com/cedmundo/SimpleSchema/analyzer"
+	"github.com/cedmundo/SimpleSchema/generator"
+	"github.com/cedmundo/SimpleSchema/parser"
+)
+
+// compileWireSize makes the #define X_WIRE_SIZE n of a struct whose fields all have a fixed size, structs having
+// any variable length field get none (see wireSize)
+func (c *Compiler) compileWireSize(name string, def *parser.StructDef) []generator.Decl {
+	size, ok := c.wireSize(def, make(map[string]bool))
+	if !ok {
+		return nil
+	}
+
+	return []generator.Decl{&generator.Define{
+		Name:  name + "_WIRE_SIZE",
+		Value: &generator.Literal{Value: strconv.FormatInt(size, 10)},
+	}}
+}
+
+// wireSize returns the packed size in bytes of a type (the sum of its fields, without padding), false when its
+// length is variable (pointers, strings, collections, flexible arrays) or depends on the platform (int, enums).
+// Visited holds the declared types being measured, so recursive types are variable too.
+func (c *Compiler) wireSize(expr parser.Expr, visited map[string]bool) (int64, bool) {
+	switch e := expr.(type) {
+	case *parser.Ident:
+		name := e.Token.Value
+		if typ, ok := c.types[name]; ok {
+			if visited[name] {
+				return 0, false
+			}
+
+			visited[name] = true
+			defer delete(visited, name)
+			return c.wireSize(typ, visited)
+		}
+
+		builtin, ok := builtinTypes[name]
+		return builtin.Size, ok && builtin.Size > 0
+	case *parser.QualifiedName:
+		return c.wireSize(e.Name, visited)
+	case *parser.Call:
+		if parser.LookupName(e.Callee) != "const" || len(e.Args) != 1 {
+			break
+		}
+
+		return c.wireSize(e.Args[0], visited)
+	case *parser.Index:
+		if e.Index == nil {
+			break
+		}
+
+		count, err := analyzer.EvalConst(e.Index, c.consts)
+		if err != nil || count < 0 {
+			break
+		}
+
+		size, ok := c.wireSize(e.Base, visited)
+		return size * count, ok
+	case *parser.FixedStringType:
+		size, err := analyzer.EvalConst(e.Size, c.consts)
+		return size, err == nil && size >= 0
+	case *parser.DecimalType:
+		precision, err := analyzer.EvalConst(e.Precision, nil)
+		if err != nil {
+			break
+		}
+
+		switch {
+		case precision <= 9:
+			return 4, true
+		case precision <= 18:
+			return 8, true
+		}
+	case *parser.StructDef:
+		total := int64(0)
+		for _, decl := range e.Block.Decls {
+			var typ parser.Expr
+			switch d := unwrapDecl(decl).(type) {
+			case *parser.Field:
+				typ = d.Type
+			case *parser.EmbedDecl:
+				typ = d.Type
+			}
+			if typ == nil {
+				return 0, false
+			}
+
+			size, ok := c.wireSize(typ, visited)
+			if !ok {
+				return 0, false
+			}
+
+			total += size
+		}
+
+		return total, true
+	}
+
+	return 0, false
+}
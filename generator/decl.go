@@ -0,0 +1,307 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Union is an expression that can be used as type
+type Union struct {
+	Attrs []Attr
+	// TagAttrs apply to the union type itself, they are written after the keyword (union __attribute__((x)) u)
+	TagAttrs []Attr
+	Name     Expr
+	Fields   []Field
+}
+
+func (u *Union) expr() {}
+
+// Generate returns the equivalent code for an union with fields, the first line is not indented so it can be
+// embedded as a type (UnionDecl indents it)
+func (u *Union) Generate(depth int) string {
+	union := &strings.Builder{}
+	union.WriteString(AttrList(u.Attrs).GenerateList())
+	union.WriteString("union ")
+	union.WriteString(AttrList(u.TagAttrs).GenerateList())
+	if u.Name != nil {
+		union.WriteString(u.Name.Generate(depth))
+		union.WriteRune(' ')
+	}
+	union.WriteString(FieldBlock(u.Fields).GenerateBlock(depth))
+	return union.String()
+}
+
+// UnionDecl represents an union declaration
+type UnionDecl struct {
+	Union Union
+}
+
+func (ud *UnionDecl) decl() {}
+
+// Generate outputs the union expr with a trailing semicolon
+func (ud *UnionDecl) Generate(depth int) string {
+	return makeIndent(depth) + ud.Union.Generate(depth) + ";"
+}
+
+// EnumMember is a single enumeration constant with an optional value, the comment is written after its comma
+// (A = 1, /* comment */)
+type EnumMember struct {
+	Name    Expr
+	Value   Expr
+	Comment string
+}
+
+// GenerateMember outputs the member with indentation, without the trailing comma
+func (em *EnumMember) GenerateMember(depth int) string {
+	member := &strings.Builder{}
+	member.WriteString(makeIndent(depth))
+	member.WriteString(em.Name.Generate(depth))
+	if em.Value != nil {
+		member.WriteString(" = ")
+		member.WriteString(em.Value.Generate(depth))
+	}
+	return member.String()
+}
+
+// Enum is an expression that can be used as type
+type Enum struct {
+	Attrs []Attr
+	// TagAttrs apply to the enum type itself, they are written after the keyword (enum __attribute__((x)) e)
+	TagAttrs []Attr
+	Name     Expr
+	Members  []EnumMember
+}
+
+func (e *Enum) expr() {}
+
+// Generate returns the equivalent code for an enumeration, each member ends with a comma. The first line is not
+// indented so it can be embedded as a type (EnumDecl indents it).
+func (e *Enum) Generate(depth int) string {
+	enum := &strings.Builder{}
+	enum.WriteString(AttrList(e.Attrs).GenerateList())
+	enum.WriteString("enum ")
+	enum.WriteString(AttrList(e.TagAttrs).GenerateList())
+	if e.Name != nil {
+		enum.WriteString(e.Name.Generate(depth))
+		enum.WriteRune(' ')
+	}
+
+	enum.WriteRune('{')
+	if len(e.Members) > 0 {
+		enum.WriteRune('\n')
+	}
+
+	for _, member := range e.Members {
+		enum.WriteString(member.GenerateMember(depth + 1))
+		enum.WriteRune(',')
+		if member.Comment != "" {
+			enum.WriteString(" /* " + member.Comment + " */")
+		}
+		enum.WriteRune('\n')
+	}
+
+	enum.WriteString(makeIndent(depth))
+	enum.WriteRune('}')
+	return enum.String()
+}
+
+// EnumDecl represents an enum declaration
+type EnumDecl struct {
+	Enum Enum
+}
+
+func (ed *EnumDecl) decl() {}
+
+// Generate outputs the enum expr with a trailing semicolon
+func (ed *EnumDecl) Generate(depth int) string {
+	return makeIndent(depth) + ed.Enum.Generate(depth) + ";"
+}
+
+// Typedef represents a type alias, the name may be a declarator (like an array subscript)
+type Typedef struct {
+	Type Expr
+	Name Expr
+}
+
+func (t *Typedef) decl() {}
+
+// Generate outputs the typedef with a trailing semicolon
+func (t *Typedef) Generate(depth int) string {
+	return fmt.Sprintf("%stypedef %s %s;", makeIndent(depth), t.Type.Generate(depth), t.Name.Generate(depth))
+}
+
+// StaticAssert represents a compile-time assertion
+type StaticAssert struct {
+	Condition string
+	Message   string
+}
+
+func (sa *StaticAssert) decl() {}
+
+// Generate outputs the assertion with the message between double quotes
+func (sa *StaticAssert) Generate(depth int) string {
+	return fmt.Sprintf(`%s_Static_assert(%s, "%s");`, makeIndent(depth), sa.Condition, sa.Message)
+}
+
+// Define represents a macro, object-like (#define NAME value) or function-like when it has params
+// (#define NAME(a, b) value), the value is optional. Directives are not indented.
+type Define struct {
+	Name   string
+	Params []string
+	Value  Expr
+}
+
+func (d *Define) decl() {}
+
+// Generate outputs the directive on a single line
+func (d *Define) Generate(depth int) string {
+	name := d.Name
+	if len(d.Params) > 0 {
+		name += "(" + strings.Join(d.Params, ", ") + ")"
+	}
+
+	if d.Value == nil {
+		return "#define " + name
+	}
+
+	return "#define " + name + " " + d.Value.Generate(depth)
+}
+
+// ForwardDecl represents an incomplete struct or union declaration (struct name;)
+type ForwardDecl struct {
+	Tag  string
+	Name Expr
+}
+
+func (fd *ForwardDecl) decl() {}
+
+// Generate outputs the tag and the name with a trailing semicolon
+func (fd *ForwardDecl) Generate(depth int) string {
+	return fmt.Sprintf("%s%s %s;", makeIndent(depth), fd.Tag, fd.Name.Generate(depth))
+}
+
+// OpaqueDecl represents an opaque handle: a forward declaration plus a pointer typedef (typedef struct X* X_handle;)
+type OpaqueDecl struct {
+	Tag    string
+	Name   Expr
+	Handle Expr
+}
+
+func (od *OpaqueDecl) decl() {}
+
+// Generate outputs the forward declaration followed by the handle typedef on its own line
+func (od *OpaqueDecl) Generate(depth int) string {
+	forward := &ForwardDecl{Tag: od.Tag, Name: od.Name}
+	handle := &Typedef{
+		Type: &Pointer{Type: &Ident{Name: od.Tag + " " + od.Name.Generate(0)}},
+		Name: od.Handle,
+	}
+	return forward.Generate(depth) + "\n" + handle.Generate(depth)
+}
+
+// GlobalVar represents a variable declared at file scope, the storage class (static, extern) and the initializer
+// are optional
+type GlobalVar struct {
+	Attrs   []Attr
+	Storage string
+	Type    Expr
+	Name    Expr
+	Value   Expr
+}
+
+func (gv *GlobalVar) decl() {}
+
+// Generate outputs the variable declaration with a trailing semicolon
+func (gv *GlobalVar) Generate(depth int) string {
+	global := &strings.Builder{}
+	global.WriteString(makeIndent(depth))
+	global.WriteString(AttrList(gv.Attrs).GenerateList())
+	if gv.Storage != "" {
+		global.WriteString(gv.Storage)
+		global.WriteRune(' ')
+	}
+
+	global.WriteString(gv.Type.Generate(depth))
+	global.WriteRune(' ')
+	global.WriteString(gv.Name.Generate(depth))
+	if gv.Value != nil {
+		global.WriteString(" = ")
+		global.WriteString(gv.Value.Generate(depth))
+	}
+
+	global.WriteRune(';')
+	return global.String()
+}
+
+// docWidth is the column where documentation blocks are wrapped
+const docWidth = 80
+
+// DocComment represents a documentation block (/** ... */), long lines are wrapped and new lines are kept
+type DocComment struct {
+	Text string
+}
+
+func (dc *DocComment) decl() {}
+
+// Generate outputs the text in a block with one "*" prefixed line per wrapped line
+func (dc *DocComment) Generate(depth int) string {
+	indent := makeIndent(depth)
+	doc := &strings.Builder{}
+	doc.WriteString(indent)
+	doc.WriteString("/**\n")
+	for _, line := range wrapDoc(dc.Text, docWidth-len(indent)-len(" * ")) {
+		doc.WriteString(indent)
+		doc.WriteString(" *")
+		if line != "" {
+			doc.WriteRune(' ')
+			doc.WriteString(line)
+		}
+		doc.WriteRune('\n')
+	}
+	doc.WriteString(indent)
+	doc.WriteString(" */")
+	return doc.String()
+}
+
+// wrapDoc splits the text on new lines and then wraps each line on word boundaries, words longer than the width
+// are kept whole
+func wrapDoc(text string, width int) []string {
+	lines := make([]string, 0)
+	for _, paragraph := range strings.Split(strings.TrimSpace(text), "\n") {
+		line := &strings.Builder{}
+		for _, word := range strings.Fields(paragraph) {
+			if line.Len() > 0 && line.Len()+1+len(word) > width {
+				lines = append(lines, line.String())
+				line.Reset()
+			}
+
+			if line.Len() > 0 {
+				line.WriteRune(' ')
+			}
+			line.WriteString(word)
+		}
+
+		lines = append(lines, line.String())
+	}
+
+	return lines
+}
+
+// FuncDef represents a function definition, a prototype followed by its body
+type FuncDef struct {
+	Prototype Prototype
+	Body      []Stmt
+}
+
+func (fd *FuncDef) decl() {}
+
+// Generate outputs the prototype and the body wrapped in "{}"
+func (fd *FuncDef) Generate(depth int) string {
+	def := &strings.Builder{}
+	def.WriteString(fd.Prototype.GeneratePrototype(depth))
+	def.WriteString(" {\n")
+	def.WriteString(StmtBlock(fd.Body).GenerateBlock(depth + 1))
+	def.WriteString(makeIndent(depth))
+	def.WriteRune('}')
+	return def.String()
+}
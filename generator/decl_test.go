@@ -0,0 +1,227 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnionDecl_Generate(t *testing.T) {
+	decl := &UnionDecl{Union{
+		Name: mockExpr("u"),
+		Fields: []Field{
+			{Type: mockExpr("int"), Name: mockExpr("x")},
+			{Type: mockExpr("float"), Name: mockExpr("y")},
+		},
+	}}
+
+	actualString := decl.Generate(0)
+	require.Equal(t, "union u {\n  int x;\n  float y;\n};", actualString)
+
+	decl.Union.TagAttrs = []Attr{&GNUAttr{Name: "packed"}}
+	actualString = decl.Generate(0)
+	require.Equal(t, "union __attribute__((packed)) u {\n  int x;\n  float y;\n};", actualString)
+}
+
+func TestEnumDecl_Generate(t *testing.T) {
+	cases := []struct {
+		name           string
+		decl           *EnumDecl
+		depth          int
+		expectedString string
+	}{
+		{
+			name:           "empty enum",
+			decl:           &EnumDecl{Enum{Name: mockExpr("e")}},
+			expectedString: "enum e {};",
+		},
+		{
+			name: "enum with members",
+			decl: &EnumDecl{Enum{
+				Name: mockExpr("e"),
+				Members: []EnumMember{
+					{Name: mockExpr("A")},
+					{Name: mockExpr("B"), Value: mockExpr("2")},
+				},
+			}},
+			expectedString: "enum e {\n  A,\n  B = 2,\n};",
+		},
+		{
+			name: "enum with member comments",
+			decl: &EnumDecl{Enum{
+				Name: mockExpr("e"),
+				Members: []EnumMember{
+					{Name: mockExpr("A"), Comment: "0"},
+					{Name: mockExpr("B"), Value: mockExpr("2"), Comment: "2"},
+					{Name: mockExpr("C")},
+				},
+			}},
+			expectedString: "enum e {\n  A, /* 0 */\n  B = 2, /* 2 */\n  C,\n};",
+		},
+		{
+			name:           "enum with tag attributes",
+			decl:           &EnumDecl{Enum{TagAttrs: []Attr{&GNUAttr{Name: "packed"}}, Name: mockExpr("e")}},
+			expectedString: "enum __attribute__((packed)) e {};",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualString := tt.decl.Generate(tt.depth)
+			require.Equal(t, tt.expectedString, actualString)
+		})
+	}
+}
+
+func TestTypedef_Generate(t *testing.T) {
+	decl := &Typedef{Type: mockExpr("float"), Name: &Subscript{Base: mockExpr("vec2"), Index: mockExpr("2")}}
+	require.Equal(t, "typedef float vec2[2];", decl.Generate(0))
+}
+
+func TestStaticAssert_Generate(t *testing.T) {
+	cases := []struct {
+		name           string
+		decl           *StaticAssert
+		depth          int
+		expectedString string
+	}{
+		{
+			name:           "size assert",
+			decl:           &StaticAssert{Condition: "sizeof(struct s) == 16", Message: "struct s must be 16 bytes"},
+			expectedString: `_Static_assert(sizeof(struct s) == 16, "struct s must be 16 bytes");`,
+		},
+		{
+			name:           "size assert with depth",
+			decl:           &StaticAssert{Condition: "1", Message: "always"},
+			depth:          1,
+			expectedString: `  _Static_assert(1, "always");`,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualString := tt.decl.Generate(tt.depth)
+			require.Equal(t, tt.expectedString, actualString)
+		})
+	}
+}
+
+func TestDefine_Generate(t *testing.T) {
+	cases := []struct {
+		name           string
+		decl           *Define
+		depth          int
+		expectedString string
+	}{
+		{
+			name:           "define with value",
+			decl:           &Define{Name: "S_WIRE_SIZE", Value: mockExpr("12")},
+			expectedString: "#define S_WIRE_SIZE 12",
+		},
+		{
+			name:           "define without value",
+			decl:           &Define{Name: "S_H"},
+			expectedString: "#define S_H",
+		},
+		{
+			name:           "function-like define",
+			decl:           &Define{Name: "S_a", Params: []string{"x"}, Value: mockExpr("((x)->a)")},
+			expectedString: "#define S_a(x) ((x)->a)",
+		},
+		{
+			name:           "function-like define with params",
+			decl:           &Define{Name: "MAX", Params: []string{"a", "b"}, Value: mockExpr("((a) > (b) ? (a) : (b))")},
+			expectedString: "#define MAX(a, b) ((a) > (b) ? (a) : (b))",
+		},
+		{
+			name:           "define is not indented",
+			decl:           &Define{Name: "S_WIRE_SIZE", Value: mockExpr("12")},
+			depth:          1,
+			expectedString: "#define S_WIRE_SIZE 12",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualString := tt.decl.Generate(tt.depth)
+			require.Equal(t, tt.expectedString, actualString)
+		})
+	}
+}
+
+func TestForwardDecl_Generate(t *testing.T) {
+	decl := &ForwardDecl{Tag: "struct", Name: mockExpr("s")}
+	require.Equal(t, "struct s;", decl.Generate(0))
+	require.Equal(t, "  struct s;", decl.Generate(1))
+}
+
+func TestOpaqueDecl_Generate(t *testing.T) {
+	decl := &OpaqueDecl{Tag: "struct", Name: mockExpr("s"), Handle: mockExpr("s_handle")}
+	require.Equal(t, "struct s;\ntypedef struct s* s_handle;", decl.Generate(0))
+	require.Equal(t, "  struct s;\n  typedef struct s* s_handle;", decl.Generate(1))
+}
+
+func TestGlobalVar_Generate(t *testing.T) {
+	cases := []struct {
+		name           string
+		decl           *GlobalVar
+		expectedString string
+	}{
+		{
+			name:           "declaration only",
+			decl:           &GlobalVar{Storage: "extern", Type: mockExpr("int"), Name: mockExpr("x")},
+			expectedString: "extern int x;",
+		},
+		{
+			name: "struct with designated initializer",
+			decl: &GlobalVar{
+				Type: mockExpr("struct s"),
+				Name: mockExpr("x"),
+				Value: &StructInit{Fields: []FieldInit{
+					{Name: "a", Value: mockExpr("1")},
+					{Name: "b", Value: mockExpr("2")},
+				}},
+			},
+			expectedString: "struct s x = {.a = 1, .b = 2};",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualString := tt.decl.Generate(0)
+			require.Equal(t, tt.expectedString, actualString)
+		})
+	}
+}
+
+func TestDocComment_Generate(t *testing.T) {
+	cases := []struct {
+		name           string
+		doc            *DocComment
+		depth          int
+		expectedString string
+	}{
+		{
+			name:           "single line",
+			doc:            &DocComment{Text: "A vector."},
+			expectedString: "/**\n * A vector.\n */",
+		},
+		{
+			name:           "explicit new lines",
+			doc:            &DocComment{Text: "A vector.\n\nUsed everywhere."},
+			depth:          1,
+			expectedString: "  /**\n   * A vector.\n   *\n   * Used everywhere.\n   */",
+		},
+		{
+			name: "long line is wrapped",
+			doc: &DocComment{Text: "A two dimensional vector of single precision floating point numbers, " +
+				"used to represent positions and velocities on the screen."},
+			expectedString: "/**\n" +
+				" * A two dimensional vector of single precision floating point numbers, used to\n" +
+				" * represent positions and velocities on the screen.\n" +
+				" */",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualString := tt.doc.Generate(tt.depth)
+			require.Equal(t, tt.expectedString, actualString)
+		})
+	}
+}
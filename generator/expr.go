@@ -0,0 +1,293 @@
+package generator
+
+import "strings"
+
+// Ident is a plain identifier, used for both type and value names
+type Ident struct {
+	Name string
+}
+
+func (i *Ident) expr() {}
+
+// Generate outputs the identifier as is
+func (i *Ident) Generate(depth int) string {
+	return i.Name
+}
+
+// Literal is a value already written in its C representation (numbers, strings, chars)
+type Literal struct {
+	Value string
+}
+
+func (l *Literal) expr() {}
+
+// Generate outputs the literal as is
+func (l *Literal) Generate(depth int) string {
+	return l.Value
+}
+
+// Paren wraps an expression in parenthesis, macros wrap their parameters and bodies so they expand as a single
+// operand
+type Paren struct {
+	Expr Expr
+}
+
+func (p *Paren) expr() {}
+
+// Generate outputs the expression between parenthesis
+func (p *Paren) Generate(depth int) string {
+	return "(" + p.Expr.Generate(depth) + ")"
+}
+
+// Subscript is an array declarator or index expression (base[index]), index is optional
+type Subscript struct {
+	Base  Expr
+	Index Expr
+}
+
+func (s *Subscript) expr() {}
+
+// Generate outputs the base followed by the index between brackets
+func (s *Subscript) Generate(depth int) string {
+	sub := &strings.Builder{}
+	sub.WriteString(s.Base.Generate(depth))
+	sub.WriteRune('[')
+	if s.Index != nil {
+		sub.WriteString(s.Index.Generate(depth))
+	}
+	sub.WriteRune(']')
+	return sub.String()
+}
+
+// Pointer is a pointer to a type
+type Pointer struct {
+	Type Expr
+}
+
+func (p *Pointer) expr() {}
+
+// Generate outputs the pointed type followed by an asterisk
+func (p *Pointer) Generate(depth int) string {
+	return p.Type.Generate(depth) + "*"
+}
+
+// BitInt is a C23 exact width integer type (_BitInt(N), unsigned _BitInt(N))
+type BitInt struct {
+	Bits     Expr
+	Unsigned bool
+}
+
+func (bi *BitInt) expr() {}
+
+// Generate outputs the type with its width between parenthesis
+func (bi *BitInt) Generate(depth int) string {
+	if bi.Unsigned {
+		return "unsigned _BitInt(" + bi.Bits.Generate(depth) + ")"
+	}
+
+	return "_BitInt(" + bi.Bits.Generate(depth) + ")"
+}
+
+// Const is a const-qualified type
+type Const struct {
+	Type Expr
+}
+
+func (c *Const) expr() {}
+
+// Generate outputs the qualified type
+func (c *Const) Generate(depth int) string {
+	return "const " + c.Type.Generate(depth)
+}
+
+// UnaryOp is a prefix operation over a value
+type UnaryOp struct {
+	Operator string
+	Operand  Expr
+	// Postfix writes the operator after the operand (a++)
+	Postfix bool
+}
+
+func (uo *UnaryOp) expr() {}
+
+// Generate outputs the operator next to the operand, nested operations are wrapped in parenthesis
+func (uo *UnaryOp) Generate(depth int) string {
+	if uo.Postfix {
+		return generateOperand(uo.Operand, depth) + uo.Operator
+	}
+	return uo.Operator + generateOperand(uo.Operand, depth)
+}
+
+// BinaryOp is an infix operation between two values
+type BinaryOp struct {
+	Operator string
+	Left     Expr
+	Right    Expr
+}
+
+func (bo *BinaryOp) expr() {}
+
+// Generate outputs both operands separated by the operator, nested operations are wrapped in parenthesis
+func (bo *BinaryOp) Generate(depth int) string {
+	return generateOperand(bo.Left, depth) + " " + bo.Operator + " " + generateOperand(bo.Right, depth)
+}
+
+func generateOperand(operand Expr, depth int) string {
+	switch operand.(type) {
+	case *BinaryOp, *UnaryOp:
+		return "(" + operand.Generate(depth) + ")"
+	}
+
+	return operand.Generate(depth)
+}
+
+// Call is a function call expression (callee(args))
+type Call struct {
+	Callee Expr
+	Args   []Expr
+}
+
+func (c *Call) expr() {}
+
+// Generate outputs the callee followed by the arguments separated by commas
+func (c *Call) Generate(depth int) string {
+	call := &strings.Builder{}
+	call.WriteString(c.Callee.Generate(depth))
+	call.WriteRune('(')
+	for i, arg := range c.Args {
+		if i != 0 {
+			call.WriteString(", ")
+		}
+		call.WriteString(arg.Generate(depth))
+	}
+	call.WriteRune(')')
+	return call.String()
+}
+
+// Member is a member access expression, either through a value (base.name) or a pointer (base->name)
+type Member struct {
+	Base  Expr
+	Name  string
+	Arrow bool
+}
+
+func (m *Member) expr() {}
+
+// Generate outputs the base and the member name joined by the access operator
+func (m *Member) Generate(depth int) string {
+	if m.Arrow {
+		return m.Base.Generate(depth) + "->" + m.Name
+	}
+
+	return m.Base.Generate(depth) + "." + m.Name
+}
+
+// FuncPointer is a function pointer declarator ((*name)(params)), the return type goes on the field or typedef
+type FuncPointer struct {
+	Name   Expr
+	Params []Param
+}
+
+func (fp *FuncPointer) expr() {}
+
+// Generate outputs the declarator with the parameters separated by commas
+func (fp *FuncPointer) Generate(depth int) string {
+	ptr := &strings.Builder{}
+	ptr.WriteString("(*")
+	ptr.WriteString(fp.Name.Generate(depth))
+	ptr.WriteString(")(")
+	for i, param := range fp.Params {
+		if i != 0 {
+			ptr.WriteString(", ")
+		}
+		ptr.WriteString(param.GenerateParam())
+	}
+	ptr.WriteRune(')')
+	return ptr.String()
+}
+
+// FieldInit is a single designator of a struct initializer (.name = value)
+type FieldInit struct {
+	Name  string
+	Value Expr
+}
+
+// StructInit is a designated initializer ({.a = 1, .b = 2}), without fields it zero-initializes ({0})
+type StructInit struct {
+	Fields []FieldInit
+}
+
+func (si *StructInit) expr() {}
+
+// Generate outputs the designators separated by commas and wrapped on "{}"
+func (si *StructInit) Generate(depth int) string {
+	if len(si.Fields) == 0 {
+		return "{0}"
+	}
+
+	init := &strings.Builder{}
+	init.WriteRune('{')
+	for i, field := range si.Fields {
+		if i != 0 {
+			init.WriteString(", ")
+		}
+		init.WriteRune('.')
+		init.WriteString(field.Name)
+		init.WriteString(" = ")
+		init.WriteString(field.Value.Generate(depth))
+	}
+	init.WriteRune('}')
+	return init.String()
+}
+
+// ArrayInit is an array initializer, each element is written on its own line ({\n  a,\n  b,\n})
+type ArrayInit struct {
+	Elems []Expr
+	// Inline writes the elements on a single line ({a, b}), for short initializers nested in others
+	Inline bool
+}
+
+func (ai *ArrayInit) expr() {}
+
+// Generate outputs the elements wrapped on "{}" with a trailing comma each, indented one level past depth
+func (ai *ArrayInit) Generate(depth int) string {
+	if len(ai.Elems) == 0 {
+		return "{0}"
+	}
+
+	init := &strings.Builder{}
+	if ai.Inline {
+		init.WriteRune('{')
+		for i, elem := range ai.Elems {
+			if i != 0 {
+				init.WriteString(", ")
+			}
+			init.WriteString(elem.Generate(depth))
+		}
+		init.WriteRune('}')
+		return init.String()
+	}
+
+	init.WriteString("{\n")
+	for _, elem := range ai.Elems {
+		init.WriteString(makeIndent(depth + 1))
+		init.WriteString(elem.Generate(depth + 1))
+		init.WriteString(",\n")
+	}
+	init.WriteString(makeIndent(depth))
+	init.WriteRune('}')
+	return init.String()
+}
+
+// IndexInit is a designated element of an array initializer ([RED] = value)
+type IndexInit struct {
+	Index Expr
+	Value Expr
+}
+
+func (ii *IndexInit) expr() {}
+
+// Generate outputs the index between brackets followed by the value
+func (ii *IndexInit) Generate(depth int) string {
+	return "[" + ii.Index.Generate(depth) + "] = " + ii.Value.Generate(depth)
+}
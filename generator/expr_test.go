@@ -0,0 +1,206 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpr_Generate(t *testing.T) {
+	cases := []struct {
+		name           string
+		expr           Expr
+		expectedString string
+	}{
+		{
+			name:           "ident",
+			expr:           &Ident{Name: "x"},
+			expectedString: "x",
+		},
+		{
+			name:           "literal",
+			expr:           &Literal{Value: "0xFF"},
+			expectedString: "0xFF",
+		},
+		{
+			name:           "char literal",
+			expr:           &Literal{Value: "'A'"},
+			expectedString: "'A'",
+		},
+		{
+			name:           "subscript with index",
+			expr:           &Subscript{Base: mockExpr("x"), Index: mockExpr("4")},
+			expectedString: "x[4]",
+		},
+		{
+			name:           "subscript without index",
+			expr:           &Subscript{Base: mockExpr("x")},
+			expectedString: "x[]",
+		},
+		{
+			name:           "pointer",
+			expr:           &Pointer{Type: mockExpr("int")},
+			expectedString: "int*",
+		},
+		{
+			name:           "const",
+			expr:           &Const{Type: &Pointer{Type: mockExpr("char")}},
+			expectedString: "const char*",
+		},
+		{
+			name:           "bit int",
+			expr:           &BitInt{Bits: mockExpr("24")},
+			expectedString: "_BitInt(24)",
+		},
+		{
+			name:           "unsigned bit int",
+			expr:           &BitInt{Bits: mockExpr("24"), Unsigned: true},
+			expectedString: "unsigned _BitInt(24)",
+		},
+		{
+			name:           "unary op",
+			expr:           &UnaryOp{Operator: "-", Operand: mockExpr("1")},
+			expectedString: "-1",
+		},
+		{
+			name:           "postfix unary op",
+			expr:           &UnaryOp{Operator: "++", Operand: &Member{Base: mockExpr("a"), Name: "b"}, Postfix: true},
+			expectedString: "a.b++",
+		},
+		{
+			name: "nested binary op",
+			expr: &BinaryOp{
+				Operator: "*",
+				Left:     &BinaryOp{Operator: "+", Left: mockExpr("1"), Right: mockExpr("2")},
+				Right:    mockExpr("3"),
+			},
+			expectedString: "(1 + 2) * 3",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualString := tt.expr.Generate(0)
+			require.Equal(t, tt.expectedString, actualString)
+		})
+	}
+}
+
+func TestCall_Generate(t *testing.T) {
+	cases := []struct {
+		name           string
+		call           *Call
+		expectedString string
+	}{
+		{
+			name:           "call without args",
+			call:           &Call{Callee: mockExpr("f")},
+			expectedString: "f()",
+		},
+		{
+			name:           "call with args",
+			call:           &Call{Callee: mockExpr("f"), Args: []Expr{mockExpr("a"), mockExpr("b")}},
+			expectedString: "f(a, b)",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualString := tt.call.Generate(0)
+			require.Equal(t, tt.expectedString, actualString)
+		})
+	}
+}
+
+func TestMember_Generate(t *testing.T) {
+	require.Equal(t, "x.a", (&Member{Base: mockExpr("x"), Name: "a"}).Generate(0))
+	require.Equal(t, "x->a", (&Member{Base: mockExpr("x"), Name: "a", Arrow: true}).Generate(0))
+}
+
+func TestParen_Generate(t *testing.T) {
+	require.Equal(t, "((x)->a)", (&Paren{Expr: &Member{Base: &Paren{Expr: mockExpr("x")}, Name: "a", Arrow: true}}).Generate(0))
+}
+
+func TestFuncPointer_Generate(t *testing.T) {
+	ptr := &FuncPointer{
+		Name:   mockExpr("cb"),
+		Params: []Param{{Type: mockExpr("int"), Name: mockExpr("x")}, {Type: mockExpr("void*")}},
+	}
+	require.Equal(t, "(*cb)(int x, void*)", ptr.Generate(0))
+}
+
+func TestStructInit_Generate(t *testing.T) {
+	cases := []struct {
+		name           string
+		init           *StructInit
+		expectedString string
+	}{
+		{
+			name:           "zero initializer",
+			init:           &StructInit{},
+			expectedString: "{0}",
+		},
+		{
+			name:           "single designator",
+			init:           &StructInit{Fields: []FieldInit{{Name: "a", Value: mockExpr("1")}}},
+			expectedString: "{.a = 1}",
+		},
+		{
+			name: "multiple designators",
+			init: &StructInit{Fields: []FieldInit{
+				{Name: "a", Value: mockExpr("1")},
+				{Name: "b", Value: mockExpr("2")},
+			}},
+			expectedString: "{.a = 1, .b = 2}",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualString := tt.init.Generate(0)
+			require.Equal(t, tt.expectedString, actualString)
+		})
+	}
+}
+
+func TestArrayInit_Generate(t *testing.T) {
+	cases := []struct {
+		name           string
+		init           *ArrayInit
+		depth          int
+		expectedString string
+	}{
+		{
+			name:           "zero initializer",
+			init:           &ArrayInit{},
+			expectedString: "{0}",
+		},
+		{
+			name:           "elements on their own lines",
+			init:           &ArrayInit{Elems: []Expr{mockExpr("1"), mockExpr("2")}},
+			expectedString: "{\n  1,\n  2,\n}",
+		},
+		{
+			name:           "inline elements",
+			init:           &ArrayInit{Elems: []Expr{mockExpr("1"), mockExpr("2")}, Inline: true},
+			expectedString: "{1, 2}",
+		},
+		{
+			name: "designated elements",
+			init: &ArrayInit{Elems: []Expr{
+				&IndexInit{Index: mockExpr("RED"), Value: mockExpr("1")},
+				&IndexInit{Index: mockExpr("GREEN"), Value: &ArrayInit{Elems: []Expr{mockExpr("2")}, Inline: true}},
+			}},
+			expectedString: "{\n  [RED] = 1,\n  [GREEN] = {2},\n}",
+		},
+		{
+			name:           "nested initializers",
+			init:           &ArrayInit{Elems: []Expr{&StructInit{Fields: []FieldInit{{Name: "a", Value: mockExpr("1")}}}}},
+			depth:          1,
+			expectedString: "{\n    {.a = 1},\n  }",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualString := tt.init.Generate(tt.depth)
+			require.Equal(t, tt.expectedString, actualString)
+		})
+	}
+}
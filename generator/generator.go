@@ -110,6 +110,45 @@ func (al AttrList) GenerateList() string {
 	return attrs.String()
 }
 
+// Specifier is a plain keyword attribute such storage classes and function specifiers (static, inline)
+type Specifier struct {
+	Name string
+}
+
+func (s *Specifier) attr() {}
+
+// Generate outputs the keyword as is
+func (s *Specifier) Generate(depth int) string {
+	return s.Name
+}
+
+// GNUAttr is a GNU attribute (__attribute__((name(args)))), the parenthesis are omitted without arguments
+type GNUAttr struct {
+	Name string
+	Args []Expr
+}
+
+func (ga *GNUAttr) attr() {}
+
+// Generate outputs the attribute with its arguments separated by commas
+func (ga *GNUAttr) Generate(depth int) string {
+	attr := &strings.Builder{}
+	attr.WriteString("__attribute__((")
+	attr.WriteString(ga.Name)
+	if len(ga.Args) != 0 {
+		attr.WriteRune('(')
+		for i, arg := range ga.Args {
+			if i != 0 {
+				attr.WriteString(", ")
+			}
+			attr.WriteString(arg.Generate(0))
+		}
+		attr.WriteRune(')')
+	}
+	attr.WriteString("))")
+	return attr.String()
+}
+
 // Param represents a param with name and type and optionally attributes
 type Param struct {
 	Attrs []Attr
@@ -172,8 +211,10 @@ func (p *PrototypeDecl) Generate(depth int) string {
 	return p.Prototype.GeneratePrototype(depth) + ";"
 }
 
-// Field represents a field within a struct or union
+// Field represents a field within a struct or union, optionally documented. A field without name is an anonymous
+// member (C11), its type is usually an inline union or struct whose fields are accessed directly.
 type Field struct {
+	Doc   *DocComment
 	Attrs []Attr
 	Type  Expr
 	Name  Expr
@@ -182,11 +223,18 @@ type Field struct {
 // Generate outputs the actual field with indentation
 func (f *Field) GenerateField(depth int) string {
 	field := &strings.Builder{}
+	if f.Doc != nil {
+		field.WriteString(f.Doc.Generate(depth))
+		field.WriteRune('\n')
+	}
+
 	field.WriteString(makeIndent(depth))
 	field.WriteString(AttrList(f.Attrs).GenerateList())
 	field.WriteString(f.Type.Generate(depth))
-	field.WriteRune(' ')
-	field.WriteString(f.Name.Generate(depth))
+	if f.Name != nil {
+		field.WriteRune(' ')
+		field.WriteString(f.Name.Generate(depth))
+	}
 	return field.String()
 }
 
@@ -214,19 +262,22 @@ func (fb FieldBlock) GenerateBlock(depth int) string {
 
 // Struct is an expression that can be used as type
 type Struct struct {
-	Attrs  []Attr
-	Name   Expr
-	Fields []Field
+	Attrs []Attr
+	// TagAttrs apply to the struct type itself, they are written after the keyword (struct __attribute__((x)) s)
+	TagAttrs []Attr
+	Name     Expr
+	Fields   []Field
 }
 
 func (s *Struct) expr() {}
 
-// Generate returns the equivalent code for a structure with fields
+// Generate returns the equivalent code for a structure with fields, the first line is not indented so it can be
+// embedded as a type (StructDecl indents it)
 func (s *Struct) Generate(depth int) string {
 	strct := &strings.Builder{}
-	strct.WriteString(makeIndent(depth))
 	strct.WriteString(AttrList(s.Attrs).GenerateList())
 	strct.WriteString("struct ")
+	strct.WriteString(AttrList(s.TagAttrs).GenerateList())
 	if s.Name != nil {
 		strct.WriteString(s.Name.Generate(depth))
 		strct.WriteRune(' ')
@@ -244,7 +295,7 @@ func (sd *StructDecl) decl() {}
 
 // Generates the struct expr with a trailing semicolon
 func (sd *StructDecl) Generate(depth int) string {
-	return sd.Struct.Generate(depth) + ";"
+	return makeIndent(depth) + sd.Struct.Generate(depth) + ";"
 }
 
 func makeIndent(depth int) string {
@@ -173,6 +173,40 @@ func TestAttrList_GenerateList(t *testing.T) {
 	}
 }
 
+func TestSpecifier_Generate(t *testing.T) {
+	attrs := AttrList{&Specifier{Name: "static"}, &Specifier{Name: "inline"}}
+	require.Equal(t, "static inline ", attrs.GenerateList())
+}
+
+func TestGNUAttr_Generate(t *testing.T) {
+	cases := []struct {
+		name           string
+		attr           *GNUAttr
+		expectedString string
+	}{
+		{
+			name:           "without arguments",
+			attr:           &GNUAttr{Name: "deprecated"},
+			expectedString: "__attribute__((deprecated))",
+		},
+		{
+			name:           "with a message",
+			attr:           &GNUAttr{Name: "deprecated", Args: []Expr{mockExpr(`"use t"`)}},
+			expectedString: `__attribute__((deprecated("use t")))`,
+		},
+		{
+			name:           "with several arguments",
+			attr:           &GNUAttr{Name: "format", Args: []Expr{mockExpr("printf"), mockExpr("1"), mockExpr("2")}},
+			expectedString: "__attribute__((format(printf, 1, 2)))",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expectedString, tt.attr.Generate(0))
+		})
+	}
+}
+
 func TestParam_GenerateParam(t *testing.T) {
 	cases := []struct {
 		name           string
@@ -333,6 +367,42 @@ func TestField_Generate(t *testing.T) {
 			depth:          1,
 			expectedString: "  __attr__ int x",
 		},
+		{
+			name: "anonymous enum field",
+			field: &Field{
+				Type: &Enum{Members: []EnumMember{{Name: mockExpr("A")}, {Name: mockExpr("B")}}},
+				Name: mockExpr("mode"),
+			},
+			depth:          1,
+			expectedString: "  enum {\n    A,\n    B,\n  } mode",
+		},
+		{
+			name: "anonymous struct field",
+			field: &Field{
+				Type: &Struct{Fields: []Field{{Type: mockExpr("int"), Name: mockExpr("x")}}},
+				Name: mockExpr("p"),
+			},
+			depth:          1,
+			expectedString: "  struct {\n    int x;\n  } p",
+		},
+		{
+			name: "anonymous union member",
+			field: &Field{
+				Type: &Union{Fields: []Field{{Type: mockExpr("int"), Name: mockExpr("i")}, {Type: mockExpr("float"), Name: mockExpr("f")}}},
+			},
+			depth:          1,
+			expectedString: "  union {\n    int i;\n    float f;\n  }",
+		},
+		{
+			name: "documented field",
+			field: &Field{
+				Doc:  &DocComment{Text: "horizontal position"},
+				Type: mockExpr("int"),
+				Name: mockExpr("x"),
+			},
+			depth:          1,
+			expectedString: "  /**\n   * horizontal position\n   */\n  int x",
+		},
 	}
 	for _, tt := range cases {
 		t.Run(tt.name, func(t *testing.T) {
@@ -432,6 +502,27 @@ func TestStruct_Generate(t *testing.T) {
 			depth:          0,
 			expectedString: "struct s {}",
 		},
+		{
+			name: "struct with tag attributes",
+			decl: &Struct{
+				TagAttrs: []Attr{&GNUAttr{Name: "deprecated"}},
+				Name:     mockExpr("s"),
+			},
+			depth:          0,
+			expectedString: "struct __attribute__((deprecated)) s {}",
+		},
+		{
+			name: "struct with anonymous union member",
+			decl: &Struct{
+				Name: mockExpr("value"),
+				Fields: []Field{
+					{Type: mockExpr("int"), Name: mockExpr("kind")},
+					{Type: &Union{Fields: []Field{{Type: mockExpr("int"), Name: mockExpr("i")}, {Type: mockExpr("float"), Name: mockExpr("f")}}}},
+				},
+			},
+			depth:          0,
+			expectedString: "struct value {\n  int kind;\n  union {\n    int i;\n    float f;\n  };\n}",
+		},
 		{
 			name: "struct with name with single field",
 			decl: &Struct{
@@ -464,6 +555,24 @@ func TestStruct_Generate(t *testing.T) {
 			depth:          0,
 			expectedString: "struct s {\n  int x;\n  int y;\n}",
 		},
+		{
+			name: "struct ending in a flexible array",
+			decl: &Struct{
+				Name: mockExpr("s"),
+				Fields: []Field{
+					{
+						Type: mockExpr("usize"),
+						Name: mockExpr("len"),
+					},
+					{
+						Type: mockExpr("int"),
+						Name: &Subscript{Base: mockExpr("data")},
+					},
+				},
+			},
+			depth:          0,
+			expectedString: "struct s {\n  usize len;\n  int data[];\n}",
+		},
 	}
 	for _, tt := range cases {
 		t.Run(tt.name, func(t *testing.T) {
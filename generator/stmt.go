@@ -0,0 +1,124 @@
+package generator
+
+import "strings"
+
+// Stmt represents any statement within a function body
+type Stmt interface {
+	Generator
+	stmt()
+}
+
+// StmtBlock is a list of statements
+type StmtBlock []Stmt
+
+// GenerateBlock outputs each statement on its own line, every line ends with a new line
+func (sb StmtBlock) GenerateBlock(depth int) string {
+	block := &strings.Builder{}
+	for _, stmt := range sb {
+		block.WriteString(stmt.Generate(depth))
+		block.WriteRune('\n')
+	}
+	return block.String()
+}
+
+// ExprStmt is an expression evaluated as a statement
+type ExprStmt struct {
+	Expr Expr
+}
+
+func (es *ExprStmt) stmt() {}
+
+// Generate outputs the expression with a trailing semicolon
+func (es *ExprStmt) Generate(depth int) string {
+	return makeIndent(depth) + es.Expr.Generate(depth) + ";"
+}
+
+// LocalVar declares a variable within a function body, optionally initialized
+type LocalVar struct {
+	Type  Expr
+	Name  Expr
+	Value Expr
+}
+
+func (lv *LocalVar) stmt() {}
+
+// Generate outputs the declaration with a trailing semicolon
+func (lv *LocalVar) Generate(depth int) string {
+	local := makeIndent(depth) + lv.Type.Generate(depth) + " " + lv.Name.Generate(depth)
+	if lv.Value != nil {
+		local += " = " + lv.Value.Generate(depth)
+	}
+
+	return local + ";"
+}
+
+// Return is a return statement with an optional value
+type Return struct {
+	Value Expr
+}
+
+func (r *Return) stmt() {}
+
+// Generate outputs the return statement
+func (r *Return) Generate(depth int) string {
+	if r.Value == nil {
+		return makeIndent(depth) + "return;"
+	}
+
+	return makeIndent(depth) + "return " + r.Value.Generate(depth) + ";"
+}
+
+// Break is a break statement
+type Break struct{}
+
+func (b *Break) stmt() {}
+
+// Generate outputs the break statement
+func (b *Break) Generate(depth int) string {
+	return makeIndent(depth) + "break;"
+}
+
+// Case is a single switch case, a nil value means the default case
+type Case struct {
+	Value Expr
+	Body  []Stmt
+}
+
+// GenerateCase outputs the label followed by its body indented one level
+func (c *Case) GenerateCase(depth int) string {
+	label := &strings.Builder{}
+	label.WriteString(makeIndent(depth))
+	if c.Value == nil {
+		label.WriteString("default:\n")
+	} else {
+		label.WriteString("case ")
+		label.WriteString(c.Value.Generate(depth))
+		label.WriteString(":\n")
+	}
+
+	label.WriteString(StmtBlock(c.Body).GenerateBlock(depth + 1))
+	return label.String()
+}
+
+// Switch is a switch statement, labels are written at the same depth as the switch
+type Switch struct {
+	Value Expr
+	Cases []Case
+}
+
+func (s *Switch) stmt() {}
+
+// Generate outputs the switch with all of its cases
+func (s *Switch) Generate(depth int) string {
+	sw := &strings.Builder{}
+	sw.WriteString(makeIndent(depth))
+	sw.WriteString("switch (")
+	sw.WriteString(s.Value.Generate(depth))
+	sw.WriteString(") {\n")
+	for _, cs := range s.Cases {
+		sw.WriteString(cs.GenerateCase(depth))
+	}
+	sw.WriteString(makeIndent(depth))
+	sw.WriteRune('}')
+	return sw.String()
+}
@@ -0,0 +1,79 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStmt_Generate(t *testing.T) {
+	cases := []struct {
+		name           string
+		stmt           Stmt
+		depth          int
+		expectedString string
+	}{
+		{
+			name:           "expression statement",
+			stmt:           &ExprStmt{Expr: mockExpr("f()")},
+			depth:          1,
+			expectedString: "  f();",
+		},
+		{
+			name:           "local variable",
+			stmt:           &LocalVar{Type: mockExpr("int"), Name: mockExpr("value")},
+			depth:          1,
+			expectedString: "  int value;",
+		},
+		{
+			name:           "initialized local variable",
+			stmt:           &LocalVar{Type: mockExpr("int"), Name: mockExpr("value"), Value: mockExpr("0")},
+			expectedString: "int value = 0;",
+		},
+		{
+			name:           "empty return",
+			stmt:           &Return{},
+			expectedString: "return;",
+		},
+		{
+			name:           "return with value",
+			stmt:           &Return{Value: mockExpr("x")},
+			expectedString: "return x;",
+		},
+		{
+			name:           "break",
+			stmt:           &Break{},
+			expectedString: "break;",
+		},
+		{
+			name: "switch with cases and default",
+			stmt: &Switch{
+				Value: mockExpr("tag"),
+				Cases: []Case{
+					{Value: mockExpr("A"), Body: []Stmt{&ExprStmt{Expr: mockExpr("a()")}, &Break{}}},
+					{Body: []Stmt{&Break{}}},
+				},
+			},
+			depth:          1,
+			expectedString: "  switch (tag) {\n  case A:\n    a();\n    break;\n  default:\n    break;\n  }",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualString := tt.stmt.Generate(tt.depth)
+			require.Equal(t, tt.expectedString, actualString)
+		})
+	}
+}
+
+func TestFuncDef_Generate(t *testing.T) {
+	def := &FuncDef{
+		Prototype: Prototype{
+			Type:   mockExpr("int"),
+			Name:   mockExpr("get"),
+			Params: []Param{{Type: mockExpr("int"), Name: mockExpr("x")}},
+		},
+		Body: []Stmt{&Return{Value: mockExpr("x")}},
+	}
+	require.Equal(t, "int get(int x) {\n  return x;\n}", def.Generate(0))
+}
@@ -0,0 +1,183 @@
+package generator
+
+// WalkGen visits a node and all of its children, depth first. The visit function is called on each node before
+// its children and returns the node replacing it, the children of the returned node are walked next. Returning
+// the same node keeps it, replacements must be of the same kind (a Decl for a Decl, an Expr for an Expr...).
+//
+// Fields, parameters and enum members are not nodes on their own, their parts are walked and they can be
+// rewritten through their parent (a Struct, a Prototype or an Enum). Returns the replacement of the root node.
+func WalkGen(node Generator, visit func(Generator) Generator) Generator {
+	w := walker{visit: visit}
+	return w.walk(node)
+}
+
+type walker struct {
+	visit func(Generator) Generator
+}
+
+func (w walker) walk(node Generator) Generator {
+	if node == nil {
+		return nil
+	}
+
+	node = w.visit(node)
+	switch n := node.(type) {
+	case *File:
+		w.decls(n.Decls)
+	case *ModuleWard:
+		w.decls(n.Decls)
+	case *StructDecl:
+		n.Struct = *w.walk(&n.Struct).(*Struct)
+	case *UnionDecl:
+		n.Union = *w.walk(&n.Union).(*Union)
+	case *EnumDecl:
+		n.Enum = *w.walk(&n.Enum).(*Enum)
+	case *Typedef:
+		n.Type = w.expr(n.Type)
+		n.Name = w.expr(n.Name)
+	case *Define:
+		n.Value = w.expr(n.Value)
+	case *ForwardDecl:
+		n.Name = w.expr(n.Name)
+	case *OpaqueDecl:
+		n.Name = w.expr(n.Name)
+		n.Handle = w.expr(n.Handle)
+	case *GlobalVar:
+		w.attrs(n.Attrs)
+		n.Type = w.expr(n.Type)
+		n.Name = w.expr(n.Name)
+		n.Value = w.expr(n.Value)
+	case *PrototypeDecl:
+		w.prototype(&n.Prototype)
+	case *FuncDef:
+		w.prototype(&n.Prototype)
+		w.stmts(n.Body)
+	case *Struct:
+		w.attrs(n.Attrs)
+		w.attrs(n.TagAttrs)
+		n.Name = w.expr(n.Name)
+		w.fields(n.Fields)
+	case *Union:
+		w.attrs(n.Attrs)
+		w.attrs(n.TagAttrs)
+		n.Name = w.expr(n.Name)
+		w.fields(n.Fields)
+	case *Enum:
+		w.attrs(n.Attrs)
+		w.attrs(n.TagAttrs)
+		n.Name = w.expr(n.Name)
+		for i := range n.Members {
+			n.Members[i].Name = w.expr(n.Members[i].Name)
+			n.Members[i].Value = w.expr(n.Members[i].Value)
+		}
+	case *Subscript:
+		n.Base = w.expr(n.Base)
+		n.Index = w.expr(n.Index)
+	case *Pointer:
+		n.Type = w.expr(n.Type)
+	case *Const:
+		n.Type = w.expr(n.Type)
+	case *BitInt:
+		n.Bits = w.expr(n.Bits)
+	case *UnaryOp:
+		n.Operand = w.expr(n.Operand)
+	case *BinaryOp:
+		n.Left = w.expr(n.Left)
+		n.Right = w.expr(n.Right)
+	case *Call:
+		n.Callee = w.expr(n.Callee)
+		for i := range n.Args {
+			n.Args[i] = w.expr(n.Args[i])
+		}
+	case *Member:
+		n.Base = w.expr(n.Base)
+	case *Paren:
+		n.Expr = w.expr(n.Expr)
+	case *FuncPointer:
+		n.Name = w.expr(n.Name)
+		w.params(n.Params)
+	case *StructInit:
+		for i := range n.Fields {
+			n.Fields[i].Value = w.expr(n.Fields[i].Value)
+		}
+	case *ArrayInit:
+		for i := range n.Elems {
+			n.Elems[i] = w.expr(n.Elems[i])
+		}
+	case *IndexInit:
+		n.Index = w.expr(n.Index)
+		n.Value = w.expr(n.Value)
+	case *GNUAttr:
+		for i := range n.Args {
+			n.Args[i] = w.expr(n.Args[i])
+		}
+	case *ExprStmt:
+		n.Expr = w.expr(n.Expr)
+	case *LocalVar:
+		n.Type = w.expr(n.Type)
+		n.Name = w.expr(n.Name)
+		n.Value = w.expr(n.Value)
+	case *Return:
+		n.Value = w.expr(n.Value)
+	case *Switch:
+		n.Value = w.expr(n.Value)
+		for i := range n.Cases {
+			n.Cases[i].Value = w.expr(n.Cases[i].Value)
+			w.stmts(n.Cases[i].Body)
+		}
+	}
+
+	return node
+}
+
+func (w walker) expr(expr Expr) Expr {
+	if expr == nil {
+		return nil
+	}
+
+	return w.walk(expr).(Expr)
+}
+
+func (w walker) decls(decls []Decl) {
+	for i := range decls {
+		decls[i] = w.walk(decls[i]).(Decl)
+	}
+}
+
+func (w walker) attrs(attrs []Attr) {
+	for i := range attrs {
+		attrs[i] = w.walk(attrs[i]).(Attr)
+	}
+}
+
+func (w walker) stmts(stmts []Stmt) {
+	for i := range stmts {
+		stmts[i] = w.walk(stmts[i]).(Stmt)
+	}
+}
+
+func (w walker) fields(fields []Field) {
+	for i := range fields {
+		if fields[i].Doc != nil {
+			fields[i].Doc = w.walk(fields[i].Doc).(*DocComment)
+		}
+		w.attrs(fields[i].Attrs)
+		fields[i].Type = w.expr(fields[i].Type)
+		fields[i].Name = w.expr(fields[i].Name)
+	}
+}
+
+func (w walker) params(params []Param) {
+	for i := range params {
+		w.attrs(params[i].Attrs)
+		params[i].Type = w.expr(params[i].Type)
+		params[i].Name = w.expr(params[i].Name)
+	}
+}
+
+func (w walker) prototype(proto *Prototype) {
+	w.attrs(proto.Attrs)
+	proto.Type = w.expr(proto.Type)
+	proto.Name = w.expr(proto.Name)
+	w.params(proto.Params)
+}
@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalkGen_InjectPacked(t *testing.T) {
+	file := &File{
+		Decls: []Decl{
+			&Include{File: "stdint.h"},
+			&ModuleWard{
+				Name: "TEST_H",
+				Decls: []Decl{
+					&StructDecl{Struct: Struct{
+						Name: mockExpr("point"),
+						Fields: []Field{
+							{Type: mockExpr("int"), Name: mockExpr("x")},
+							{
+								Type: &Struct{Fields: []Field{{Type: mockExpr("int"), Name: mockExpr("y")}}},
+								Name: mockExpr("inner"),
+							},
+						},
+					}},
+					&Typedef{Type: mockExpr("int"), Name: mockExpr("size")},
+				},
+			},
+		},
+	}
+
+	visited := 0
+	result := WalkGen(file, func(node Generator) Generator {
+		if s, ok := node.(*Struct); ok {
+			s.TagAttrs = append(s.TagAttrs, &GNUAttr{Name: "packed"})
+			visited++
+		}
+		return node
+	})
+
+	require.Same(t, file, result)
+	require.Equal(t, 2, visited)
+	require.Equal(t, "#include <stdint.h>\n"+
+		"#ifndef TEST_H\n"+
+		"#define TEST_H\n"+
+		"struct __attribute__((packed)) point {\n"+
+		"  int x;\n"+
+		"  struct __attribute__((packed)) {\n"+
+		"    int y;\n"+
+		"  } inner;\n"+
+		"};\n"+
+		"typedef int size;\n"+
+		"#endif /* TEST_H */\n\n", result.Generate(0))
+}
+
+func TestWalkGen_ReplaceNodes(t *testing.T) {
+	decl := &PrototypeDecl{Prototype: Prototype{
+		Type: &Pointer{Type: &Ident{Name: "old_t"}},
+		Name: &Ident{Name: "make"},
+		Params: []Param{
+			{Type: &Ident{Name: "old_t"}, Name: &Ident{Name: "from"}},
+		},
+	}}
+
+	result := WalkGen(decl, func(node Generator) Generator {
+		if ident, ok := node.(*Ident); ok && ident.Name == "old_t" {
+			return &Ident{Name: "new_t"}
+		}
+		return node
+	})
+
+	require.Equal(t, "new_t* make(new_t from);", result.Generate(0))
+}
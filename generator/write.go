@@ -0,0 +1,56 @@
+package generator
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// WriteTo streams the code of each declaration followed by a new line, the output is the same as Generate(0)
+// without building the whole file in memory
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	written := int64(0)
+	for _, decl := range f.Decls {
+		n, err := io.WriteString(w, decl.Generate(0)+"\n")
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
+
+// WriteFile writes the file to path creating its parent directories. The content is written into a temporary file
+// next to path which then replaces it, so readers never see a partially written file.
+func WriteFile(path string, f *File) error {
+	dir := filepath.Dir(path)
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // fails once renamed
+
+	buffered := bufio.NewWriter(tmp)
+	_, err = f.WriteTo(buffered)
+	if err == nil {
+		err = buffered.Flush()
+	}
+	if err == nil {
+		err = tmp.Chmod(0o644)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
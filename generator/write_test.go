@@ -0,0 +1,47 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFile_WriteTo(t *testing.T) {
+	file := &File{Decls: []Decl{mockDecl("int a;"), mockDecl("int b;")}}
+
+	contents := &strings.Builder{}
+	n, err := file.WriteTo(contents)
+	require.NoError(t, err)
+	require.Equal(t, file.Generate(0), contents.String())
+	require.Equal(t, int64(len("int a;\nint b;\n")), n)
+}
+
+func TestWriteFile(t *testing.T) {
+	file := &File{Decls: []Decl{
+		&Include{File: "stdint.h"},
+		&StructDecl{Struct: Struct{Name: mockExpr("s"), Fields: []Field{{Type: mockExpr("int"), Name: mockExpr("a")}}}},
+	}}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "include", "schema", "s.h")
+	require.NoError(t, WriteFile(path, file))
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "#include <stdint.h>\nstruct s {\n  int a;\n};\n", string(contents))
+
+	// overwrites the previous output and leaves no temporary files behind
+	file.Decls = file.Decls[:1]
+	require.NoError(t, WriteFile(path, file))
+
+	contents, err = os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "#include <stdint.h>\n", string(contents))
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
@@ -0,0 +1,49 @@
+// Package keywords lists the reserved words and the builtin type names of the schema language, it is shared by
+// the lexer and the analyzer so both classify a word in the same way
+package keywords
+
+import "slices"
+
+// Class tells what a word means to the language before any declaration is taken into account
+type Class int
+
+const (
+	ClassName    Class = iota // ClassName a plain name, declarations may use it
+	ClassKeyword              // ClassKeyword a reserved word introducing a declaration or a construct
+	ClassBuiltin              // ClassBuiltin a builtin type name, declarations with the same name shadow it
+)
+
+// Keywords are the reserved words, kept sorted
+var Keywords = []string{
+	"const", "enum", "import", "in", "include", "module", "options", "package", "proc", "struct", "type", "union",
+	"where",
+}
+
+// Builtins are the type names every schema can use without declaring them
+var Builtins = []string{
+	"void", "char", "string", "int", "uint", "float", "double", "f32", "f64", "bool", "byte",
+	"i8", "i16", "i32", "i64", "u8", "u16", "u32", "u64", "usize", "isize",
+}
+
+// IsKeyword reports whether the word is reserved
+func IsKeyword(word string) bool {
+	_, found := slices.BinarySearch(Keywords, word)
+	return found
+}
+
+// IsBuiltin reports whether the word is a builtin type name
+func IsBuiltin(word string) bool {
+	return slices.Contains(Builtins, word)
+}
+
+// Classify returns the class of a word
+func Classify(word string) Class {
+	switch {
+	case IsKeyword(word):
+		return ClassKeyword
+	case IsBuiltin(word):
+		return ClassBuiltin
+	}
+
+	return ClassName
+}
@@ -0,0 +1,37 @@
+package keywords_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/cedmundo/SimpleSchema/keywords"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		name          string
+		input         string
+		expectedClass keywords.Class
+	}{
+		{name: "keyword", input: "struct", expectedClass: keywords.ClassKeyword},
+		{name: "declaration keyword", input: "options", expectedClass: keywords.ClassKeyword},
+		{name: "builtin type", input: "u32", expectedClass: keywords.ClassBuiltin},
+		{name: "string builtin", input: "string", expectedClass: keywords.ClassBuiltin},
+		{name: "plain name", input: "vec2", expectedClass: keywords.ClassName},
+		{name: "case matters", input: "Struct", expectedClass: keywords.ClassName},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expectedClass, keywords.Classify(tt.input))
+		})
+	}
+}
+
+func TestKeywords_Sorted(t *testing.T) {
+	// IsKeyword searches them with a binary search
+	require.True(t, slices.IsSorted(keywords.Keywords))
+	for _, builtin := range keywords.Builtins {
+		require.False(t, keywords.IsKeyword(builtin), builtin)
+	}
+}
@@ -2,12 +2,16 @@
 package lexer
 
 import (
+	"bufio"
+	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"slices"
 	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 var (
@@ -23,19 +27,33 @@ var (
 	// ErrUnterminatedStringLiteral represents an error that occurs when a string literal is not properly closed before the end of the line.
 	ErrUnterminatedStringLiteral = errors.New("unterminated string literal")
 
+	// ErrMalformedCharLiteral represents an error that occurs when a character literal is empty, holds more than one character or is not closed.
+	ErrMalformedCharLiteral = errors.New("malformed character literal")
+
 	// ErrMalformedEscapeSequence indicates that an escape sequence in a string or character literal is not recognized or properly formatted.
 	ErrMalformedEscapeSequence = errors.New("malformed escape sequence")
 
 	// ErrAlreadyUnread indicates an attempt to mark a token as unread when there is already an existing unread token.
 	ErrAlreadyUnread = errors.New("token is already unread")
 
+	// ErrMalformedIdentifier indicates that an escaped identifier is empty or its closing backtick is missing before the end of the line.
+	ErrMalformedIdentifier = errors.New("malformed escaped identifier")
+
+	// ErrTokenTooLong indicates that a word, number or string is longer than the maximum token length of the lexer.
+	ErrTokenTooLong = errors.New("token too long")
+
+	// ErrMalformedBytesLiteral represents an error that occurs when a byte string literal holds anything but hex digits or is not closed.
+	ErrMalformedBytesLiteral = errors.New("malformed byte string literal")
+
 	// ErrUnbalancedGroup indicates that the grouping is not valid (there are more closes than opens)
 	ErrUnbalancedGroup = errors.New("unbalanced group")
 
+	// punctuations are matched by extending the current token while it is still in the list, so every prefix of
+	// a punctuation must be listed as well (".." is required to reach "...")
 	punctuations = []string{
 		"(", ")", "[", "]", "{", "}", ",", ".", ":", "=", "+", "-", "*", "/", "%",
 		">", "<", "^", "~", "!", "|", "&", ":=", "==", "!=", ">=", "<=",
-		">>", "<<", "&&", "||", "=>", "->", "[[", "]]",
+		">>", "<<", "&&", "||", "=>", "->", "[[", "]]", "@", "..", "...", "**", "++", "--", "?",
 	}
 )
 
@@ -48,27 +66,79 @@ type Lexer struct {
 	reader   io.RuneReader
 	unread   *Token
 	group    int
+	offset   int64
+
+	// maxTokenLength bounds the bytes of words, numbers and strings, see SetMaxTokenLength
+	maxTokenLength int
+
+	// dollarIdents accepts "$" as an identifier character, see AllowDollarIdents
+	dollarIdents bool
+
+	// whitespace emits the spaces between tokens instead of skipping them, see KeepWhitespace
+	whitespace bool
+
+	// skipBlankLines skips end of lines in ReadSignificant, see SkipBlankLines
+	skipBlankLines bool
+
+	// recovering skips invalid characters and keeps their errors, see RecoverErrors
+	recovering bool
+	errors     []error
+
+	// peek is the rune read ahead of current, see peekRune
+	peek     rune
+	peekSize int
+	peekErr  error
+	peeked   bool
+
+	// value accumulates the text of the token being classified, it is reset (keeping its memory) per token
+	value bytes.Buffer
+
+	// tokenRow is the row of the last token other than spaces, end of lines and comments, rowHasToken is cleared
+	// when its line ends. They tell trailing comments apart, see Token.Trailing
+	tokenRow    int
+	rowHasToken bool
 }
 
+// DefaultMaxTokenLength is the maximum token length of new lexers (1 MiB)
+const DefaultMaxTokenLength = 1 << 20
+
 type tryReadFn func() (Token, error)
 
 // New returns a new lexer using a rune reader
 func New(file string, reader io.RuneReader) *Lexer {
 	loc := Location{File: file}
 	return &Lexer{
-		reader:   reader,
-		startLoc: loc,
-		endLoc:   loc,
+		reader:         reader,
+		startLoc:       loc,
+		endLoc:         loc,
+		maxTokenLength: DefaultMaxTokenLength,
 	}
 }
 
+// NewFromReader returns a lexer reading from a plain reader (like a file), the reader is buffered unless it already
+// reads runes on its own
+func NewFromReader(file string, reader io.Reader) *Lexer {
+	if runeReader, ok := reader.(io.RuneReader); ok {
+		return New(file, runeReader)
+	}
+
+	return New(file, bufio.NewReader(reader))
+}
+
 // NewFromString returns a lexer using a string content
 func NewFromString(file, content string) *Lexer {
 	return New(file, strings.NewReader(content))
 }
 
 func (l *Lexer) advanceRune() (err error) {
-	l.current, _, err = l.reader.ReadRune()
+	size := 0
+	if l.peeked {
+		l.current, size, err = l.peek, l.peekSize, l.peekErr
+		l.peeked = false
+	} else {
+		l.current, size, err = l.reader.ReadRune()
+	}
+	l.offset += int64(size)
 	if errors.Is(err, io.EOF) {
 		l.consumed = true
 		return nil
@@ -82,8 +152,24 @@ func (l *Lexer) advanceRune() (err error) {
 	return err
 }
 
+// peekRune returns the rune following the current one without consuming it (0 at the end of the input), numbers
+// need it to tell a range (0..1) apart from a decimal point. Reading errors are returned once the rune is consumed.
+func (l *Lexer) peekRune() rune {
+	if !l.peeked {
+		l.peek, l.peekSize, l.peekErr = l.reader.ReadRune()
+		l.peeked = true
+	}
+
+	return l.peek
+}
+
+// isSpace reports whether the current rune is skipped between tokens, new lines are only skipped within groups
+func (l *Lexer) isSpace() bool {
+	return l.current == ' ' || l.current == '\t' || (l.group != 0 && unicode.IsSpace(l.current))
+}
+
 func (l *Lexer) skipSpaces() error {
-	for l.current == ' ' || l.current == '\t' || (l.group != 0 && unicode.IsSpace(l.current)) {
+	for l.isSpace() {
 		l.startLoc.Col += 1
 		if l.current == '\n' {
 			l.startLoc.Col = 0
@@ -107,6 +193,37 @@ func (l *Lexer) tryReadEOF() (Token, error) {
 	return Token{}, ErrInvalidCharacter
 }
 
+func (l *Lexer) tryReadWhitespace() (Token, error) {
+	if !l.whitespace || !l.isSpace() {
+		return Token{}, ErrInvalidCharacter
+	}
+
+	start := l.startLoc
+	value := l.resetValue()
+	end := start
+	for l.isSpace() {
+		end.Col += 1
+		if l.current == '\n' {
+			end.Col = 0
+			end.Row += 1
+		}
+
+		value.WriteRune(l.current)
+		err := l.advanceRune()
+		if err != nil {
+			return Token{}, err
+		}
+	}
+
+	// the last rune may be the end of file, which does not move the end location
+	l.endLoc = end
+	return Token{
+		Tag:   TokenTagWhitespace,
+		Loc:   start,
+		Value: value.String(),
+	}, nil
+}
+
 func (l *Lexer) tryReadEOL() (Token, error) {
 	if l.group != 0 || (l.current != '\n' && l.current != ';') {
 		return Token{}, ErrInvalidCharacter
@@ -148,6 +265,14 @@ func (l *Lexer) tryReadComment() (Token, error) {
 		if err != nil {
 			return Token{}, err
 		}
+
+		// a line directive renames the location of the following line, but the directive itself is a comment
+		if row, file, ok := parseLineDirective(value.String()); ok {
+			l.endLoc.Row = row
+			if file != "" {
+				l.endLoc.File = file
+			}
+		}
 	}
 
 	return Token{
@@ -165,7 +290,7 @@ func (l *Lexer) tryReadNumber() (Token, error) {
 	tag := TokenTagDecInt
 	start := l.startLoc
 	haveExp := false
-	value := strings.Builder{}
+	value := l.resetValue()
 
 	if l.current == '0' {
 		skip := true
@@ -182,6 +307,13 @@ func (l *Lexer) tryReadNumber() (Token, error) {
 		case 'x':
 			tag = TokenTagHexInt
 		case '.':
+			// 0..n is a range starting at zero
+			if l.peekRune() == '.' {
+				skip = false
+				value.WriteRune('0')
+				break
+			}
+
 			tag = TokenTagFloat
 			value.WriteString("0.")
 		default:
@@ -195,17 +327,31 @@ func (l *Lexer) tryReadNumber() (Token, error) {
 				return Token{}, err
 			}
 		}
+
+		if tag == TokenTagHexInt && l.current == '[' {
+			return l.readBytes(start)
+		}
 	}
 
 	for {
 		for isDigitOfBase(l.current, tag) {
 			value.WriteRune(l.current)
-			err := l.advanceRune()
+			err := l.checkLength(start)
+			if err != nil {
+				return Token{}, err
+			}
+
+			err = l.advanceRune()
 			if err != nil {
 				return Token{}, err
 			}
 		}
 
+		// a range (1..2) ends the number before its punctuation
+		if l.current == '.' && l.peekRune() == '.' {
+			break
+		}
+
 		if l.current == '.' && tag == TokenTagDecInt {
 			value.WriteRune(l.current)
 			err := l.advanceRune()
@@ -256,13 +402,51 @@ func (l *Lexer) tryReadNumber() (Token, error) {
 
 }
 
+// readBytes reads the hex digits of a byte string literal (0x[DEADBEEF]) after its prefix, the digits are kept as
+// written and decoded by the parser
+func (l *Lexer) readBytes(start Location) (Token, error) {
+	value := l.resetValue()
+	err := l.advanceRune()
+	if err != nil {
+		return Token{}, err
+	}
+
+	for isDigitOfBase(l.current, TokenTagHexInt) {
+		value.WriteRune(l.current)
+		err = l.checkLength(start)
+		if err != nil {
+			return Token{}, err
+		}
+
+		err = l.advanceRune()
+		if err != nil {
+			return Token{}, err
+		}
+	}
+
+	if l.consumed || l.current != ']' {
+		return Token{}, ErrMalformedBytesLiteral
+	}
+
+	err = l.advanceRune()
+	if err != nil {
+		return Token{}, err
+	}
+
+	return Token{
+		Tag:   TokenTagBytes,
+		Loc:   start,
+		Value: value.String(),
+	}, nil
+}
+
 func (l *Lexer) tryReadString() (Token, error) {
 	if l.current != '"' {
 		return Token{}, ErrInvalidCharacter
 	}
 
 	start := l.startLoc
-	value := strings.Builder{}
+	value := l.resetValue()
 
 	for l.current != '\n' && l.current != 0 {
 		err := l.advanceRune()
@@ -271,7 +455,7 @@ func (l *Lexer) tryReadString() (Token, error) {
 		}
 
 		if l.current == '\\' {
-			err = l.decodeEscapeSequence(&value)
+			err = l.decodeEscapeSequence(value)
 			if err != nil {
 				return Token{}, err
 			}
@@ -282,6 +466,10 @@ func (l *Lexer) tryReadString() (Token, error) {
 		}
 
 		value.WriteRune(l.current)
+		err = l.checkLength(start)
+		if err != nil {
+			return Token{}, err
+		}
 	}
 
 	if l.current != '"' {
@@ -300,7 +488,49 @@ func (l *Lexer) tryReadString() (Token, error) {
 	}, nil
 }
 
-func (l *Lexer) decodeEscapeSequence(value *strings.Builder) error {
+// tryReadChar reads a character literal ('A', '\n'), which holds exactly one character or escape sequence
+func (l *Lexer) tryReadChar() (Token, error) {
+	if l.current != '\'' {
+		return Token{}, ErrInvalidCharacter
+	}
+
+	start := l.startLoc
+	value := l.resetValue()
+	err := l.advanceRune()
+	if err != nil {
+		return Token{}, err
+	}
+
+	switch {
+	case l.consumed || l.current == '\'' || l.current == '\n':
+		return Token{}, ErrMalformedCharLiteral
+	case l.current == '\\':
+		err = l.decodeEscapeSequence(value)
+	default:
+		value.WriteRune(l.current)
+		err = l.advanceRune()
+	}
+	if err != nil {
+		return Token{}, err
+	}
+
+	if l.consumed || l.current != '\'' {
+		return Token{}, ErrMalformedCharLiteral
+	}
+
+	err = l.advanceRune()
+	if err != nil {
+		return Token{}, err
+	}
+
+	return Token{
+		Tag:   TokenTagChar,
+		Loc:   start,
+		Value: value.String(),
+	}, nil
+}
+
+func (l *Lexer) decodeEscapeSequence(value *bytes.Buffer) error {
 	// must already read first '\'
 	err := l.advanceRune()
 	if err != nil {
@@ -372,17 +602,41 @@ func (l *Lexer) decodeEscapeSequence(value *strings.Builder) error {
 	return nil
 }
 
+// checkLength fails once the value being read is longer than the maximum token length
+func (l *Lexer) checkLength(start Location) error {
+	if l.maxTokenLength > 0 && l.value.Len() > l.maxTokenLength {
+		return &Error{Loc: start, Err: ErrTokenTooLong, Msg: fmt.Sprintf("longer than %d bytes", l.maxTokenLength)}
+	}
+
+	return nil
+}
+
+// resetValue empties the token value buffer and returns it
+func (l *Lexer) resetValue() *bytes.Buffer {
+	l.value.Reset()
+	return &l.value
+}
+
 func (l *Lexer) tryReadWord() (Token, error) {
-	if !unicode.IsLetter(l.current) && l.current != '_' {
+	if l.current == '`' {
+		return l.readEscapedWord()
+	}
+
+	if !unicode.IsLetter(l.current) && l.current != '_' && !l.isDollar() {
 		return Token{}, ErrInvalidCharacter
 	}
 
 	start := l.startLoc
-	value := strings.Builder{}
+	value := l.resetValue()
 
-	for unicode.IsLetter(l.current) || unicode.IsDigit(l.current) || l.current == '_' {
+	for unicode.IsLetter(l.current) || unicode.IsDigit(l.current) || l.current == '_' || l.isDollar() {
 		value.WriteRune(l.current)
-		err := l.advanceRune()
+		err := l.checkLength(start)
+		if err != nil {
+			return Token{}, err
+		}
+
+		err = l.advanceRune()
 		if err != nil {
 			return Token{}, err
 		}
@@ -396,6 +650,55 @@ func (l *Lexer) tryReadWord() (Token, error) {
 	}, nil
 }
 
+func (l *Lexer) isDollar() bool {
+	return l.dollarIdents && l.current == '$'
+}
+
+// readEscapedWord reads a word between backticks, the value excludes the backticks
+func (l *Lexer) readEscapedWord() (Token, error) {
+	start := l.startLoc
+	value := l.resetValue()
+
+	err := l.advanceRune()
+	if err != nil {
+		return Token{}, err
+	}
+
+	for l.current != '`' {
+		if l.current == '\n' || l.consumed {
+			return Token{}, ErrMalformedIdentifier
+		}
+
+		value.WriteRune(l.current)
+		err = l.checkLength(start)
+		if err != nil {
+			return Token{}, err
+		}
+
+		err = l.advanceRune()
+		if err != nil {
+			return Token{}, err
+		}
+	}
+
+	if value.Len() == 0 {
+		return Token{}, ErrMalformedIdentifier
+	}
+
+	err = l.advanceRune()
+	if err != nil {
+		return Token{}, err
+	}
+
+	l.endLoc.Col = start.Col + utf8.RuneCount(value.Bytes()) + 2
+	return Token{
+		Tag:     TokenTagWord,
+		Loc:     start,
+		Value:   value.String(),
+		Escaped: true,
+	}, nil
+}
+
 func (l *Lexer) tryReadPunct() (Token, error) {
 	value := strings.Builder{}
 	start := l.startLoc
@@ -439,11 +742,22 @@ func (l *Lexer) Read() (Token, error) {
 		if err != nil {
 			return token, errors.Join(err, token.GetErrorf("cannot read first character"))
 		}
+
+		// some editors start files with a byte order mark, it is skipped only as the very first rune
+		if l.current == '\uFEFF' && l.offset == int64(utf8.RuneLen('\uFEFF')) {
+			l.endLoc = l.startLoc
+			err = l.advanceRune()
+			if err != nil {
+				return token, errors.Join(err, token.GetErrorf("cannot read first character"))
+			}
+		}
 	}
 
-	err = l.skipSpaces()
-	if err != nil {
-		return token, errors.Join(err, token.GetErrorf("cannot skip spaces"))
+	if !l.whitespace {
+		err = l.skipSpaces()
+		if err != nil {
+			return token, errors.Join(err, token.GetErrorf("cannot skip spaces"))
+		}
 	}
 
 	defer func() {
@@ -453,10 +767,12 @@ func (l *Lexer) Read() (Token, error) {
 	// order is important
 	classifiers := []tryReadFn{
 		l.tryReadEOF,
+		l.tryReadWhitespace,
 		l.tryReadEOL,
 		l.tryReadComment,
 		l.tryReadNumber,
 		l.tryReadString,
+		l.tryReadChar,
 		l.tryReadWord,
 		l.tryReadPunct,
 	}
@@ -465,12 +781,86 @@ func (l *Lexer) Read() (Token, error) {
 		if err != nil && !errors.Is(err, ErrInvalidCharacter) {
 			return token, err
 		} else if err == nil {
+			l.trackTrailing(&token)
 			return token, nil
 		}
 	}
 
+	if l.recovering {
+		return l.recoverInvalidCharacter()
+	}
+
+	// the invalid character is not read as a token, the error is still located at it
 	token = Token{}
-	return token, errors.Join(ErrCannotTokenize, ErrInvalidCharacter, token.GetErrorf("invalid character: %q", l.current))
+	err = Token{Loc: l.startLoc}.GetErrorf("invalid character: %q", l.current)
+	return token, errors.Join(ErrCannotTokenize, ErrInvalidCharacter, err)
+}
+
+// trackTrailing marks the comments following a token on the same line, comments always end their line
+func (l *Lexer) trackTrailing(token *Token) {
+	switch token.Tag {
+	case TokenTagWhitespace:
+	case TokenTagEOL, TokenTagEOF:
+		l.rowHasToken = false
+	case TokenTagComment:
+		token.Trailing = l.rowHasToken && l.tokenRow == token.Loc.Row
+		l.rowHasToken = false
+	default:
+		l.tokenRow = l.endLoc.Row
+		l.rowHasToken = true
+	}
+}
+
+// recoverInvalidCharacter records the current rune as an error and skips it, returning an error token in its place
+func (l *Lexer) recoverInvalidCharacter() (Token, error) {
+	token := Token{Tag: TokenTagError, Loc: l.startLoc, Value: string(l.current)}
+	l.errors = append(l.errors, errors.Join(ErrInvalidCharacter, token.GetErrorf("invalid character: %q", l.current)))
+
+	err := l.advanceRune()
+	if err != nil {
+		return Token{}, err
+	}
+
+	return token, nil
+}
+
+// ReadSignificant reads the next token skipping comments (and whitespace, see KeepWhitespace), end of lines are
+// skipped as well when SkipBlankLines is set
+func (l *Lexer) ReadSignificant() (Token, error) {
+	for {
+		token, err := l.Read()
+		if err != nil {
+			return token, err
+		}
+
+		switch token.Tag {
+		case TokenTagComment, TokenTagWhitespace:
+			continue
+		case TokenTagEOL:
+			if l.skipBlankLines {
+				continue
+			}
+		}
+
+		return token, nil
+	}
+}
+
+// ReadAll reads every token up to the end of file, which is the last one. Returns the tokens read before the first
+// error along with it.
+func (l *Lexer) ReadAll() ([]Token, error) {
+	tokens := make([]Token, 0)
+	for {
+		token, err := l.Read()
+		if err != nil {
+			return tokens, err
+		}
+
+		tokens = append(tokens, token)
+		if token.Tag == TokenTagEOF {
+			return tokens, nil
+		}
+	}
 }
 
 // Unread attempts to set the given token as the unread token in the lexer. Returns an error if there is already an unread token.
@@ -483,6 +873,97 @@ func (l *Lexer) Unread(token Token) error {
 	return nil
 }
 
+// LexerState is a copy of the lexer position taken between tokens, see Snapshot
+type LexerState struct {
+	// Offset is the number of bytes consumed from the reader at the time of the snapshot
+	Offset int64
+
+	startLoc Location
+	endLoc   Location
+	current  rune
+	consumed bool
+	unread   *Token
+	group    int
+
+	tokenRow    int
+	rowHasToken bool
+}
+
+// Snapshot saves the lexer position so reading can be resumed from it later (e.g. to re-tokenize an edited
+// region), it must be taken between tokens.
+func (l *Lexer) Snapshot() LexerState {
+	state := LexerState{
+		Offset:   l.offset,
+		startLoc: l.startLoc,
+		endLoc:   l.endLoc,
+		current:  l.current,
+		consumed: l.consumed,
+		group:    l.group,
+
+		tokenRow:    l.tokenRow,
+		rowHasToken: l.rowHasToken,
+	}
+	if l.unread != nil {
+		unread := *l.unread
+		state.unread = &unread
+	}
+
+	return state
+}
+
+// Restore rewinds the lexer to a snapshot. The lexer does not own the reader, so the caller must seek it to the
+// snapshot Offset before reading again (for instance with io.Seeker).
+func (l *Lexer) Restore(state LexerState) {
+	l.offset = state.Offset
+	l.startLoc = state.startLoc
+	l.endLoc = state.endLoc
+	l.current = state.current
+	l.consumed = state.consumed
+	l.group = state.group
+	l.tokenRow = state.tokenRow
+	l.rowHasToken = state.rowHasToken
+	l.peeked = false
+	l.unread = nil
+	if state.unread != nil {
+		unread := *state.unread
+		l.unread = &unread
+	}
+}
+
+// SetMaxTokenLength bounds the length in bytes of words, numbers and strings (DefaultMaxTokenLength unless set)
+// so malformed inputs cannot grow a token without limit, a length of zero or less removes the bound.
+func (l *Lexer) SetMaxTokenLength(length int) {
+	l.maxTokenLength = length
+}
+
+// AllowDollarIdents accepts "$" as an identifier character (a$b is a single word), as required by some FFI
+// targets. It is disabled by default.
+func (l *Lexer) AllowDollarIdents(allow bool) {
+	l.dollarIdents = allow
+}
+
+// RecoverErrors makes Read continue after an invalid character: the rune is skipped, an error token is returned in
+// its place and the error is kept in Errors
+func (l *Lexer) RecoverErrors(recover bool) {
+	l.recovering = recover
+}
+
+// Errors returns the errors recovered so far, see RecoverErrors
+func (l *Lexer) Errors() []error {
+	return l.errors
+}
+
+// SkipBlankLines makes ReadSignificant skip end of lines too
+func (l *Lexer) SkipBlankLines(skip bool) {
+	l.skipBlankLines = skip
+}
+
+// KeepWhitespace emits each run of spaces between tokens as a TokenTagWhitespace token holding the exact
+// characters, so a formatter can reconstruct the source. Spaces are skipped by default.
+func (l *Lexer) KeepWhitespace(keep bool) {
+	l.whitespace = keep
+}
+
 // PushGroup pushes a group so lexer will ignore new lines
 func (l *Lexer) PushGroup() {
 	l.group += 1
@@ -498,6 +979,33 @@ func (l *Lexer) PopGroup() error {
 	return nil
 }
 
+// parseLineDirective reads a `#line N "file"` comment (the file is optional), lines are counted from 1 like in C so
+// the returned row is N-1
+func parseLineDirective(comment string) (int, string, bool) {
+	rest, ok := strings.CutPrefix(comment, "#line ")
+	if !ok {
+		return 0, "", false
+	}
+
+	number, file, _ := strings.Cut(strings.TrimSpace(rest), " ")
+	line, err := strconv.Atoi(number)
+	if err != nil || line < 1 {
+		return 0, "", false
+	}
+
+	file = strings.TrimSpace(file)
+	if file == "" {
+		return line - 1, "", true
+	}
+
+	file, err = strconv.Unquote(file)
+	if err != nil {
+		return 0, "", false
+	}
+
+	return line - 1, file, true
+}
+
 func isDigitOfBase(r rune, tag TokenTag) bool {
 	switch tag {
 	case TokenTagBinInt:
@@ -1,7 +1,13 @@
 package lexer_test
 
 import (
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/cedmundo/SimpleSchema/lexer"
 	"github.com/stretchr/testify/require"
@@ -84,6 +90,24 @@ func TestLexer_SingleScans(t *testing.T) {
 				{Tag: lexer.TokenTagEOF, Loc: lexer.Location{File: "lex hex int", Row: 0, Col: 6}},
 			},
 		},
+		{
+			name:  "lex bytes",
+			input: "0x[DEADbeef]",
+			expectedTokens: []lexer.Token{
+				{Tag: lexer.TokenTagBytes, Loc: lexer.Location{File: "lex bytes", Row: 0, Col: 0}, Value: "DEADbeef"},
+				{Tag: lexer.TokenTagEOF, Loc: lexer.Location{File: "lex bytes", Row: 0, Col: 12}},
+			},
+		},
+		{
+			name:          "lex unclosed bytes",
+			input:         "0x[DEAD",
+			expectedError: lexer.ErrMalformedBytesLiteral,
+		},
+		{
+			name:          "lex bytes with invalid digit",
+			input:         "0x[DEADG]",
+			expectedError: lexer.ErrMalformedBytesLiteral,
+		},
 		{
 			name:  "lex float one",
 			input: "1.0",
@@ -210,6 +234,37 @@ func TestLexer_SingleScans(t *testing.T) {
 			input:         `"\xNO"`,
 			expectedError: lexer.ErrMalformedEscapeSequence,
 		},
+		{
+			name:  "lex char",
+			input: `'A'`,
+			expectedTokens: []lexer.Token{
+				{Tag: lexer.TokenTagChar, Loc: lexer.Location{File: "lex char", Row: 0, Col: 0}, Value: "A"},
+				{Tag: lexer.TokenTagEOF, Loc: lexer.Location{File: "lex char", Row: 0, Col: 3}},
+			},
+		},
+		{
+			name:  "lex escaped char",
+			input: `'\''`,
+			expectedTokens: []lexer.Token{
+				{Tag: lexer.TokenTagChar, Loc: lexer.Location{File: "lex escaped char", Row: 0, Col: 0}, Value: "'"},
+				{Tag: lexer.TokenTagEOF, Loc: lexer.Location{File: "lex escaped char", Row: 0, Col: 4}},
+			},
+		},
+		{
+			name:          "lex empty char",
+			input:         `''`,
+			expectedError: lexer.ErrMalformedCharLiteral,
+		},
+		{
+			name:          "lex char with many characters",
+			input:         `'ab'`,
+			expectedError: lexer.ErrMalformedCharLiteral,
+		},
+		{
+			name:          "lex unterminated char",
+			input:         `'a`,
+			expectedError: lexer.ErrMalformedCharLiteral,
+		},
 		{
 			name:  "lex word",
 			input: `_hello_world_10`,
@@ -226,6 +281,57 @@ func TestLexer_SingleScans(t *testing.T) {
 				{Tag: lexer.TokenTagEOF, Loc: lexer.Location{File: "lex punct", Row: 0, Col: 2}},
 			},
 		},
+		{
+			name:  "lex annotation sigil",
+			input: `@doc`,
+			expectedTokens: []lexer.Token{
+				{Tag: lexer.TokenTagPunct, Loc: lexer.Location{File: "lex annotation sigil", Row: 0, Col: 0}, Value: "@"},
+				{Tag: lexer.TokenTagWord, Loc: lexer.Location{File: "lex annotation sigil", Row: 0, Col: 1}, Value: "doc"},
+				{Tag: lexer.TokenTagEOF, Loc: lexer.Location{File: "lex annotation sigil", Row: 0, Col: 4}},
+			},
+		},
+		{
+			name:  "lex ellipsis",
+			input: `(...)`,
+			expectedTokens: []lexer.Token{
+				{Tag: lexer.TokenTagPunct, Loc: lexer.Location{File: "lex ellipsis", Row: 0, Col: 0}, Value: "("},
+				{Tag: lexer.TokenTagPunct, Loc: lexer.Location{File: "lex ellipsis", Row: 0, Col: 1}, Value: "..."},
+				{Tag: lexer.TokenTagPunct, Loc: lexer.Location{File: "lex ellipsis", Row: 0, Col: 4}, Value: ")"},
+				{Tag: lexer.TokenTagEOF, Loc: lexer.Location{File: "lex ellipsis", Row: 0, Col: 5}},
+			},
+		},
+		{
+			name:  "lex power operator",
+			input: `a**b`,
+			expectedTokens: []lexer.Token{
+				{Tag: lexer.TokenTagWord, Loc: lexer.Location{File: "lex power operator", Row: 0, Col: 0}, Value: "a"},
+				{Tag: lexer.TokenTagPunct, Loc: lexer.Location{File: "lex power operator", Row: 0, Col: 1}, Value: "**"},
+				{Tag: lexer.TokenTagWord, Loc: lexer.Location{File: "lex power operator", Row: 0, Col: 3}, Value: "b"},
+				{Tag: lexer.TokenTagEOF, Loc: lexer.Location{File: "lex power operator", Row: 0, Col: 4}},
+			},
+		},
+		{
+			name:  "lex increment and decrement",
+			input: `a++--`,
+			expectedTokens: []lexer.Token{
+				{Tag: lexer.TokenTagWord, Loc: lexer.Location{File: "lex increment and decrement", Row: 0, Col: 0}, Value: "a"},
+				{Tag: lexer.TokenTagPunct, Loc: lexer.Location{File: "lex increment and decrement", Row: 0, Col: 1}, Value: "++"},
+				{Tag: lexer.TokenTagPunct, Loc: lexer.Location{File: "lex increment and decrement", Row: 0, Col: 3}, Value: "--"},
+				{Tag: lexer.TokenTagEOF, Loc: lexer.Location{File: "lex increment and decrement", Row: 0, Col: 5}},
+			},
+		},
+		{
+			name:  "lex member lookup",
+			input: `a.b.c`,
+			expectedTokens: []lexer.Token{
+				{Tag: lexer.TokenTagWord, Loc: lexer.Location{File: "lex member lookup", Row: 0, Col: 0}, Value: "a"},
+				{Tag: lexer.TokenTagPunct, Loc: lexer.Location{File: "lex member lookup", Row: 0, Col: 1}, Value: "."},
+				{Tag: lexer.TokenTagWord, Loc: lexer.Location{File: "lex member lookup", Row: 0, Col: 2}, Value: "b"},
+				{Tag: lexer.TokenTagPunct, Loc: lexer.Location{File: "lex member lookup", Row: 0, Col: 3}, Value: "."},
+				{Tag: lexer.TokenTagWord, Loc: lexer.Location{File: "lex member lookup", Row: 0, Col: 4}, Value: "c"},
+				{Tag: lexer.TokenTagEOF, Loc: lexer.Location{File: "lex member lookup", Row: 0, Col: 5}},
+			},
+		},
 		{
 			name:  "lex punct with juxtaposition",
 			input: `+(`,
@@ -279,6 +385,411 @@ func TestLexer_SingleScans(t *testing.T) {
 	}
 }
 
+func TestLexer_EscapedWords(t *testing.T) {
+	cases := []struct {
+		name          string
+		input         string
+		expectedToken lexer.Token
+		expectedError error
+	}{
+		{
+			name:  "lex escaped keyword",
+			input: "`type`",
+			expectedToken: lexer.Token{
+				Tag:     lexer.TokenTagWord,
+				Loc:     lexer.Location{File: "lex escaped keyword", Row: 0, Col: 0},
+				Value:   "type",
+				Escaped: true,
+			},
+		},
+		{
+			name:  "lex escaped word with spaces",
+			input: "`two words`",
+			expectedToken: lexer.Token{
+				Tag:     lexer.TokenTagWord,
+				Loc:     lexer.Location{File: "lex escaped word with spaces", Row: 0, Col: 0},
+				Value:   "two words",
+				Escaped: true,
+			},
+		},
+		{
+			name:          "lex unterminated escaped word",
+			input:         "`type",
+			expectedError: lexer.ErrMalformedIdentifier,
+		},
+		{
+			name:          "lex escaped word broken by a new line",
+			input:         "`ty\npe`",
+			expectedError: lexer.ErrMalformedIdentifier,
+		},
+		{
+			name:          "lex empty escaped word",
+			input:         "``",
+			expectedError: lexer.ErrMalformedIdentifier,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			lex := lexer.NewFromString(tt.name, tt.input)
+			actualToken, err := lex.Read()
+			if tt.expectedError != nil {
+				require.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedToken, actualToken)
+
+			eof, err := lex.Read()
+			require.NoError(t, err)
+			require.Equal(t, lexer.Location{File: tt.name, Row: 0, Col: len(tt.input)}, eof.Loc)
+		})
+	}
+}
+
+func TestLexer_DollarIdents(t *testing.T) {
+	cases := []struct {
+		name           string
+		allow          bool
+		input          string
+		expectedValues []string
+		expectedError  error
+	}{
+		{
+			name:           "dollar inside a word",
+			allow:          true,
+			input:          "a$b",
+			expectedValues: []string{"a$b"},
+		},
+		{
+			name:           "dollar starting a word",
+			allow:          true,
+			input:          "$a b$",
+			expectedValues: []string{"$a", "b$"},
+		},
+		{
+			name:           "dollar disabled",
+			input:          "a$b",
+			expectedValues: []string{"a"},
+			expectedError:  lexer.ErrInvalidCharacter,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			lex := lexer.NewFromString(tt.name, tt.input)
+			lex.AllowDollarIdents(tt.allow)
+			for _, expectedValue := range tt.expectedValues {
+				token, err := lex.Read()
+				require.NoError(t, err)
+				require.Equal(t, lexer.TokenTagWord, token.Tag)
+				require.Equal(t, expectedValue, token.Value)
+			}
+
+			token, err := lex.Read()
+			if tt.expectedError != nil {
+				require.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, lexer.TokenTagEOF, token.Tag)
+		})
+	}
+}
+
+func TestLexer_KeepWhitespace(t *testing.T) {
+	lex := lexer.NewFromString("test", "a \t b(  c) ")
+	lex.KeepWhitespace(true)
+
+	expectedTokens := []lexer.Token{
+		{Tag: lexer.TokenTagWord, Loc: lexer.Location{File: "test", Row: 0, Col: 0}, Value: "a"},
+		{Tag: lexer.TokenTagWhitespace, Loc: lexer.Location{File: "test", Row: 0, Col: 1}, Value: " \t "},
+		{Tag: lexer.TokenTagWord, Loc: lexer.Location{File: "test", Row: 0, Col: 4}, Value: "b"},
+		{Tag: lexer.TokenTagPunct, Loc: lexer.Location{File: "test", Row: 0, Col: 5}, Value: "("},
+		{Tag: lexer.TokenTagWhitespace, Loc: lexer.Location{File: "test", Row: 0, Col: 6}, Value: "  "},
+		{Tag: lexer.TokenTagWord, Loc: lexer.Location{File: "test", Row: 0, Col: 8}, Value: "c"},
+		{Tag: lexer.TokenTagPunct, Loc: lexer.Location{File: "test", Row: 0, Col: 9}, Value: ")"},
+		{Tag: lexer.TokenTagWhitespace, Loc: lexer.Location{File: "test", Row: 0, Col: 10}, Value: " "},
+		{Tag: lexer.TokenTagEOF, Loc: lexer.Location{File: "test", Row: 0, Col: 11}},
+	}
+	for _, expectedToken := range expectedTokens {
+		actualToken, err := lex.Read()
+		require.NoError(t, err)
+		require.Equal(t, expectedToken, actualToken)
+	}
+}
+
+func TestLexer_TrailingComments(t *testing.T) {
+	comments := func(lex *lexer.Lexer) map[string]bool {
+		trailing := make(map[string]bool)
+		for {
+			token, err := lex.Read()
+			require.NoError(t, err)
+			if token.Tag == lexer.TokenTagEOF {
+				return trailing
+			}
+
+			if token.Tag == lexer.TokenTagComment {
+				trailing[token.Value] = token.Trailing
+			}
+		}
+	}
+
+	lex := lexer.NewFromString("test", "# leading\na # trailing\n\n  # indented\nb\n")
+	expected := map[string]bool{"# leading": false, "# trailing": true, "# indented": false}
+	require.Equal(t, expected, comments(lex))
+
+	// within groups the new lines are skipped as spaces, the rows still tell the comments apart
+	lex = lexer.NewFromString("test", "a,\n# leading\nb # trailing\n")
+	lex.PushGroup()
+	expected = map[string]bool{"# leading": false, "# trailing": true}
+	require.Equal(t, expected, comments(lex))
+}
+
+func TestLexer_MaxTokenLength(t *testing.T) {
+	cases := []struct {
+		name          string
+		input         string
+		expectedError error
+	}{
+		{name: "word under the limit", input: strings.Repeat("a", 8)},
+		{name: "word over the limit", input: strings.Repeat("a", 9), expectedError: lexer.ErrTokenTooLong},
+		{name: "escaped word over the limit", input: "`" + strings.Repeat("a", 9) + "`", expectedError: lexer.ErrTokenTooLong},
+		{name: "number under the limit", input: strings.Repeat("1", 8)},
+		{name: "number over the limit", input: "0x" + strings.Repeat("f", 9), expectedError: lexer.ErrTokenTooLong},
+		{name: "string under the limit", input: `"` + strings.Repeat("s", 8) + `"`},
+		{name: "unterminated string over the limit", input: `"` + strings.Repeat("s", 9), expectedError: lexer.ErrTokenTooLong},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			lex := lexer.NewFromString(tt.name, "  "+tt.input)
+			lex.SetMaxTokenLength(8)
+
+			_, err := lex.Read()
+			if tt.expectedError != nil {
+				require.ErrorIs(t, err, tt.expectedError)
+				require.ErrorContains(t, err, tt.name+":0:2")
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestLexer_ByteOrderMark(t *testing.T) {
+	input := "type vec2 float[2];"
+	readAll := func(input string) ([]lexer.Token, error) {
+		lex := lexer.NewFromString("test", input)
+		tokens := make([]lexer.Token, 0)
+		for {
+			token, err := lex.Read()
+			if err != nil {
+				return tokens, err
+			}
+
+			tokens = append(tokens, token)
+			if token.Tag == lexer.TokenTagEOF {
+				return tokens, nil
+			}
+		}
+	}
+
+	expectedTokens, err := readAll(input)
+	require.NoError(t, err)
+
+	actualTokens, err := readAll("\uFEFF" + input)
+	require.NoError(t, err)
+	require.Equal(t, expectedTokens, actualTokens)
+
+	_, err = readAll("type \uFEFFvec2")
+	require.ErrorIs(t, err, lexer.ErrInvalidCharacter)
+}
+
+func TestLexer_LineDirective(t *testing.T) {
+	lex := lexer.NewFromString("merged.ss", "a\n#line 10 \"other.ss\"\nb\n#line 3\nc\n# line 40\nd")
+	expected := []lexer.Location{
+		{File: "merged.ss", Row: 0},
+		{File: "other.ss", Row: 9},
+		{File: "other.ss", Row: 2},
+		{File: "other.ss", Row: 4},
+	}
+
+	actual := make([]lexer.Location, 0)
+	for {
+		token, err := lex.Read()
+		require.NoError(t, err)
+		if token.Tag == lexer.TokenTagEOF {
+			break
+		}
+
+		// only rows and files are remapped, columns are left as they are
+		if token.Tag == lexer.TokenTagWord {
+			actual = append(actual, lexer.Location{File: token.Loc.File, Row: token.Loc.Row})
+		}
+	}
+
+	require.Equal(t, expected, actual)
+}
+
+func TestLexer_RecoverErrors(t *testing.T) {
+	lex := lexer.NewFromString("recover", "a $ b \\ c")
+	lex.RecoverErrors(true)
+
+	tags := make([]lexer.TokenTag, 0)
+	for {
+		token, err := lex.Read()
+		require.NoError(t, err)
+		tags = append(tags, token.Tag)
+		if token.Tag == lexer.TokenTagEOF {
+			break
+		}
+	}
+
+	require.Equal(t, []lexer.TokenTag{
+		lexer.TokenTagWord,
+		lexer.TokenTagError,
+		lexer.TokenTagWord,
+		lexer.TokenTagError,
+		lexer.TokenTagWord,
+		lexer.TokenTagEOF,
+	}, tags)
+
+	errs := lex.Errors()
+	require.Len(t, errs, 2)
+	require.ErrorIs(t, errs[0], lexer.ErrInvalidCharacter)
+	require.ErrorContains(t, errs[0], "recover:0:2")
+	require.ErrorIs(t, errs[1], lexer.ErrInvalidCharacter)
+}
+
+func TestLexer_RecoverErrorsDisabled(t *testing.T) {
+	lex := lexer.NewFromString("stop", "a $ b")
+	_, err := lex.Read()
+	require.NoError(t, err)
+
+	_, err = lex.Read()
+	require.ErrorIs(t, err, lexer.ErrInvalidCharacter)
+	require.Empty(t, lex.Errors())
+
+	var located *lexer.Error
+	require.ErrorAs(t, err, &located)
+	require.Equal(t, lexer.Location{File: "stop", Row: 0, Col: 2}, located.Loc)
+}
+
+func TestLexer_ReadSignificant(t *testing.T) {
+	input := "# header\n\ntype a int; # trailing\n\n# between\ntype b u8;\n"
+	cases := []struct {
+		name           string
+		skipBlankLines bool
+		keepWhitespace bool
+		expectedValues []string
+	}{
+		// ";" is an end of line too, the comments consume their line break
+		{
+			name:           "comments only",
+			expectedValues: []string{"", "type", "a", "int", "", "", "type", "b", "u8", ""},
+		},
+		{
+			name:           "comments and blank lines",
+			skipBlankLines: true,
+			expectedValues: []string{"type", "a", "int", "type", "b", "u8"},
+		},
+		{
+			name:           "whitespace is never significant",
+			skipBlankLines: true,
+			keepWhitespace: true,
+			expectedValues: []string{"type", "a", "int", "type", "b", "u8"},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			lex := lexer.NewFromString(tt.name, input)
+			lex.SkipBlankLines(tt.skipBlankLines)
+			lex.KeepWhitespace(tt.keepWhitespace)
+
+			actualValues := make([]string, 0)
+			for {
+				token, err := lex.ReadSignificant()
+				require.NoError(t, err)
+				require.NotEqual(t, lexer.TokenTagComment, token.Tag)
+				if token.Tag == lexer.TokenTagEOF {
+					break
+				}
+
+				actualValues = append(actualValues, token.Value)
+			}
+
+			require.Equal(t, tt.expectedValues, actualValues)
+		})
+	}
+}
+
+func TestLexer_Ranges(t *testing.T) {
+	cases := []struct {
+		name           string
+		input          string
+		expectedTags   []lexer.TokenTag
+		expectedValues []string
+	}{
+		{
+			name:           "integer range",
+			input:          "1..100",
+			expectedTags:   []lexer.TokenTag{lexer.TokenTagDecInt, lexer.TokenTagPunct, lexer.TokenTagDecInt},
+			expectedValues: []string{"1", "..", "100"},
+		},
+		{
+			name:           "range from zero",
+			input:          "0..9",
+			expectedTags:   []lexer.TokenTag{lexer.TokenTagDecInt, lexer.TokenTagPunct, lexer.TokenTagDecInt},
+			expectedValues: []string{"0", "..", "9"},
+		},
+		{
+			name:           "float range",
+			input:          "0.5..1.5",
+			expectedTags:   []lexer.TokenTag{lexer.TokenTagFloat, lexer.TokenTagPunct, lexer.TokenTagFloat},
+			expectedValues: []string{"0.5", "..", "1.5"},
+		},
+		{
+			name:           "hex range",
+			input:          "0x0..0xFF",
+			expectedTags:   []lexer.TokenTag{lexer.TokenTagHexInt, lexer.TokenTagPunct, lexer.TokenTagHexInt},
+			expectedValues: []string{"0", "..", "FF"},
+		},
+		{
+			name:           "named range",
+			input:          "min..max",
+			expectedTags:   []lexer.TokenTag{lexer.TokenTagWord, lexer.TokenTagPunct, lexer.TokenTagWord},
+			expectedValues: []string{"min", "..", "max"},
+		},
+		{
+			name:           "floats and lookups are unchanged",
+			input:          "1.5 a.b",
+			expectedTags:   []lexer.TokenTag{lexer.TokenTagFloat, lexer.TokenTagWord, lexer.TokenTagPunct, lexer.TokenTagWord},
+			expectedValues: []string{"1.5", "a", ".", "b"},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			lex := lexer.NewFromString(tt.name, tt.input)
+			actualTags := make([]lexer.TokenTag, 0)
+			actualValues := make([]string, 0)
+			for {
+				token, err := lex.Read()
+				require.NoError(t, err)
+				if token.Tag == lexer.TokenTagEOF {
+					break
+				}
+
+				actualTags = append(actualTags, token.Tag)
+				actualValues = append(actualValues, token.Value)
+			}
+
+			require.Equal(t, tt.expectedTags, actualTags)
+			require.Equal(t, tt.expectedValues, actualValues)
+		})
+	}
+}
+
 func TestLexer_TestSkipEOL(t *testing.T) {
 	input := "example\nignoring\nEOLs"
 	lex := lexer.NewFromString("test", input)
@@ -320,3 +831,154 @@ func TestLexer_TestSkipEOL(t *testing.T) {
 		Value: "EOLs",
 	}, token)
 }
+
+func TestNewFromReader(t *testing.T) {
+	file, err := os.Open(writeSchema(t, "type vec2 float[2];"))
+	require.NoError(t, err)
+	defer file.Close()
+
+	lex := lexer.NewFromReader("test", file)
+	expectedValues := []string{"type", "vec2", "float", "[", "2", "]", ""}
+	for _, expectedValue := range expectedValues {
+		token, err := lex.Read()
+		require.NoError(t, err)
+		require.Equal(t, expectedValue, token.Value)
+	}
+
+	token, err := lex.Read()
+	require.NoError(t, err)
+	require.Equal(t, lexer.TokenTagEOF, token.Tag)
+}
+
+func TestLexer_SnapshotRestore(t *testing.T) {
+	reader := strings.NewReader("type vec2 float[2];\ntype vec3 (\nfloat, float,\nfloat);\n")
+	lex := lexer.New("test", reader)
+	readTokens := func(n int) []lexer.Token {
+		tokens := make([]lexer.Token, 0, n)
+		for range n {
+			token, err := lex.Read()
+			require.NoError(t, err)
+			tokens = append(tokens, token)
+		}
+		return tokens
+	}
+
+	readTokens(7)
+
+	// snapshot inside a group and with a pending unread token
+	opening := readTokens(3)
+	lex.PushGroup()
+	next := readTokens(1)
+	require.NoError(t, lex.Unread(next[0]))
+
+	state := lex.Snapshot()
+	expectedTokens := readTokens(6)
+	require.NoError(t, lex.PopGroup())
+	expectedTokens = append(expectedTokens, readTokens(3)...)
+	require.Equal(t, lexer.TokenTagEOF, expectedTokens[len(expectedTokens)-1].Tag)
+
+	_, err := reader.Seek(state.Offset, io.SeekStart)
+	require.NoError(t, err)
+	lex.Restore(state)
+
+	actualTokens := readTokens(6)
+	require.NoError(t, lex.PopGroup())
+	actualTokens = append(actualTokens, readTokens(3)...)
+	require.Equal(t, expectedTokens, actualTokens)
+	require.Equal(t, "vec3", opening[1].Value)
+	require.Equal(t, "float", actualTokens[0].Value)
+}
+
+// unbufferedReader reads runes straight from the underlying reader, one read call per byte
+type unbufferedReader struct {
+	reader io.Reader
+}
+
+func (u *unbufferedReader) ReadRune() (rune, int, error) {
+	buf := make([]byte, 0, utf8.UTFMax)
+	for !utf8.FullRune(buf) {
+		b := []byte{0}
+		_, err := u.reader.Read(b)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		buf = append(buf, b[0])
+	}
+
+	r, size := utf8.DecodeRune(buf)
+	return r, size, nil
+}
+
+func writeSchema(tb testing.TB, content string) string {
+	tb.Helper()
+	path := filepath.Join(tb.TempDir(), "schema.ss")
+	require.NoError(tb, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func lexAll(tb testing.TB, lex *lexer.Lexer) {
+	tb.Helper()
+	for {
+		token, err := lex.Read()
+		require.NoError(tb, err)
+		if token.Tag == lexer.TokenTagEOF {
+			return
+		}
+	}
+}
+
+func benchmarkFile(b *testing.B, newLexer func(file *os.File) *lexer.Lexer) {
+	content := strings.Repeat("type vec2 struct {\n  x : float = 1.5 # first\n  y : float = 0x10\n}\n", 1000)
+	path := writeSchema(b, content)
+	b.SetBytes(int64(len(content)))
+	b.ResetTimer()
+
+	for range b.N {
+		file, err := os.Open(path)
+		require.NoError(b, err)
+
+		lexAll(b, newLexer(file))
+		require.NoError(b, file.Close())
+	}
+}
+
+func BenchmarkLexer_Unbuffered(b *testing.B) {
+	benchmarkFile(b, func(file *os.File) *lexer.Lexer {
+		return lexer.New("bench", &unbufferedReader{reader: file})
+	})
+}
+
+func BenchmarkLexer_NewFromReader(b *testing.B) {
+	benchmarkFile(b, func(file *os.File) *lexer.Lexer {
+		return lexer.NewFromReader("bench", file)
+	})
+}
+
+func BenchmarkLexer_Read(b *testing.B) {
+	content := strings.Repeat("type position struct {\n  horizontal_offset : float = 12.5e-3\n  "+
+		"description : string = \"two dimensional\\tvector\"\n  vertical_offset : u32 = 0x1000FFFF\n}\n", 1000)
+	tokens := 0
+	lex := lexer.NewFromString("bench", content)
+	for {
+		token, err := lex.Read()
+		require.NoError(b, err)
+		if token.Tag == lexer.TokenTagEOF {
+			break
+		}
+		tokens++
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for range b.N {
+		lexAll(b, lexer.NewFromString("bench", content))
+	}
+
+	b.StopTimer()
+	runtime.ReadMemStats(&after)
+	b.ReportMetric(float64(after.Mallocs-before.Mallocs)/float64(b.N*tokens), "allocs/token")
+}
@@ -2,6 +2,8 @@ package lexer
 
 import (
 	"fmt"
+
+	"github.com/cedmundo/SimpleSchema/keywords"
 )
 
 // Location is a token coordinate, relative to build path
@@ -19,20 +21,29 @@ type Token struct {
 	Tag   TokenTag
 	Loc   Location
 	Value string
+	// Escaped marks words written between backticks (`type`), they are never keywords
+	Escaped bool
+	// Trailing marks comments written after another token on the same line (a : int # note), comments on their
+	// own line are leading comments
+	Trailing bool
 }
 
 const (
-	TokenTagEOF     TokenTag = iota // TokenTagEOF end of file
-	TokenTagEOL                     // TokenTagEOL end of line
-	TokenTagComment                 // TokenTagComment only single-line comments at the moment
-	TokenTagDecInt                  // TokenTagDecInt a decimal integer number
-	TokenTagBinInt                  // TokenTagBinInt a binary integer number
-	TokenTagOctInt                  // TokenTagOctInt a octal integer number
-	TokenTagHexInt                  // TokenTagHexInt a hexadecimal integer number
-	TokenTagFloat                   // TokenTagFloat a decimal floating point number
-	TokenTagString                  // TokenTagString a string literal
-	TokenTagWord                    // TokenTagWord both ids and keywords
-	TokenTagPunct                   // TokenTagPunct any punctuation symbol
+	TokenTagEOF        TokenTag = iota // TokenTagEOF end of file
+	TokenTagEOL                        // TokenTagEOL end of line
+	TokenTagComment                    // TokenTagComment only single-line comments at the moment
+	TokenTagDecInt                     // TokenTagDecInt a decimal integer number
+	TokenTagBinInt                     // TokenTagBinInt a binary integer number
+	TokenTagOctInt                     // TokenTagOctInt a octal integer number
+	TokenTagHexInt                     // TokenTagHexInt a hexadecimal integer number
+	TokenTagFloat                      // TokenTagFloat a decimal floating point number
+	TokenTagString                     // TokenTagString a string literal
+	TokenTagWord                       // TokenTagWord both ids and keywords
+	TokenTagPunct                      // TokenTagPunct any punctuation symbol
+	TokenTagWhitespace                 // TokenTagWhitespace spaces between tokens, only emitted on demand
+	TokenTagError                      // TokenTagError an invalid character skipped while recovering from errors
+	TokenTagChar                       // TokenTagChar a character literal, the value is the decoded character
+	TokenTagBytes                      // TokenTagBytes a byte string literal (0x[DEADBEEF]), the value is its hex digits
 )
 
 // String returns a standard file coordinate format
@@ -56,14 +67,54 @@ func (t Token) String() string {
 		return fmt.Sprintf("`FLOAT '%s'`", t.Value)
 	case TokenTagString:
 		return fmt.Sprintf("`STRING '%s'`", t.Value)
+	case TokenTagChar:
+		return fmt.Sprintf("`CHAR %q`", t.Value)
+	case TokenTagBytes:
+		return fmt.Sprintf("`BYTES '%s'`", t.Value)
 	case TokenTagWord:
 		return fmt.Sprintf("`WORD '%s'`", t.Value)
 	case TokenTagPunct:
 		return fmt.Sprintf("`PUNCT '%s'`", t.Value)
+	case TokenTagWhitespace:
+		return fmt.Sprintf("`WHITESPACE %q`", t.Value)
+	case TokenTagError:
+		return fmt.Sprintf("`ERROR %q`", t.Value)
 	}
 	panic("unreachable code: unhandled tag in Token.String()")
 }
 
+// IsKeyword reports whether the token is a reserved word, words written between backticks are never keywords
+func (t Token) IsKeyword() bool {
+	return t.Tag == TokenTagWord && !t.Escaped && keywords.IsKeyword(t.Value)
+}
+
+// GetErrorf returns an error located at the token
 func (t Token) GetErrorf(msg string, args ...any) error {
-	return fmt.Errorf("%s:%d:%d: %s", t.Loc.File, t.Loc.Row, t.Loc.Col, fmt.Sprintf(msg, args...))
+	return &Error{Loc: t.Loc, Msg: fmt.Sprintf(msg, args...)}
+}
+
+// Error is an error found at a location of the input, the parser reports its located errors with it as well. Err is
+// the sentinel error, if any, and Msg describes the error further.
+type Error struct {
+	Loc Location
+	Err error
+	Msg string
+}
+
+// Error returns the diagnostic using the standard file coordinate format
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Loc.String() + ": " + e.Msg
+	}
+
+	if e.Msg == "" {
+		return e.Loc.String() + ": " + e.Err.Error()
+	}
+
+	return e.Loc.String() + ": " + e.Err.Error() + ": " + e.Msg
+}
+
+// Unwrap returns the sentinel error
+func (e *Error) Unwrap() error {
+	return e.Err
 }
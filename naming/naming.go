@@ -0,0 +1,92 @@
+// Package naming converts schema identifiers between the casing conventions of each backend
+package naming
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Words splits an identifier into its words. Underscores, dashes and spaces separate words, so do case changes:
+// a lowercase followed by an uppercase (fooBar), and the last uppercase of an acronym followed by a lowercase
+// (HTTPServer). Digits stick to the word preceding them (utf8Decoder).
+func Words(name string) []string {
+	words := make([]string, 0)
+	runes := []rune(name)
+	current := &strings.Builder{}
+	flush := func() {
+		if current.Len() > 0 {
+			words = append(words, current.String())
+			current.Reset()
+		}
+	}
+
+	for i, r := range runes {
+		if r == '_' || r == '-' || unicode.IsSpace(r) {
+			flush()
+			continue
+		}
+
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				flush()
+			}
+		}
+
+		current.WriteRune(r)
+	}
+
+	flush()
+	return words
+}
+
+// ToSnake converts a name into snake_case (HTTPServer -> http_server)
+func ToSnake(name string) string {
+	words := Words(name)
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+	}
+
+	return strings.Join(words, "_")
+}
+
+// ToScreamingSnake converts a name into SCREAMING_SNAKE_CASE (HTTPServer -> HTTP_SERVER)
+func ToScreamingSnake(name string) string {
+	words := Words(name)
+	for i, word := range words {
+		words[i] = strings.ToUpper(word)
+	}
+
+	return strings.Join(words, "_")
+}
+
+// ToPascal converts a name into PascalCase, acronyms are capitalized as regular words (http_server -> HttpServer)
+func ToPascal(name string) string {
+	words := Words(name)
+	for i, word := range words {
+		words[i] = capitalize(word)
+	}
+
+	return strings.Join(words, "")
+}
+
+// ToCamel converts a name into lowerCamelCase (HTTPServer -> httpServer)
+func ToCamel(name string) string {
+	words := Words(name)
+	for i, word := range words {
+		if i == 0 {
+			words[i] = strings.ToLower(word)
+		} else {
+			words[i] = capitalize(word)
+		}
+	}
+
+	return strings.Join(words, "")
+}
+
+func capitalize(word string) string {
+	runes := []rune(strings.ToLower(word))
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
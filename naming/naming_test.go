@@ -0,0 +1,56 @@
+package naming_test
+
+import (
+	"testing"
+
+	"github.com/cedmundo/SimpleSchema/naming"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWords(t *testing.T) {
+	cases := []struct {
+		name          string
+		input         string
+		expectedWords []string
+	}{
+		{name: "empty", input: "", expectedWords: []string{}},
+		{name: "single word", input: "hello", expectedWords: []string{"hello"}},
+		{name: "snake case", input: "hello_world", expectedWords: []string{"hello", "world"}},
+		{name: "camel case", input: "helloWorld", expectedWords: []string{"hello", "World"}},
+		{name: "acronym", input: "HTTPServer", expectedWords: []string{"HTTP", "Server"}},
+		{name: "trailing acronym", input: "serveHTTP", expectedWords: []string{"serve", "HTTP"}},
+		{name: "digits", input: "utf8Decoder", expectedWords: []string{"utf8", "Decoder"}},
+		{name: "repeated separators", input: "__a--b  c", expectedWords: []string{"a", "b", "c"}},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expectedWords, naming.Words(tt.input))
+		})
+	}
+}
+
+func TestConversions(t *testing.T) {
+	cases := []struct {
+		input            string
+		expectedSnake    string
+		expectedScreamer string
+		expectedPascal   string
+		expectedCamel    string
+	}{
+		{"hello", "hello", "HELLO", "Hello", "hello"},
+		{"hello_world", "hello_world", "HELLO_WORLD", "HelloWorld", "helloWorld"},
+		{"HelloWorld", "hello_world", "HELLO_WORLD", "HelloWorld", "helloWorld"},
+		{"HTTPServer", "http_server", "HTTP_SERVER", "HttpServer", "httpServer"},
+		{"vec2", "vec2", "VEC2", "Vec2", "vec2"},
+		{"vec2Add", "vec2_add", "VEC2_ADD", "Vec2Add", "vec2Add"},
+		{"MAX_SIZE", "max_size", "MAX_SIZE", "MaxSize", "maxSize"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.input, func(t *testing.T) {
+			require.Equal(t, tt.expectedSnake, naming.ToSnake(tt.input))
+			require.Equal(t, tt.expectedScreamer, naming.ToScreamingSnake(tt.input))
+			require.Equal(t, tt.expectedPascal, naming.ToPascal(tt.input))
+			require.Equal(t, tt.expectedCamel, naming.ToCamel(tt.input))
+		})
+	}
+}
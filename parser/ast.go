@@ -12,7 +12,7 @@ type Expr interface {
 	expr()
 }
 
-// Annotation maps from lookup name to a value
+// Annotation maps from lookup name to a value, flag annotations have no value
 type Annotation struct {
 	Name  Expr
 	Value Expr
@@ -22,10 +22,30 @@ type Annotation struct {
 type AnnotatedDecl struct {
 	Annotations []*Annotation
 	Decl        Decl
+
+	// Deprecated and DeprecationMessage are filled by the analyzer from [[ deprecated ]] or
+	// [[ deprecated = "message" ]], so backends do not need to inspect the annotation themselves
+	Deprecated         bool
+	DeprecationMessage string
 }
 
 func (aw *AnnotatedDecl) decl() {}
 
+// Find returns the first annotation matching a (possibly dotted) name, it is safe to call on a nil receiver
+func (aw *AnnotatedDecl) Find(name string) (*Annotation, bool) {
+	if aw == nil {
+		return nil, false
+	}
+
+	for _, annotation := range aw.Annotations {
+		if LookupName(annotation.Name) == name {
+			return annotation, true
+		}
+	}
+
+	return nil, false
+}
+
 // Literal represents any plain data in text representation
 type Literal struct {
 	Token lexer.Token
@@ -33,6 +53,14 @@ type Literal struct {
 
 func (l *Literal) expr() {}
 
+// BytesLit represents a byte string literal (0x[DEADBEEF]) along with its decoded bytes
+type BytesLit struct {
+	Token lexer.Token
+	Bytes []byte
+}
+
+func (bl *BytesLit) expr() {}
+
 // Ident represents an identifier
 type Ident struct {
 	Token lexer.Token
@@ -48,7 +76,15 @@ type Call struct {
 
 func (ca *Call) expr() {}
 
-// Index represents a selection expression (base[index])
+// ListLit represents a list of values ([255, 0, 0]), used by annotations to attach several values to a declaration
+type ListLit struct {
+	Open     lexer.Token
+	Elements []Expr
+}
+
+func (ll *ListLit) expr() {}
+
+// Index represents a selection expression (base[index]), the index is nil on empty subscripts (base[])
 type Index struct {
 	Base  Expr
 	Index Expr
@@ -60,6 +96,8 @@ func (in *Index) expr() {}
 type UnaryOp struct {
 	Operator lexer.Token
 	Operand  Expr
+	// Postfix marks operators written after the operand (a++)
+	Postfix bool
 }
 
 func (uo *UnaryOp) expr() {}
@@ -73,6 +111,15 @@ type BinaryOp struct {
 
 func (bo *BinaryOp) expr() {}
 
+// QualifiedName represents a reference to a type of an imported package in type position (pkg.Type), Package is
+// either an Ident or another QualifiedName for dotted packages (net.http.Request)
+type QualifiedName struct {
+	Package Expr
+	Name    *Ident
+}
+
+func (qn *QualifiedName) expr() {}
+
 // StructDef represents the definition of a struct body(struct { fields ... })
 type StructDef struct {
 	Block Block
@@ -96,12 +143,67 @@ func (sd *EnumDef) expr() {}
 
 // PrototypeDef represents the definition of a prototype (proc(int, int) -> int)
 type PrototypeDef struct {
-	Params     []Field
+	Params []Field
+	// ReturnType is any type, multiple values are returned as a tuple (proc() -> (int, string))
 	ReturnType Expr
 }
 
 func (pd *PrototypeDef) expr() {}
 
+// SetType represents an unordered collection of unique elements (set[T])
+type SetType struct {
+	Element Expr
+}
+
+func (st *SetType) expr() {}
+
+// MapType represents a collection of values indexed by unique keys (map[K]V)
+type MapType struct {
+	Key   Expr
+	Value Expr
+}
+
+func (mt *MapType) expr() {}
+
+// TupleType represents an ordered group of values with different types ((A, B))
+type TupleType struct {
+	Elements []Expr
+}
+
+func (tt *TupleType) expr() {}
+
+// FixedStringType represents a string stored in a buffer of a fixed number of bytes (string<N>)
+type FixedStringType struct {
+	Size Expr
+}
+
+func (fs *FixedStringType) expr() {}
+
+// DecimalType represents a fixed-point number with a number of significant digits and digits after the
+// decimal point (decimal<10, 2>)
+type DecimalType struct {
+	Precision Expr
+	Scale     Expr
+}
+
+func (dt *DecimalType) expr() {}
+
+// OptionalType represents a value that may be absent (?T), see analyzer.Desugar for its canonical forms
+type OptionalType struct {
+	Token lexer.Token
+	Type  Expr
+}
+
+func (ot *OptionalType) expr() {}
+
+// SizedIntType represents an integer of an exact number of bits (int<24>, uint<24>)
+type SizedIntType struct {
+	Bits     Expr
+	Unsigned bool
+}
+
+func (si *SizedIntType) expr() {}
+
 // Block represents a sequence of declarations within a scope ({})
 type Block struct {
 	Decls []Decl
@@ -112,14 +214,53 @@ type Field struct {
 	Name  Expr
 	Type  Expr
 	Value Expr
+
+	// Range constrains the values of the field (score : int in 0..100), nil when unconstrained
+	Range *RangeConstraint
+
+	// Tag marks the discriminant of a tagged union (union { tag kind : Kind })
+	Tag bool
+
+	// Leading are the comments written on their own lines right before the field
+	Leading []lexer.Token
+	// Trailing is the comment written after the field on the same line
+	Trailing *lexer.Token
+
+	// Annotations are only set on proc parameters (proc f([[ doc = "..." ]] a : int) -> int), the fields of blocks
+	// are wrapped in an AnnotatedDecl instead
+	Annotations []*Annotation
 }
 
 func (fi *Field) decl() {}
 
+// EmbedDecl includes the fields of another struct in a block (include Base), they are expanded by the analyzer
+type EmbedDecl struct {
+	Type Expr
+}
+
+func (ed *EmbedDecl) decl() {}
+
+// RangeConstraint bounds the values of a field, both ends are inclusive
+type RangeConstraint struct {
+	Low  Expr
+	High Expr
+}
+
+// Constraint bounds a generic parameter of a declaration, the bound is not resolved by the parser
+type Constraint struct {
+	Param *Ident
+	Bound Expr
+}
+
 // TypeDecl represents a type declaration ("type Name Type" or "proc Name(arg: Type) -> Type")
 type TypeDecl struct {
 	Name Expr
 	Type Expr
+	// IsAlias marks the explicit alias form (type Name = Type), it only renames the type and never defines a new one
+	IsAlias bool
+	// Params are the generic parameters (type List<T>) and Constraints bound them (where T : Comparable)
+	Params      []*Ident
+	Constraints []*Constraint
 }
 
 func (ty *TypeDecl) decl() {}
@@ -128,10 +269,22 @@ func (ty *TypeDecl) decl() {}
 type ProcDecl struct {
 	Name Expr
 	Type Expr
+	// Params and Constraints are the generic parameters and their bounds (proc max<T> where T : Ordered (a: T) -> T)
+	Params      []*Ident
+	Constraints []*Constraint
 }
 
 func (pd *ProcDecl) decl() {}
 
+// ConstDecl represents a named constant ("const NAME : Type = value")
+type ConstDecl struct {
+	Name  Expr
+	Type  Expr
+	Value Expr
+}
+
+func (cd *ConstDecl) decl() {}
+
 // ModuleDecl represents a module declaration ("module id")
 type ModuleDecl struct {
 	Name Expr
@@ -139,7 +292,114 @@ type ModuleDecl struct {
 
 func (md *ModuleDecl) decl() {}
 
+// PackageDecl represents a package declaration ("package foo.bar"), a logical grouping used by the backends that
+// namespace their output, unlike the module it has nothing to do with the C include guard
+type PackageDecl struct {
+	Name Expr
+}
+
+func (pd *PackageDecl) decl() {}
+
+// Option is a single setting of an options directive (namespace = "foo")
+type Option struct {
+	Name  Expr
+	Value Expr
+}
+
+// OptionBlock is the sequence of settings within an options directive ({ namespace = "foo"; version = 2 })
+type OptionBlock struct {
+	Options []Option
+}
+
+// Find returns the value of the first option with the given name
+func (ob OptionBlock) Find(name string) (Expr, bool) {
+	for _, option := range ob.Options {
+		if LookupName(option.Name) == name {
+			return option.Value, true
+		}
+	}
+
+	return nil, false
+}
+
+// OptionsDecl represents the schema-level directive configuring the code generation ("options { version = 2 }"),
+// Token is the options keyword
+type OptionsDecl struct {
+	Token lexer.Token
+	Block OptionBlock
+}
+
+func (od *OptionsDecl) decl() {}
+
+// ImportDecl represents an import declaration ("import "path"")
+type ImportDecl struct {
+	Path Expr
+}
+
+func (id *ImportDecl) decl() {}
+
 // Schema represents the data of an entire schema file
 type Schema struct {
 	Decls []Decl
 }
+
+// LookupName flattens an identifier or a dotted lookup (a.b.c) into its textual form, returns an empty
+// string for any other expression
+func LookupName(expr Expr) string {
+	switch e := expr.(type) {
+	case *Ident:
+		return e.Token.Value
+	case *BinaryOp:
+		if e.Operator.Value != "." {
+			return ""
+		}
+
+		left, right := LookupName(e.Left), LookupName(e.Right)
+		if left == "" || right == "" {
+			return ""
+		}
+
+		return left + "." + right
+	case *QualifiedName:
+		pkg := LookupName(e.Package)
+		if pkg == "" || e.Name == nil {
+			return ""
+		}
+
+		return pkg + "." + e.Name.Token.Value
+	}
+
+	return ""
+}
+
+// ExprLoc returns the location of the first token of an expression, definitions without tokens return
+// an empty location
+func ExprLoc(expr Expr) lexer.Location {
+	switch e := expr.(type) {
+	case *Literal:
+		return e.Token.Loc
+	case *BytesLit:
+		return e.Token.Loc
+	case *Ident:
+		return e.Token.Loc
+	case *Call:
+		return ExprLoc(e.Callee)
+	case *Index:
+		return ExprLoc(e.Base)
+	case *UnaryOp:
+		if e.Postfix {
+			return ExprLoc(e.Operand)
+		}
+		return e.Operator.Loc
+	case *BinaryOp:
+		return ExprLoc(e.Left)
+	case *QualifiedName:
+		return ExprLoc(e.Package)
+	case *OptionalType:
+		return e.Token.Loc
+	case *ListLit:
+		return e.Open.Loc
+	}
+
+	return lexer.Location{}
+}
@@ -0,0 +1,69 @@
+package parser
+
+import "reflect"
+
+// Clone returns a deep copy of a declaration, the copy shares no pointers or slices with the original
+func Clone(n Decl) Decl {
+	if n == nil {
+		return nil
+	}
+
+	return cloneValue(reflect.ValueOf(n)).Interface().(Decl)
+}
+
+// CloneExpr returns a deep copy of an expression, the copy shares no pointers or slices with the original
+func CloneExpr(e Expr) Expr {
+	if e == nil {
+		return nil
+	}
+
+	return cloneValue(reflect.ValueOf(e)).Interface().(Expr)
+}
+
+// CloneSchema returns a deep copy of an entire schema
+func CloneSchema(s *Schema) *Schema {
+	if s == nil {
+		return nil
+	}
+
+	return cloneValue(reflect.ValueOf(s)).Interface().(*Schema)
+}
+
+func cloneValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(cloneValue(v.Elem()))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+
+		c := reflect.New(v.Type()).Elem()
+		c.Set(cloneValue(v.Elem()))
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		for i := range v.NumField() {
+			c.Field(i).Set(cloneValue(v.Field(i)))
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			c.Index(i).Set(cloneValue(v.Index(i)))
+		}
+		return c
+	}
+
+	return v
+}
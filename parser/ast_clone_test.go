@@ -0,0 +1,44 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/cedmundo/SimpleSchema/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClone(t *testing.T) {
+	original, err := parser.NewFromString("clone", "[[ name = \"s\" ]]\ntype s struct { a : int; b : const(vec2); };").ParseAnnotatedDecl()
+	require.NoError(t, err)
+
+	clone := parser.Clone(original)
+	require.Equal(t, original, clone)
+	require.True(t, parser.Equal(original, clone))
+
+	// mutate the clone at several depths
+	cloneDecl := clone.(*parser.AnnotatedDecl)
+	cloneDecl.Annotations[0].Name.(*parser.Ident).Token.Value = "other"
+	cloneStruct := cloneDecl.Decl.(*parser.TypeDecl).Type.(*parser.StructDef)
+	cloneStruct.Block.Decls[0].(*parser.Field).Name.(*parser.Ident).Token.Value = "renamed"
+	cloneStruct.Block.Decls[1].(*parser.Field).Type.(*parser.Call).Args[0] = &parser.Ident{}
+	cloneStruct.Block.Decls = append(cloneStruct.Block.Decls[:1], &parser.Field{})
+
+	originalDecl := original.(*parser.AnnotatedDecl)
+	require.Equal(t, "name", parser.LookupName(originalDecl.Annotations[0].Name))
+	originalStruct := originalDecl.Decl.(*parser.TypeDecl).Type.(*parser.StructDef)
+	require.Len(t, originalStruct.Block.Decls, 2)
+	require.Equal(t, "a", parser.LookupName(originalStruct.Block.Decls[0].(*parser.Field).Name))
+	require.Equal(t, "vec2", parser.LookupName(originalStruct.Block.Decls[1].(*parser.Field).Type.(*parser.Call).Args[0]))
+}
+
+func TestCloneExpr(t *testing.T) {
+	original, err := parser.NewFromString("clone expr", "a + f(b)[2]").ParseExpr()
+	require.NoError(t, err)
+
+	clone := parser.CloneExpr(original)
+	require.True(t, parser.EqualExpr(original, clone))
+
+	clone.(*parser.BinaryOp).Left.(*parser.Ident).Token.Value = "z"
+	require.Equal(t, "a", original.(*parser.BinaryOp).Left.(*parser.Ident).Token.Value)
+	require.Nil(t, parser.CloneExpr(nil))
+}
@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/cedmundo/SimpleSchema/lexer"
+)
+
+var locationType = reflect.TypeOf(lexer.Location{})
+
+// Equal compares two declarations structurally, node kinds and values must match but locations are ignored
+func Equal(a, b Decl) bool {
+	return Diff(a, b) == ""
+}
+
+// EqualExpr compares two expressions structurally, node kinds and values must match but locations are ignored
+func EqualExpr(a, b Expr) bool {
+	return Diff(a, b) == ""
+}
+
+// Diff returns the path to the first difference between two nodes (a Decl, an Expr or a *Schema) or an empty
+// string if they are structurally equal. Paths start with the node type, like "TypeDecl.Name.Token.Value".
+// Nil and empty slices are considered equal.
+func Diff(a, b any) string {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if !va.IsValid() || !vb.IsValid() {
+		if va.IsValid() != vb.IsValid() {
+			return "<nil>"
+		}
+
+		return ""
+	}
+
+	root := reflect.Indirect(va).Type().Name()
+	return diffValues(root, va, vb)
+}
+
+func diffValues(path string, a, b reflect.Value) string {
+	if a.Kind() != b.Kind() {
+		return path
+	}
+
+	switch a.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				return path
+			}
+
+			return ""
+		}
+
+		if a.Elem().Type() != b.Elem().Type() {
+			return path
+		}
+
+		return diffValues(path, a.Elem(), b.Elem())
+	case reflect.Struct:
+		if a.Type() != b.Type() {
+			return path
+		}
+
+		for i := range a.NumField() {
+			field := a.Type().Field(i)
+			if field.Type == locationType {
+				continue
+			}
+
+			diff := diffValues(path+"."+field.Name, a.Field(i), b.Field(i))
+			if diff != "" {
+				return diff
+			}
+		}
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			return path
+		}
+
+		for i := range a.Len() {
+			diff := diffValues(fmt.Sprintf("%s[%d]", path, i), a.Index(i), b.Index(i))
+			if diff != "" {
+				return diff
+			}
+		}
+	default:
+		if !a.Equal(b) {
+			return path
+		}
+	}
+
+	return ""
+}
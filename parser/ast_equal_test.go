@@ -0,0 +1,83 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/cedmundo/SimpleSchema/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEqual(t *testing.T) {
+	cases := []struct {
+		name         string
+		left         string
+		right        string
+		expectedDiff string
+	}{
+		{
+			name:  "same source",
+			left:  "type s struct { a : int; };",
+			right: "type s struct { a : int; };",
+		},
+		{
+			name:  "differently indented sources",
+			left:  "type s struct { a : int; b : float[4]; };",
+			right: "type   s struct {\n    a :   int\n\tb : float[ 4 ]\n};",
+		},
+		{
+			name:         "different field name",
+			left:         "type s struct { a : int; };",
+			right:        "type s struct { b : int; };",
+			expectedDiff: "TypeDecl.Type.Block.Decls[0].Name.Token.Value",
+		},
+		{
+			name:         "different node kind",
+			left:         "type s struct {};",
+			right:        "type s union {};",
+			expectedDiff: "TypeDecl.Type",
+		},
+		{
+			name:         "different number of fields",
+			left:         "type s struct { a : int; };",
+			right:        "type s struct { a : int; b : int; };",
+			expectedDiff: "TypeDecl.Type.Block.Decls",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			left, err := parser.NewFromString("left", tt.left).ParseDecl()
+			require.NoError(t, err)
+
+			right, err := parser.NewFromString("right", tt.right).ParseDecl()
+			require.NoError(t, err)
+
+			require.Equal(t, tt.expectedDiff, parser.Diff(left, right))
+			require.Equal(t, tt.expectedDiff == "", parser.Equal(left, right))
+		})
+	}
+}
+
+func TestEqualExpr(t *testing.T) {
+	left, err := parser.NewFromString("left", "a + b * 2").ParseExpr()
+	require.NoError(t, err)
+
+	right, err := parser.NewFromString("right", "a+b*2").ParseExpr()
+	require.NoError(t, err)
+
+	other, err := parser.NewFromString("other", "(a + b) * 2").ParseExpr()
+	require.NoError(t, err)
+
+	require.True(t, parser.EqualExpr(left, right))
+	require.False(t, parser.EqualExpr(left, other))
+	require.False(t, parser.EqualExpr(left, nil))
+}
+
+func TestDiff_Schema(t *testing.T) {
+	left, err := parser.NewFromString("left", "module m\ntype a int\n").Parse()
+	require.NoError(t, err)
+
+	right, err := parser.NewFromString("right", "\nmodule m;\n\ntype a int;\n").Parse()
+	require.NoError(t, err)
+
+	require.Empty(t, parser.Diff(left, right))
+}
@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+var (
+	ErrImportCycle = errors.New("import cycle")
+)
+
+// LoadSchema parses the root file and every file it imports (transitively) from the file system, returning a
+// single schema. Imported declarations come before the declarations of the importing file and each file is
+// included once. Import paths are relative to the importing file, only the root file keeps its module and package.
+func LoadSchema(rootPath string, fsys fs.FS) (*Schema, error) {
+	loader := &schemaLoader{
+		fsys:    fsys,
+		loading: make(map[string]bool),
+		loaded:  make(map[string]bool),
+	}
+
+	decls, err := loader.load(path.Clean(rootPath), nil, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Schema{Decls: decls}, nil
+}
+
+type schemaLoader struct {
+	fsys    fs.FS
+	loading map[string]bool
+	loaded  map[string]bool
+}
+
+func (sl *schemaLoader) load(file string, chain []string, root bool) ([]Decl, error) {
+	chain = append(chain, file)
+	if sl.loading[file] {
+		return nil, fmt.Errorf("%w: %s", ErrImportCycle, strings.Join(chain, " -> "))
+	}
+
+	if sl.loaded[file] {
+		return nil, nil
+	}
+
+	content, err := fs.ReadFile(sl.fsys, file)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := NewFromString(file, string(content)).Parse()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	sl.loading[file] = true
+	decls := make([]Decl, 0, len(schema.Decls))
+	own := make([]Decl, 0, len(schema.Decls))
+	for _, decl := range schema.Decls {
+		switch d := unwrapAnnotated(decl).(type) {
+		case *ImportDecl:
+			literal, ok := d.Path.(*Literal)
+			if !ok {
+				return nil, fmt.Errorf("%s: %w import path", file, ErrUnexpectedToken)
+			}
+
+			imported, err := sl.load(path.Join(path.Dir(file), literal.Token.Value), chain, false)
+			if err != nil {
+				return nil, err
+			}
+
+			decls = append(decls, imported...)
+		case *ModuleDecl, *PackageDecl, *OptionsDecl:
+			if root {
+				own = append(own, decl)
+			}
+		default:
+			own = append(own, decl)
+		}
+	}
+
+	sl.loading[file] = false
+	sl.loaded[file] = true
+	return append(decls, own...), nil
+}
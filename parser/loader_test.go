@@ -0,0 +1,94 @@
+package parser_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/cedmundo/SimpleSchema/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSchema(t *testing.T) {
+	cases := []struct {
+		name          string
+		files         fstest.MapFS
+		expectedNames []string
+		expectedErr   error
+	}{
+		{
+			name: "single file",
+			files: fstest.MapFS{
+				"main.ss": {Data: []byte("module main\ntype a int\n")},
+			},
+			expectedNames: []string{"main", "a"},
+		},
+		{
+			name: "two files",
+			files: fstest.MapFS{
+				"main.ss":      {Data: []byte("module main\nimport \"lib/types.ss\"\ntype b struct {\nx : a\n}\n")},
+				"lib/types.ss": {Data: []byte("module types\ntype a int\n")},
+			},
+			expectedNames: []string{"a", "main", "b"},
+		},
+		{
+			name: "annotated module of an imported file",
+			files: fstest.MapFS{
+				"main.ss": {Data: []byte("[[ binding_name = \"m\" ]]\nmodule main\nimport \"b.ss\"\ntype b struct {\nx : a\n}\n")},
+				"b.ss":    {Data: []byte("[[ binding_name = \"b\" ]]\nmodule b\ntype a int\n")},
+			},
+			expectedNames: []string{"a", "main", "b"},
+		},
+		{
+			name: "shared import is loaded once",
+			files: fstest.MapFS{
+				"main.ss":   {Data: []byte("import \"left.ss\"\nimport \"right.ss\"\n")},
+				"left.ss":   {Data: []byte("import \"common.ss\"\ntype l int\n")},
+				"right.ss":  {Data: []byte("import \"common.ss\"\ntype r int\n")},
+				"common.ss": {Data: []byte("type c int\n")},
+			},
+			expectedNames: []string{"c", "l", "r"},
+		},
+		{
+			name: "import cycle",
+			files: fstest.MapFS{
+				"main.ss":  {Data: []byte("import \"other.ss\"\n")},
+				"other.ss": {Data: []byte("import \"main.ss\"\n")},
+			},
+			expectedErr: parser.ErrImportCycle,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, actualErr := parser.LoadSchema("main.ss", tt.files)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+			actualNames := make([]string, 0, len(schema.Decls))
+			for _, decl := range schema.Decls {
+				if annotated, ok := decl.(*parser.AnnotatedDecl); ok {
+					decl = annotated.Decl
+				}
+
+				switch d := decl.(type) {
+				case *parser.ModuleDecl:
+					actualNames = append(actualNames, parser.LookupName(d.Name))
+				case *parser.TypeDecl:
+					actualNames = append(actualNames, parser.LookupName(d.Name))
+				}
+			}
+			require.Equal(t, tt.expectedNames, actualNames)
+		})
+	}
+}
+
+func TestLoadSchema_MissingFile(t *testing.T) {
+	files := fstest.MapFS{
+		"main.ss": {Data: []byte("import \"missing.ss\"\n")},
+	}
+
+	_, err := parser.LoadSchema("main.ss", files)
+	require.Error(t, err)
+}
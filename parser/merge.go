@@ -0,0 +1,77 @@
+package parser
+
+import "errors"
+
+var (
+	ErrNameCollision = errors.New("name collision")
+)
+
+// Merge returns a new schema with the declarations of the base followed by the ones of the overlay. A type of
+// the overlay annotated with [[ override ]] replaces the base type of the same name in place, any other repeated
+// type or proc name is a collision. The base module and package are kept, the modules, packages and imports of the
+// overlay are dropped.
+func Merge(base, overlay *Schema) (*Schema, error) {
+	decls := make([]Decl, 0, len(base.Decls)+len(overlay.Decls))
+	positions := make(map[string]int)
+	for _, decl := range base.Decls {
+		name := mergeName(decl)
+		if name != "" {
+			positions[name] = len(decls)
+		}
+
+		decls = append(decls, decl)
+	}
+
+	for _, decl := range overlay.Decls {
+		switch unwrapped := unwrapAnnotated(decl).(type) {
+		case *ModuleDecl, *PackageDecl, *ImportDecl, *OptionsDecl:
+			continue
+		case *TypeDecl:
+			name := LookupName(unwrapped.Name)
+			annotated, _ := decl.(*AnnotatedDecl)
+			if i, ok := positions[name]; ok {
+				_, isType := unwrapAnnotated(decls[i]).(*TypeDecl)
+				if _, override := annotated.Find("override"); override && isType {
+					decls[i] = decl
+					continue
+				}
+
+				return nil, errorf(ExprLoc(unwrapped.Name), ErrNameCollision, "`%s`", name)
+			}
+		case *ProcDecl:
+			name := LookupName(unwrapped.Name)
+			if _, ok := positions[name]; ok {
+				return nil, errorf(ExprLoc(unwrapped.Name), ErrNameCollision, "`%s`", name)
+			}
+		}
+
+		name := mergeName(decl)
+		if name != "" {
+			positions[name] = len(decls)
+		}
+
+		decls = append(decls, decl)
+	}
+
+	return &Schema{Decls: decls}, nil
+}
+
+// mergeName returns the name of a type or proc declaration, or an empty string for any other declaration
+func mergeName(decl Decl) string {
+	switch d := unwrapAnnotated(decl).(type) {
+	case *TypeDecl:
+		return LookupName(d.Name)
+	case *ProcDecl:
+		return LookupName(d.Name)
+	}
+
+	return ""
+}
+
+func unwrapAnnotated(decl Decl) Decl {
+	if annotated, ok := decl.(*AnnotatedDecl); ok {
+		return unwrapAnnotated(annotated.Decl)
+	}
+
+	return decl
+}
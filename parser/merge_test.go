@@ -0,0 +1,87 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/cedmundo/SimpleSchema/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	cases := []struct {
+		name          string
+		base          string
+		overlay       string
+		expectedNames []string
+		expectedTypes []string
+		expectedErr   error
+	}{
+		{
+			name:          "clean merge",
+			base:          "module base;\ntype a int;\nproc f() -> a;",
+			overlay:       "module overlay;\ntype b float;\nproc g() -> b;",
+			expectedNames: []string{"base", "a", "f", "b", "g"},
+			expectedTypes: []string{"int", "float"},
+		},
+		{
+			name:          "override replaces in place",
+			base:          "type a int;\ntype b int;",
+			overlay:       "type c int;\n[[ override ]]\ntype a float;",
+			expectedNames: []string{"a", "b", "c"},
+			expectedTypes: []string{"float", "int", "int"},
+		},
+		{
+			name:        "type collision",
+			base:        "type a int;",
+			overlay:     "type a float;",
+			expectedErr: parser.ErrNameCollision,
+		},
+		{
+			name:        "proc collision",
+			base:        "proc f() -> void;",
+			overlay:     "proc f() -> int;",
+			expectedErr: parser.ErrNameCollision,
+		},
+		{
+			name:        "type cannot override a proc",
+			base:        "proc f() -> void;",
+			overlay:     "[[ override ]]\ntype f int;",
+			expectedErr: parser.ErrNameCollision,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			base, err := parser.NewFromString("base", tt.base).Parse()
+			require.NoError(t, err)
+			overlay, err := parser.NewFromString("overlay", tt.overlay).Parse()
+			require.NoError(t, err)
+
+			merged, actualErr := parser.Merge(base, overlay)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+			actualNames := make([]string, 0, len(merged.Decls))
+			actualTypes := make([]string, 0, len(merged.Decls))
+			for _, decl := range merged.Decls {
+				if annotated, ok := decl.(*parser.AnnotatedDecl); ok {
+					decl = annotated.Decl
+				}
+
+				switch d := decl.(type) {
+				case *parser.ModuleDecl:
+					actualNames = append(actualNames, parser.LookupName(d.Name))
+				case *parser.TypeDecl:
+					actualNames = append(actualNames, parser.LookupName(d.Name))
+					actualTypes = append(actualTypes, parser.LookupName(d.Type))
+				case *parser.ProcDecl:
+					actualNames = append(actualNames, parser.LookupName(d.Name))
+				}
+			}
+			require.Equal(t, tt.expectedNames, actualNames)
+			require.Equal(t, tt.expectedTypes, actualTypes)
+		})
+	}
+}
@@ -2,25 +2,60 @@ package parser
 
 import "github.com/cedmundo/SimpleSchema/lexer"
 
-// ParseDecl parses either type proc or module
+// ParseDecl parses either type proc module package import const or options, types and procs may be generic
 func (p *Parser) ParseDecl() (Decl, error) {
 	obj, err := p.expect(
 		lexer.Token{Tag: lexer.TokenTagWord, Value: "module"},
+		lexer.Token{Tag: lexer.TokenTagWord, Value: "package"},
 		lexer.Token{Tag: lexer.TokenTagWord, Value: "type"},
 		lexer.Token{Tag: lexer.TokenTagWord, Value: "proc"},
+		lexer.Token{Tag: lexer.TokenTagWord, Value: "import"},
+		lexer.Token{Tag: lexer.TokenTagWord, Value: "const"},
+		lexer.Token{Tag: lexer.TokenTagWord, Value: "options"},
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	name, err := p.ParseIdent()
+	if obj.Value == "import" {
+		return p.parseImport()
+	}
+
+	if obj.Value == "const" {
+		return p.parseConst()
+	}
+
+	if obj.Value == "options" {
+		return p.parseOptions(obj)
+	}
+
+	// module and package paths may be dotted (module net.http)
+	var name Expr
+	if obj.Value == "module" || obj.Value == "package" {
+		name, err = p.ParseLookup()
+	} else {
+		name, err = p.parseDeclName()
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	var params []*Ident
+	var constraints []*Constraint
+	if obj.Value == "type" || obj.Value == "proc" {
+		params, constraints, err = p.parseGenerics()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var expr Expr
+	isAlias := false
 	if obj.Value == "type" {
-		expr, err = p.ParseExpr()
+		_, err = p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: "="})
+		isAlias = err == nil
+
+		expr, err = p.ParseType()
 		if err != nil {
 			return nil, err
 		}
@@ -40,11 +75,75 @@ func (p *Parser) ParseDecl() (Decl, error) {
 		return &ModuleDecl{Name: name}, nil
 	}
 
+	if obj.Value == "package" {
+		return &PackageDecl{Name: name}, nil
+	}
+
 	if obj.Value == "proc" {
-		return &ProcDecl{Name: name, Type: expr}, nil
+		return &ProcDecl{Name: name, Type: expr, Params: params, Constraints: constraints}, nil
+	}
+
+	return &TypeDecl{Name: name, Type: expr, IsAlias: isAlias, Params: params, Constraints: constraints}, nil
+}
+
+// parseGenerics parses the generic parameters following a declared name and the constraints bounding them
+// ("<K, V> where K : Hashable, V : Stringer"), a declaration without parameters has no constraints either
+func (p *Parser) parseGenerics() ([]*Ident, []*Constraint, error) {
+	_, err := p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: "<"})
+	if err != nil {
+		return nil, nil, nil
+	}
+
+	params := make([]*Ident, 0)
+	for {
+		param, err := p.ParseIdent()
+		if err != nil {
+			return nil, nil, err
+		}
+		params = append(params, param.(*Ident))
+
+		sep, err := p.expect(
+			lexer.Token{Tag: lexer.TokenTagPunct, Value: ","},
+			lexer.Token{Tag: lexer.TokenTagPunct, Value: ">"},
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if sep.Value == ">" {
+			break
+		}
+	}
+
+	_, err = p.expect(lexer.Token{Tag: lexer.TokenTagWord, Value: "where"})
+	if err != nil {
+		return params, nil, nil
 	}
 
-	return &TypeDecl{Name: name, Type: expr}, nil
+	constraints := make([]*Constraint, 0)
+	for {
+		param, err := p.ParseIdent()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		_, err = p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: ":"})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// bounds name interfaces (Comparable, fmt.Stringer), the parameters list of a proc may follow them
+		bound, err := p.ParseLookup()
+		if err != nil {
+			return nil, nil, err
+		}
+		constraints = append(constraints, &Constraint{Param: param.(*Ident), Bound: qualifyType(bound)})
+
+		_, err = p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: ","})
+		if err != nil {
+			return params, constraints, nil
+		}
+	}
 }
 
 // ParseAnnotatedDecl annotations followed by types
@@ -64,3 +163,122 @@ func (p *Parser) ParseAnnotatedDecl() (Decl, error) {
 		Decl:        decl,
 	}, nil
 }
+
+// parseDeclName parses the name of a type, procedure or constant, reserved words must be escaped (`type`) to be
+// declared
+func (p *Parser) parseDeclName() (Expr, error) {
+	name, err := p.ParseIdent()
+	if err != nil {
+		return nil, err
+	}
+
+	if token := name.(*Ident).Token; token.IsKeyword() {
+		return nil, errorf(token.Loc, ErrReservedName, "`%s`", token.Value)
+	}
+
+	return name, nil
+}
+
+// parseImport parses the path of an import declaration ("import "file.ss"")
+func (p *Parser) parseImport() (Decl, error) {
+	path, err := p.expect(lexer.Token{Tag: lexer.TokenTagString})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.expect(lexer.Token{Tag: lexer.TokenTagEOL})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ImportDecl{Path: &Literal{Token: path}}, nil
+}
+
+// parseConst parses the name, type and value of a constant declaration ("const SIZE : int = 4 * 8")
+func (p *Parser) parseConst() (Decl, error) {
+	name, err := p.parseDeclName()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: ":"})
+	if err != nil {
+		return nil, err
+	}
+
+	typ, err := p.ParseType()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: "="})
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := p.ParseExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.expect(lexer.Token{Tag: lexer.TokenTagEOL})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConstDecl{Name: name, Type: typ, Value: value}, nil
+}
+
+// parseOptions parses the settings of an options directive ("options { namespace = "foo"; version = 2 }"), each
+// one ends like a field
+func (p *Parser) parseOptions(keyword lexer.Token) (Decl, error) {
+	_, err := p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: "{"})
+	if err != nil {
+		return nil, err
+	}
+
+	// Skip the end of line after "{" if needed
+	_, _ = p.expect(lexer.Token{Tag: lexer.TokenTagEOL})
+
+	options := make([]Option, 0)
+	for {
+		_, err = p.expect(lexer.Token{Tag: lexer.TokenTagComment}, lexer.Token{Tag: lexer.TokenTagEOL})
+		if err == nil {
+			continue
+		}
+
+		name, err := p.ParseIdent()
+		if err != nil {
+			break
+		}
+
+		_, err = p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: "="})
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+
+		err = p.expectFieldEnd()
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, Option{Name: name, Value: value})
+	}
+
+	_, err = p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: "}"})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.expect(lexer.Token{Tag: lexer.TokenTagEOL})
+	if err != nil {
+		return nil, err
+	}
+
+	return &OptionsDecl{Token: keyword, Block: OptionBlock{Options: options}}, nil
+}
@@ -1,6 +1,7 @@
 package parser_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/cedmundo/SimpleSchema/lexer"
@@ -93,6 +94,58 @@ func TestParser_ParseDecl(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:  "parse import decl",
+			input: "import \"types.ss\";",
+			expectedDecl: &parser.ImportDecl{
+				Path: &parser.Literal{Token: lexer.Token{
+					Tag:   lexer.TokenTagString,
+					Loc:   lexer.Location{File: "parse import decl", Row: 0, Col: 7},
+					Value: "types.ss",
+				}},
+			},
+		},
+		{
+			name:  "parse const decl",
+			input: "const SIZE : int = 4;",
+			expectedDecl: &parser.ConstDecl{
+				Name: &parser.Ident{Token: lexer.Token{
+					Tag:   lexer.TokenTagWord,
+					Loc:   lexer.Location{File: "parse const decl", Row: 0, Col: 6},
+					Value: "SIZE",
+				}},
+				Type: &parser.Ident{Token: lexer.Token{
+					Tag:   lexer.TokenTagWord,
+					Loc:   lexer.Location{File: "parse const decl", Row: 0, Col: 13},
+					Value: "int",
+				}},
+				Value: &parser.Literal{Token: lexer.Token{
+					Tag:   lexer.TokenTagDecInt,
+					Loc:   lexer.Location{File: "parse const decl", Row: 0, Col: 19},
+					Value: "4",
+				}},
+			},
+		},
+		{
+			name:        "fails to parse const without value",
+			input:       "const SIZE : int;",
+			expectedErr: parser.ErrUnexpectedToken,
+		},
+		{
+			name:        "fails to parse import without path",
+			input:       "import types;",
+			expectedErr: parser.ErrUnexpectedToken,
+		},
+		{
+			name:        "fails to parse type named after a keyword",
+			input:       "type struct int;",
+			expectedErr: parser.ErrReservedName,
+		},
+		{
+			name:        "fails to parse const named after a keyword",
+			input:       "const in : int = 4;",
+			expectedErr: parser.ErrReservedName,
+		},
 	}
 	for _, tt := range cases {
 		t.Run(tt.name, func(t *testing.T) {
@@ -108,3 +161,456 @@ func TestParser_ParseDecl(t *testing.T) {
 		})
 	}
 }
+
+func TestParse_ReservedName(t *testing.T) {
+	cases := []struct {
+		name         string
+		input        string
+		expectedName string
+		expectedErr  error
+	}{
+		{
+			name:        "keyword",
+			input:       "proc union() -> void\ntype a int\n",
+			expectedErr: parser.ErrReservedName,
+		},
+		{
+			name:         "escaped keyword",
+			input:        "proc `union`() -> void\n",
+			expectedName: "union",
+		},
+		{
+			name:         "builtin shadowed",
+			input:        "type u8 int\n",
+			expectedName: "u8",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := parser.NewFromString(tt.name, tt.input).Parse()
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, err, tt.expectedErr)
+				require.ErrorContains(t, err, "keyword:0:5")
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, schema.Decls, 1)
+			require.Equal(t, tt.expectedName, testDeclName(schema.Decls[0]))
+		})
+	}
+}
+
+func TestParser_ParseProcReturnType(t *testing.T) {
+	cases := []struct {
+		name         string
+		input        string
+		expectedType parser.Expr
+		expectedErr  error
+	}{
+		{
+			name:         "single return type",
+			input:        "proc f() -> int;",
+			expectedType: ident("int"),
+		},
+		{
+			name:         "tuple return type",
+			input:        "proc f() -> (int, string);",
+			expectedType: &parser.TupleType{Elements: []parser.Expr{ident("int"), ident("string")}},
+		},
+		{
+			name:  "tuple of composite types",
+			input: "proc f(a : int) -> (*u8, set[string], float[2]);",
+			expectedType: &parser.TupleType{Elements: []parser.Expr{
+				unary("*", ident("u8")),
+				&parser.SetType{Element: ident("string")},
+				&parser.Index{Base: ident("float"), Index: decInt("2")},
+			}},
+		},
+		{
+			name:         "grouped single return type",
+			input:        "proc f() -> (int);",
+			expectedType: ident("int"),
+		},
+		{
+			name:        "unclosed tuple return type",
+			input:       "proc f() -> (int, string;",
+			expectedErr: parser.ErrUnclosedParenthesis,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			decl, actualErr := parser.NewFromString(tt.name, tt.input).ParseDecl()
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+			def := decl.(*parser.ProcDecl).Type.(*parser.PrototypeDef)
+			require.Empty(t, parser.Diff(tt.expectedType, def.ReturnType))
+		})
+	}
+}
+
+func TestParser_ParsePrototypeDefTupleReturnType(t *testing.T) {
+	expr, err := parser.NewFromString("prototype", "proc(int) -> (int, bool)").ParsePrototypeDef()
+	require.NoError(t, err)
+
+	expected := &parser.TupleType{Elements: []parser.Expr{ident("int"), ident("bool")}}
+	require.Empty(t, parser.Diff(expected, expr.(*parser.PrototypeDef).ReturnType))
+}
+
+func TestParser_ParseAnnotatedParams(t *testing.T) {
+	input := "proc([[ doc = \"the key\" ]] key : string, @unused() value : int) -> bool"
+	expr, err := parser.NewFromString("annotated params", input).ParsePrototypeDef()
+	require.NoError(t, err)
+
+	doc := &parser.Literal{Token: lexer.Token{Tag: lexer.TokenTagString, Value: "the key"}}
+	expected := &parser.PrototypeDef{
+		Params: []parser.Field{
+			{Name: ident("key"), Type: ident("string"), Annotations: []*parser.Annotation{{Name: ident("doc"), Value: doc}}},
+			{Name: ident("value"), Type: ident("int"), Annotations: []*parser.Annotation{{Name: ident("unused")}}},
+		},
+		ReturnType: ident("bool"),
+	}
+	require.Empty(t, parser.Diff(expected, expr))
+}
+
+func TestParser_ParseMalformedParamAnnotations(t *testing.T) {
+	_, err := parser.NewFromString("malformed param annotations", "proc f([[ doc = \"a\" k : int) -> int;").ParseDecl()
+	require.ErrorIs(t, err, parser.ErrUnexpectedToken)
+}
+
+func TestParser_ParseAliasDecl(t *testing.T) {
+	cases := []struct {
+		name         string
+		input        string
+		expectedDecl parser.Decl
+		expectedErr  error
+	}{
+		{
+			name:         "alias of a named type",
+			input:        "type size = u64\n",
+			expectedDecl: &parser.TypeDecl{Name: ident("size"), Type: ident("u64"), IsAlias: true},
+		},
+		{
+			name:  "alias of an inline struct",
+			input: "type point = struct { x : int }\n",
+			expectedDecl: &parser.TypeDecl{Name: ident("point"), IsAlias: true, Type: &parser.StructDef{
+				Block: parser.Block{Decls: []parser.Decl{&parser.Field{Name: ident("x"), Type: ident("int")}}},
+			}},
+		},
+		{
+			name:  "struct definition",
+			input: "type point struct { x : int }\n",
+			expectedDecl: &parser.TypeDecl{Name: ident("point"), Type: &parser.StructDef{
+				Block: parser.Block{Decls: []parser.Decl{&parser.Field{Name: ident("x"), Type: ident("int")}}},
+			}},
+		},
+		{
+			name:        "alias without type",
+			input:       "type size =\n",
+			expectedErr: parser.ErrUnexpectedToken,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualDecl, actualErr := parser.NewFromString(tt.name, tt.input).ParseDecl()
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+			require.Empty(t, parser.Diff(tt.expectedDecl, actualDecl))
+		})
+	}
+}
+
+func TestParser_ParseGenericDecl(t *testing.T) {
+	list := &parser.StructDef{Block: parser.Block{Decls: []parser.Decl{
+		&parser.Field{Name: ident("items"), Type: &parser.Index{Base: ident("T")}},
+	}}}
+	cases := []struct {
+		name         string
+		input        string
+		expectedDecl parser.Decl
+		expectedErr  error
+	}{
+		{
+			name:         "parameters without constraints",
+			input:        "type List<T> struct { items : T[] }\n",
+			expectedDecl: &parser.TypeDecl{Name: ident("List"), Type: list, Params: []*parser.Ident{ident("T")}},
+		},
+		{
+			name:  "single constraint",
+			input: "type List<T> where T : Comparable struct { items : T[] }\n",
+			expectedDecl: &parser.TypeDecl{
+				Name:        ident("List"),
+				Type:        list,
+				Params:      []*parser.Ident{ident("T")},
+				Constraints: []*parser.Constraint{{Param: ident("T"), Bound: ident("Comparable")}},
+			},
+		},
+		{
+			name:  "multiple constraints",
+			input: "type Table<K, V> where K : Hashable, V : fmt.Stringer = map[K]V\n",
+			expectedDecl: &parser.TypeDecl{
+				Name:    ident("Table"),
+				Type:    &parser.MapType{Key: ident("K"), Value: ident("V")},
+				IsAlias: true,
+				Params:  []*parser.Ident{ident("K"), ident("V")},
+				Constraints: []*parser.Constraint{
+					{Param: ident("K"), Bound: ident("Hashable")},
+					{Param: ident("V"), Bound: &parser.QualifiedName{Package: ident("fmt"), Name: ident("Stringer")}},
+				},
+			},
+		},
+		{
+			name:  "proc constraint",
+			input: "proc max<T> where T : Ordered (a: T, b: T) -> T\n",
+			expectedDecl: &parser.ProcDecl{
+				Name: ident("max"),
+				Type: &parser.PrototypeDef{
+					Params:     []parser.Field{{Name: ident("a"), Type: ident("T")}, {Name: ident("b"), Type: ident("T")}},
+					ReturnType: ident("T"),
+				},
+				Params:      []*parser.Ident{ident("T")},
+				Constraints: []*parser.Constraint{{Param: ident("T"), Bound: ident("Ordered")}},
+			},
+		},
+		{
+			name:        "empty parameters",
+			input:       "type List<> struct {}\n",
+			expectedErr: parser.ErrUnexpectedToken,
+		},
+		{
+			name:        "constraint without bound",
+			input:       "type List<T> where T struct {}\n",
+			expectedErr: parser.ErrUnexpectedToken,
+		},
+		{
+			name:        "trailing comma in constraints",
+			input:       "type List<T> where T : Comparable, struct {}\n",
+			expectedErr: parser.ErrUnexpectedToken,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualDecl, actualErr := parser.NewFromString(tt.name, tt.input).ParseDecl()
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+			require.Empty(t, parser.Diff(tt.expectedDecl, actualDecl))
+		})
+	}
+}
+
+func TestParseDeclString(t *testing.T) {
+	cases := []struct {
+		name         string
+		input        string
+		expectedDecl parser.Decl
+		expectedErr  error
+	}{
+		{
+			name:         "lone type",
+			input:        "type s struct {\na : int\n}\n\n",
+			expectedDecl: &parser.TypeDecl{Name: ident("s"), Type: &parser.StructDef{Block: parser.Block{Decls: []parser.Decl{&parser.Field{Name: ident("a"), Type: ident("int")}}}}},
+		},
+		{
+			name:  "lone proc",
+			input: "\nproc f(a : int) -> void;",
+			expectedDecl: &parser.ProcDecl{Name: ident("f"), Type: &parser.PrototypeDef{
+				Params:     []parser.Field{{Name: ident("a"), Type: ident("int")}},
+				ReturnType: ident("void"),
+			}},
+		},
+		{
+			name:  "annotated type",
+			input: "[[ opaque ]]\ntype s struct {}\n",
+			expectedDecl: &parser.AnnotatedDecl{
+				Annotations: []*parser.Annotation{{Name: ident("opaque")}},
+				Decl:        &parser.TypeDecl{Name: ident("s"), Type: &parser.StructDef{Block: parser.Block{Decls: []parser.Decl{}}}},
+			},
+		},
+		{
+			name:        "more than one declaration",
+			input:       "type a int;\ntype b int;\n",
+			expectedErr: parser.ErrUnexpectedToken,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualDecl, actualErr := parser.ParseDeclString(tt.name, tt.input)
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+			require.Empty(t, parser.Diff(tt.expectedDecl, actualDecl))
+		})
+	}
+}
+
+func TestParser_ParseDottedModule(t *testing.T) {
+	decl, err := parser.NewFromString("dotted module", "module net.http;").ParseDecl()
+	require.NoError(t, err)
+
+	module := decl.(*parser.ModuleDecl)
+	require.Empty(t, parser.Diff(binary(".", ident("net"), ident("http")), module.Name))
+	require.Equal(t, "net.http", parser.LookupName(module.Name))
+}
+
+func TestParser_ParsePackageDecl(t *testing.T) {
+	cases := []struct {
+		name         string
+		input        string
+		expectedName parser.Expr
+	}{
+		{
+			name:         "package",
+			input:        "package foo;",
+			expectedName: ident("foo"),
+		},
+		{
+			name:         "dotted package",
+			input:        "package foo.bar;",
+			expectedName: binary(".", ident("foo"), ident("bar")),
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			decl, err := parser.NewFromString(tt.name, tt.input).ParseDecl()
+			require.NoError(t, err)
+
+			pkg := decl.(*parser.PackageDecl)
+			require.Empty(t, parser.Diff(tt.expectedName, pkg.Name))
+		})
+	}
+}
+
+func TestParse_PackageWithModule(t *testing.T) {
+	schema, err := parser.NewFromString("package and module", "module net.http;\npackage web.api;\ntype s struct {\na : int\n}\n").Parse()
+	require.NoError(t, err)
+	require.Len(t, schema.Decls, 3)
+
+	module := schema.Decls[0].(*parser.ModuleDecl)
+	require.Equal(t, "net.http", parser.LookupName(module.Name))
+
+	pkg := schema.Decls[1].(*parser.PackageDecl)
+	require.Equal(t, "web.api", parser.LookupName(pkg.Name))
+}
+
+func TestParser_ParseOptions(t *testing.T) {
+	cases := []struct {
+		name            string
+		input           string
+		expectedOptions []parser.Option
+		expectedErr     error
+	}{
+		{
+			name:  "options directive",
+			input: "options { namespace = \"foo\"; version = 2; }\ntype a int;\n",
+			expectedOptions: []parser.Option{
+				{Name: ident("namespace"), Value: &parser.Literal{Token: lexer.Token{Tag: lexer.TokenTagString, Value: "foo"}}},
+				{Name: ident("version"), Value: decInt("2")},
+			},
+		},
+		{
+			name:  "options on multiple lines",
+			input: "options {\n  # generated names\n  namespace = \"foo\"\n  version = 1 + 1\n}\n",
+			expectedOptions: []parser.Option{
+				{Name: ident("namespace"), Value: &parser.Literal{Token: lexer.Token{Tag: lexer.TokenTagString, Value: "foo"}}},
+				{Name: ident("version"), Value: binary("+", decInt("1"), decInt("1"))},
+			},
+		},
+		{
+			name:            "empty options",
+			input:           "options {}\n",
+			expectedOptions: []parser.Option{},
+		},
+		{
+			name:        "duplicate options",
+			input:       "options { version = 1; }\ntype a int;\noptions { version = 2; }\n",
+			expectedErr: parser.ErrDuplicateOptions,
+		},
+		{
+			name:        "option without value",
+			input:       "options { version; }\n",
+			expectedErr: parser.ErrUnexpectedToken,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, actualErr := parser.NewFromString(tt.name, tt.input).Parse()
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+			options, ok := schema.Decls[0].(*parser.OptionsDecl)
+			require.True(t, ok)
+			require.Len(t, options.Block.Options, len(tt.expectedOptions))
+			for i, expected := range tt.expectedOptions {
+				require.Empty(t, parser.Diff(expected.Name, options.Block.Options[i].Name))
+				require.Empty(t, parser.Diff(expected.Value, options.Block.Options[i].Value))
+			}
+		})
+	}
+}
+
+func TestParser_LastErrorLocation(t *testing.T) {
+	cases := []struct {
+		name        string
+		input       string
+		expectedBad string
+	}{
+		{
+			name:        "missing field separator",
+			input:       "type s struct { a : int b : int }\n",
+			expectedBad: ":",
+		},
+		{
+			name:        "stray token",
+			input:       "type a int;\ntype b int ) ;\n",
+			expectedBad: ")",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parser.NewFromString(tt.name, tt.input)
+			_, err := p.Parse()
+			require.ErrorIs(t, err, parser.ErrUnexpectedToken)
+			require.ErrorContains(t, err, "`"+tt.expectedBad+"`")
+
+			// the location of the last token with the bad value, as located by the lexer
+			var expectedLoc lexer.Location
+			lex := lexer.New(tt.name, strings.NewReader(tt.input))
+			for {
+				token, err := lex.Read()
+				require.NoError(t, err)
+				if token.Tag == lexer.TokenTagEOF {
+					break
+				}
+
+				if token.Value == tt.expectedBad {
+					expectedLoc = token.Loc
+				}
+			}
+			require.Equal(t, expectedLoc, p.LastErrorLocation())
+		})
+	}
+
+	// the alternatives tried while parsing do not leave a location behind
+	p := parser.NewFromString("valid", "type s struct { a : int; b : float; }\n")
+	_, err := p.Parse()
+	require.NoError(t, err)
+	require.Equal(t, lexer.Location{}, p.LastErrorLocation())
+}
@@ -1,28 +1,62 @@
 package parser
 
 import (
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/cedmundo/SimpleSchema/lexer"
 )
 
 var (
-	// punctuation by precedence
+	// punctuation by precedence, the default table of every parser (see SetOperatorPrecedence)
 	punctPrec = map[int][]string{
-		9: {"||"},
-		8: {"&&"},
-		7: {"|"},
-		6: {"^"},
-		5: {"&"},
-		4: {"==", "!="},
-		3: {"<", ">", "<=", ">="},
-		2: {"+", "-"},
-		1: {"*", "/", "%"},
-	}
-	maxPrec = 9
+		11: {"||"},
+		10: {"&&"},
+		9:  {"|"},
+		8:  {"^"},
+		7:  {"&"},
+		6:  {"==", "!="},
+		5:  {"<", ">", "<=", ">="},
+		4:  {"<<", ">>"},
+		3:  {"+", "-"},
+		2:  {"*", "/", "%"},
+		1:  {"**"},
+	}
+	maxPrec = 11
+
+	// rightAssoc are the binary operators grouping from the right (2 ** 3 ** 2 is 2 ** (3 ** 2))
+	rightAssoc = map[string]bool{"**": true}
 )
 
+// DefaultOperatorPrecedence returns a copy of the binary operators table used by new parsers, higher levels bind
+// looser (1 is "**", 11 is "||")
+func DefaultOperatorPrecedence() map[int][]string {
+	return copyPrecedence(punctPrec)
+}
+
+// SetOperatorPrecedence replaces the binary operators table of the parser, operators are grouped by level and
+// higher levels bind looser. Level 0 is reserved for unary operators and ignored. The operators must be
+// punctuations known by the lexer.
+func (p *Parser) SetOperatorPrecedence(table map[int][]string) {
+	p.prec = copyPrecedence(table)
+	p.maxPrec = 0
+	for level := range p.prec {
+		p.maxPrec = max(p.maxPrec, level)
+	}
+}
+
+func copyPrecedence(table map[int][]string) map[int][]string {
+	copied := make(map[int][]string, len(table))
+	for level, puncts := range table {
+		if level > 0 {
+			copied[level] = slices.Clone(puncts)
+		}
+	}
+	return copied
+}
+
 // ParseIdent tries to parse an identifier, returns error if token is not an id
 func (p *Parser) ParseIdent() (Expr, error) {
 	token, err := p.expect(lexer.Token{Tag: lexer.TokenTagWord})
@@ -41,6 +75,7 @@ func (p *Parser) ParseLiteral() (Expr, error) {
 		lexer.Token{Tag: lexer.TokenTagOctInt},
 		lexer.Token{Tag: lexer.TokenTagHexInt},
 		lexer.Token{Tag: lexer.TokenTagString},
+		lexer.Token{Tag: lexer.TokenTagChar},
 		lexer.Token{Tag: lexer.TokenTagFloat},
 	)
 	if err != nil {
@@ -50,20 +85,64 @@ func (p *Parser) ParseLiteral() (Expr, error) {
 	return &Literal{Token: token}, nil
 }
 
+// ParseBytesLit tries to parse a byte string literal (0x[DEADBEEF]), each pair of hex digits is a byte so an odd
+// number of digits is an error (the literal is left unread)
+func (p *Parser) ParseBytesLit() (Expr, error) {
+	token, err := p.expect(lexer.Token{Tag: lexer.TokenTagBytes})
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, err := hex.DecodeString(token.Value)
+	if err != nil {
+		p.lastErrLoc = token.Loc
+		unreadErr := p.lex.Unread(token)
+		if unreadErr != nil {
+			return nil, unreadErr
+		}
+
+		return nil, errorf(token.Loc, ErrMalformedBytes, "odd number of hex digits in `0x[%s]`", token.Value)
+	}
+
+	return &BytesLit{Token: token, Bytes: decoded}, nil
+}
+
 func (p *Parser) parseField() (Decl, error) {
 	field := &Field{}
 	err := error(nil)
 
-	// name (: type)? (= value)?
+	// tag? name (: type (in low..high)?)? (= value)?
 	field.Name, err = p.ParseLookup()
 	if err != nil {
 		return nil, err
 	}
 
+	// within unions a leading tag word marks the discriminant, a member named tag (tag : u8) is still a plain one
+	if name, ok := field.Name.(*Ident); ok && p.inUnion && name.Token.Value == "tag" && !name.Token.Escaped {
+		field.Name, err = p.ParseLookup()
+		if errors.Is(err, ErrUnexpectedToken) {
+			field.Name = name
+		} else if err != nil {
+			return nil, err
+		} else {
+			field.Tag = true
+		}
+	}
+
+	return p.parseFieldRest(field)
+}
+
+// parseFieldRest parses what follows the name of a field: its type, range, value and the end of line
+func (p *Parser) parseFieldRest(field *Field) (Decl, error) {
 	// type
-	_, err = p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: ":"})
+	_, err := p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: ":"})
 	if err == nil {
-		field.Type, err = p.ParseExpr()
+		field.Type, err = p.ParseType()
+		if err != nil {
+			return nil, err
+		}
+
+		field.Range, err = p.parseRangeConstraint()
 		if err != nil {
 			return nil, err
 		}
@@ -79,34 +158,191 @@ func (p *Parser) parseField() (Decl, error) {
 	}
 
 	// end of line
-	_, err = p.expect(lexer.Token{Tag: lexer.TokenTagEOL})
+	err = p.expectFieldEnd()
 	return field, err
 }
 
+// parseRangeConstraint parses the optional range of a field type (in low..high), returns nil without one
+func (p *Parser) parseRangeConstraint() (*RangeConstraint, error) {
+	_, err := p.expect(lexer.Token{Tag: lexer.TokenTagWord, Value: "in"})
+	if err != nil {
+		return nil, nil
+	}
+
+	low, err := p.ParseExpr()
+	if err != nil {
+		return nil, fmt.Errorf("%w: low end: %w", ErrMalformedRange, err)
+	}
+
+	_, err = p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: ".."})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedRange, err)
+	}
+
+	high, err := p.ParseExpr()
+	if err != nil {
+		return nil, fmt.Errorf("%w: high end: %w", ErrMalformedRange, err)
+	}
+
+	return &RangeConstraint{Low: low, High: high}, nil
+}
+
+// expectFieldEnd accepts the end of line (new line or ";") after a field, a trailing comment also ends the line
+// and the last field of a block may end with the closing brace (or the end of file in a field list), those are
+// left for the enclosing rule
+func (p *Parser) expectFieldEnd() error {
+	token, err := p.expect(
+		lexer.Token{Tag: lexer.TokenTagEOL},
+		lexer.Token{Tag: lexer.TokenTagComment},
+		lexer.Token{Tag: lexer.TokenTagPunct, Value: "}"},
+		lexer.Token{Tag: lexer.TokenTagEOF},
+	)
+	if err != nil {
+		return err
+	}
+
+	if token.Tag == lexer.TokenTagEOL {
+		return nil
+	}
+
+	return p.lex.Unread(token)
+}
+
+// parseAnnotations parses a sequence of annotation groups, written either between brackets ([[ a = b ]]) or
+// with the sigil form (@a(b)), both forms can be mixed
 func (p *Parser) parseAnnotations() ([]*Annotation, error) {
-	_, err := p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: "[["})
+	annotations := make([]*Annotation, 0)
+	for i := 0; ; i++ {
+		token, err := p.expect(
+			lexer.Token{Tag: lexer.TokenTagPunct, Value: "[["},
+			lexer.Token{Tag: lexer.TokenTagPunct, Value: "@"},
+		)
+		if err != nil && i == 0 {
+			return nil, err
+		} else if err != nil {
+			break
+		}
+
+		err = p.lex.Unread(token)
+		if err != nil {
+			return nil, err
+		}
+
+		var group []*Annotation
+		if token.Value == "@" {
+			group, err = p.parseSigilAnnotation()
+		} else {
+			group, err = p.parseBracketAnnotations()
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		annotations = append(annotations, group...)
+	}
+
+	return annotations, nil
+}
+
+// parseSigilAnnotation parses @name, @name(value) and @name(key = value, ...), producing the same annotations
+// as [[ name ]], [[ name = value ]] and [[ name.key = value, ... ]]
+func (p *Parser) parseSigilAnnotation() ([]*Annotation, error) {
+	_, err := p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: "@"})
 	if err != nil {
 		return nil, err
 	}
 
+	name, err := p.ParseLookup()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: "("})
+	if err != nil {
+		_, _ = p.expect(lexer.Token{Tag: lexer.TokenTagEOL})
+		return []*Annotation{{Name: name}}, nil
+	}
+
 	// we want to ignore new lines in this section
 	p.lex.PushGroup()
 
 	annotations := make([]*Annotation, 0)
 	for {
-		name, err := p.ParseLookup()
+		value, err := p.ParseExpr()
 		if err != nil {
 			break
 		}
 
-		_, err = p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: "="})
+		equal, err := p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: "="})
+		if err == nil {
+			if LookupName(value) == "" {
+				return nil, fmt.Errorf("%w: annotation key", ErrUnexpectedToken)
+			}
+
+			key := value
+			value, err = p.ParseExpr()
+			if err != nil {
+				return nil, err
+			}
+
+			dot := lexer.Token{Tag: lexer.TokenTagPunct, Loc: equal.Loc, Value: "."}
+			annotations = append(annotations, &Annotation{
+				Name:  &BinaryOp{Operator: dot, Left: name, Right: key},
+				Value: value,
+			})
+		} else {
+			annotations = append(annotations, &Annotation{Name: name, Value: value})
+		}
+
+		_, err = p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: ","})
 		if err != nil {
-			return nil, err
+			break
 		}
+	}
 
-		value, err := p.ParseExpr()
+	err = p.lex.PopGroup()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: ")"})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", err, ErrUnclosedParenthesis)
+	}
+
+	// @name() is a flag as well
+	if len(annotations) == 0 {
+		annotations = append(annotations, &Annotation{Name: name})
+	}
+
+	_, _ = p.expect(lexer.Token{Tag: lexer.TokenTagEOL})
+	return annotations, nil
+}
+
+func (p *Parser) parseBracketAnnotations() ([]*Annotation, error) {
+	_, err := p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: "[["})
+	if err != nil {
+		return nil, err
+	}
+
+	// we want to ignore new lines in this section
+	p.lex.PushGroup()
+
+	annotations := make([]*Annotation, 0)
+	for {
+		name, err := p.ParseLookup()
 		if err != nil {
-			return nil, err
+			break
+		}
+
+		// an annotation without value is a flag ([[ opaque ]])
+		var value Expr
+		_, err = p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: "="})
+		if err == nil {
+			value, err = p.ParseExpr()
+			if err != nil {
+				return nil, err
+			}
 		}
 
 		annotations = append(annotations, &Annotation{
@@ -157,28 +393,134 @@ func (p *Parser) parseTypeBlock() (Block, error) {
 		return Block{}, err
 	}
 
-	// Skip the end of line after "{" if needed
-	_, _ = p.expect(lexer.Token{Tag: lexer.TokenTagEOL})
+	decls, err := p.parseBlockDecls()
+	if err != nil {
+		return Block{}, err
+	}
+
+	_, err = p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: "}"})
+	return Block{Decls: decls}, err
+}
+
+// ParseFieldList parses a sequence of fields outside of any block (a : int; b : float), stopping at the first token
+// that does not start a field. Only plain fields are accepted since the annotations cannot be kept, the comments
+// around them are kept like in blocks.
+func (p *Parser) ParseFieldList() ([]Field, error) {
+	decls, err := p.parseBlockDecls()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]Field, 0, len(decls))
+	for _, decl := range decls {
+		field, ok := decl.(*Field)
+		if !ok {
+			return nil, errorf(ExprLoc(blockField(decl).Name), ErrUnexpectedToken, "annotated field in a field list")
+		}
+
+		fields = append(fields, *field)
+	}
+
+	return fields, nil
+}
 
+// parseBlockDecls parses the fields of a block along with their comments, up to the first token that does not
+// start a field. Malformed byte strings and includes are returned as errors since they would end the block at them.
+func (p *Parser) parseBlockDecls() ([]Decl, error) {
 	decls := make([]Decl, 0)
+	leading := make([]lexer.Token, 0)
+	var last *Field
 	for {
-		annotationSection, err := p.ParseAnnotatedField()
+		// comments consume their own new line, so the end of line of any blank line after them is left here
+		_, err := p.expect(lexer.Token{Tag: lexer.TokenTagEOL})
 		if err == nil {
-			decls = append(decls, annotationSection)
 			continue
 		}
 
-		field, err := p.parseField()
+		// comments on the same row of the previous field trail it, otherwise they lead the next one
+		comment, err := p.expect(lexer.Token{Tag: lexer.TokenTagComment})
 		if err == nil {
-			decls = append(decls, field)
+			if last != nil && last.Trailing == nil && ExprLoc(last.Name).Row == comment.Loc.Row {
+				last.Trailing = &comment
+			} else {
+				leading = append(leading, comment)
+			}
 			continue
 		}
 
-		break
+		decl, err := p.parseEmbed()
+		if errors.Is(err, ErrUnexpectedToken) && !committed(err) {
+			decl, err = p.ParseAnnotatedField()
+		}
+		if err != nil && !committed(err) {
+			decl, err = p.parseField()
+		}
+		if committed(err) {
+			return nil, err
+		}
+		if err != nil {
+			break
+		}
+
+		last = blockField(decl)
+		if last != nil && len(leading) > 0 {
+			last.Leading = leading
+			leading = make([]lexer.Token, 0)
+		}
+		decls = append(decls, decl)
 	}
 
-	_, err = p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: "}"})
-	return Block{Decls: decls}, err
+	return decls, nil
+}
+
+// parseEmbed parses the inclusion of the fields of another struct (include Base)
+func (p *Parser) parseEmbed() (Decl, error) {
+	word, err := p.expect(lexer.Token{Tag: lexer.TokenTagWord, Value: "include"})
+	if err != nil {
+		return nil, err
+	}
+
+	// a field named include (include : int) is still a plain one
+	next, err := p.expect(
+		lexer.Token{Tag: lexer.TokenTagPunct, Value: ":"},
+		lexer.Token{Tag: lexer.TokenTagPunct, Value: "="},
+		lexer.Token{Tag: lexer.TokenTagEOL},
+		lexer.Token{Tag: lexer.TokenTagComment},
+		lexer.Token{Tag: lexer.TokenTagPunct, Value: "}"},
+		lexer.Token{Tag: lexer.TokenTagEOF},
+	)
+	if err == nil {
+		err = p.lex.Unread(next)
+		if err != nil {
+			return nil, err
+		}
+
+		return p.parseFieldRest(&Field{Name: &Ident{Token: word}})
+	}
+
+	// past this point the include is committed to, its errors are not retried as a field
+	typ, err := p.ParseType()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedEmbed, err)
+	}
+
+	err = p.expectFieldEnd()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrMalformedEmbed, err)
+	}
+
+	return &EmbedDecl{Type: typ}, nil
+}
+
+func blockField(decl Decl) *Field {
+	switch d := decl.(type) {
+	case *Field:
+		return d
+	case *AnnotatedDecl:
+		return blockField(d.Decl)
+	}
+
+	return nil
 }
 
 // ParseStructDef tries to parse next expression as an struct definition
@@ -188,7 +530,10 @@ func (p *Parser) ParseStructDef() (Expr, error) {
 		return nil, err
 	}
 
+	inUnion := p.inUnion
+	p.inUnion = false
 	block, err := p.parseTypeBlock()
+	p.inUnion = inUnion
 	if err != nil {
 		return nil, err
 	}
@@ -203,7 +548,10 @@ func (p *Parser) ParseUnionDef() (Expr, error) {
 		return nil, err
 	}
 
+	inUnion := p.inUnion
+	p.inUnion = true
 	block, err := p.parseTypeBlock()
+	p.inUnion = inUnion
 	if err != nil {
 		return nil, err
 	}
@@ -218,7 +566,10 @@ func (p *Parser) ParseEnumDef() (Expr, error) {
 		return nil, err
 	}
 
+	inUnion := p.inUnion
+	p.inUnion = false
 	block, err := p.parseTypeBlock()
+	p.inUnion = inUnion
 	if err != nil {
 		return nil, err
 	}
@@ -237,6 +588,25 @@ func (p *Parser) parseArgsWithReturnType() (Expr, error) {
 	for {
 		var paramName Expr
 		var paramType Expr
+		var annotations []*Annotation
+		// parameters without annotations are fine, malformed annotations are not
+		var token lexer.Token
+		token, err = p.expect(
+			lexer.Token{Tag: lexer.TokenTagPunct, Value: "[["},
+			lexer.Token{Tag: lexer.TokenTagPunct, Value: "@"},
+		)
+		if err == nil {
+			err = p.lex.Unread(token)
+			if err != nil {
+				return nil, err
+			}
+
+			annotations, err = p.parseAnnotations()
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		paramName, err = p.ParseIdent()
 		if err != nil {
 			break
@@ -244,7 +614,7 @@ func (p *Parser) parseArgsWithReturnType() (Expr, error) {
 
 		_, err = p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: ":"})
 		if err == nil {
-			paramType, err = p.ParseExpr()
+			paramType, err = p.ParseType()
 			if err != nil {
 				return nil, err
 			}
@@ -252,9 +622,9 @@ func (p *Parser) parseArgsWithReturnType() (Expr, error) {
 
 		// in this case the param is only
 		if paramType == nil {
-			params = append(params, Field{Type: paramName})
+			params = append(params, Field{Type: paramName, Annotations: annotations})
 		} else {
-			params = append(params, Field{Name: paramName, Type: paramType})
+			params = append(params, Field{Name: paramName, Type: paramType, Annotations: annotations})
 		}
 		_, err = p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: ","})
 		if err != nil {
@@ -272,7 +642,7 @@ func (p *Parser) parseArgsWithReturnType() (Expr, error) {
 		return nil, err
 	}
 
-	returnType, err := p.ParseExpr()
+	returnType, err := p.ParseType()
 	if err != nil {
 		return nil, err
 	}
@@ -316,14 +686,53 @@ func (p *Parser) ParseGroup() (Expr, error) {
 	return expr, err
 }
 
+// ParseListLit tries to parse a list of values separated by commas ([1, 2, 3]), new lines are ignored within it and a
+// trailing comma is allowed
+func (p *Parser) ParseListLit() (Expr, error) {
+	open, err := p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: "["})
+	if err != nil {
+		return nil, err
+	}
+
+	p.lex.PushGroup()
+
+	list := &ListLit{Open: open, Elements: make([]Expr, 0)}
+	for {
+		element, err := p.ParseExpr()
+		if err != nil {
+			break
+		}
+
+		list.Elements = append(list.Elements, element)
+		_, err = p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: ","})
+		if err != nil {
+			break
+		}
+	}
+
+	err = p.lex.PopGroup()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.expectCloseBracket()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", err, ErrUnclosedSubscription)
+	}
+
+	return list, nil
+}
+
 // ParseAtom reads either an group, identifier or a literal
 func (p *Parser) ParseAtom() (Expr, error) {
 	atomParsers := []func() (Expr, error){
 		p.ParseGroup,
+		p.ParseListLit,
 		p.ParseStructDef,
 		p.ParseUnionDef,
 		p.ParseEnumDef,
 		p.ParsePrototypeDef,
+		p.ParseBytesLit,
 		p.ParseLiteral,
 		p.ParseIdent,
 	}
@@ -332,6 +741,11 @@ func (p *Parser) ParseAtom() (Expr, error) {
 		if err == nil {
 			return atom, nil
 		}
+
+		// a malformed byte string is still a byte string, no other atom would take it
+		if committed(err) {
+			return nil, err
+		}
 	}
 
 	return nil, fmt.Errorf("%w was expecting atom", ErrUnexpectedToken)
@@ -399,7 +813,21 @@ func (p *Parser) ParseSubscript() (Expr, error) {
 		return nil, err
 	}
 
+	return p.parseSubscriptTail(expr)
+}
+
+// parseSubscriptTail parses the calls, indexes and postfix operators (a++) following an already parsed expression
+func (p *Parser) parseSubscriptTail(expr Expr) (Expr, error) {
 	for {
+		operator, err := p.expect(
+			lexer.Token{Tag: lexer.TokenTagPunct, Value: "++"},
+			lexer.Token{Tag: lexer.TokenTagPunct, Value: "--"},
+		)
+		if err == nil {
+			expr = &UnaryOp{Operator: operator, Operand: expr, Postfix: true}
+			continue
+		}
+
 		args, err := p.parseArgs()
 		if err == nil {
 			expr = &Call{
@@ -413,7 +841,19 @@ func (p *Parser) ParseSubscript() (Expr, error) {
 
 		_, err = p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: "["})
 		if err == nil {
+			// an empty subscript is an array without size (flexible array member), only valid on types
+			if p.inType {
+				_, err = p.expectCloseBracket()
+				if err == nil {
+					expr = &Index{Base: expr}
+					continue
+				}
+			}
+
+			inType := p.inType
+			p.inType = false
 			index, err := p.ParseExpr()
+			p.inType = inType
 			if err != nil {
 				return nil, err
 			}
@@ -423,7 +863,7 @@ func (p *Parser) ParseSubscript() (Expr, error) {
 				Index: index,
 			}
 
-			_, err = p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: "]"})
+			_, err = p.expectCloseBracket()
 			if err != nil {
 				return nil, fmt.Errorf("%w: %w", err, ErrUnclosedSubscription)
 			}
@@ -445,6 +885,9 @@ func (p *Parser) ParseUnary() (Expr, error) {
 		lexer.Token{Tag: lexer.TokenTagPunct, Value: "~"},
 		lexer.Token{Tag: lexer.TokenTagPunct, Value: "*"},
 		lexer.Token{Tag: lexer.TokenTagPunct, Value: "&"},
+		lexer.Token{Tag: lexer.TokenTagPunct, Value: "**"},
+		lexer.Token{Tag: lexer.TokenTagPunct, Value: "++"},
+		lexer.Token{Tag: lexer.TokenTagPunct, Value: "--"},
 	)
 	if err == nil {
 		expr, err := p.ParseUnary()
@@ -452,6 +895,16 @@ func (p *Parser) ParseUnary() (Expr, error) {
 			return nil, err
 		}
 
+		// the lexer reads "**" as the power operator, as a prefix it is a pointer to pointer (**T), likewise
+		// "--" and "++" are only postfix operators so as a prefix they are two signs (--1)
+		if operator.Value == "**" || operator.Value == "--" || operator.Value == "++" {
+			inner := operator
+			inner.Value = operator.Value[:1]
+			inner.Loc.Col += 1
+			operator.Value = operator.Value[:1]
+			expr = &UnaryOp{Operator: inner, Operand: expr}
+		}
+
 		return &UnaryOp{Operator: operator, Operand: expr}, nil
 	}
 
@@ -470,12 +923,18 @@ func (p *Parser) parseBinaryPrec(prec int) (Expr, error) {
 
 	for {
 		cont := false
-		for _, punct := range punctPrec[prec] {
+		for _, punct := range p.prec[prec] {
 			token, err := p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: punct})
 			if err != nil && !errors.Is(err, ErrUnexpectedToken) {
 				return nil, err
 			} else if err == nil {
-				right, err := p.parseBinaryPrec(prec - 1)
+				// a right associative operator takes the rest of the chain as its right operand
+				rightPrec := prec - 1
+				if rightAssoc[punct] {
+					rightPrec = prec
+				}
+
+				right, err := p.parseBinaryPrec(rightPrec)
 				if err != nil {
 					return nil, err
 				}
@@ -486,7 +945,7 @@ func (p *Parser) parseBinaryPrec(prec int) (Expr, error) {
 					Right:    right,
 				}
 
-				cont = true
+				cont = !rightAssoc[punct]
 				break // continue with next operator
 			}
 		}
@@ -501,7 +960,7 @@ func (p *Parser) parseBinaryPrec(prec int) (Expr, error) {
 
 // ParseBinary parses common binary operators
 func (p *Parser) ParseBinary() (Expr, error) {
-	return p.parseBinaryPrec(maxPrec)
+	return p.parseBinaryPrec(p.maxPrec)
 }
 
 // ParseExpr parse next expression
@@ -30,6 +30,36 @@ func TestParser_ParseAtom(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:  "parse hexadecimal literal atom",
+			input: "0xFF",
+			expectedExpr: &parser.Literal{
+				Token: lexer.Token{
+					Tag:   lexer.TokenTagHexInt,
+					Value: "FF",
+					Loc: lexer.Location{
+						File: "parse hexadecimal literal atom",
+						Col:  0,
+						Row:  0,
+					},
+				},
+			},
+		},
+		{
+			name:  "parse binary literal atom",
+			input: "0b101",
+			expectedExpr: &parser.Literal{
+				Token: lexer.Token{
+					Tag:   lexer.TokenTagBinInt,
+					Value: "101",
+					Loc: lexer.Location{
+						File: "parse binary literal atom",
+						Col:  0,
+						Row:  0,
+					},
+				},
+			},
+		},
 		{
 			name:  "parse identifier atom",
 			input: "hello",
@@ -1052,6 +1082,161 @@ func TestParse_Annotations(t *testing.T) {
 				}},
 			},
 		},
+		{
+			name:  "parse field with dotted annotation key",
+			input: "struct { [[ json.name = \"x\" ]] x : int; }",
+			expectedExpr: &parser.StructDef{
+				Block: parser.Block{Decls: []parser.Decl{
+					&parser.AnnotatedDecl{
+						Annotations: []*parser.Annotation{
+							{
+								Name: &parser.BinaryOp{
+									Operator: lexer.Token{
+										Tag: lexer.TokenTagPunct,
+										Loc: lexer.Location{
+											File: "parse field with dotted annotation key",
+											Row:  0,
+											Col:  16,
+										},
+										Value: ".",
+									},
+									Left: &parser.Ident{
+										Token: lexer.Token{
+											Tag: lexer.TokenTagWord,
+											Loc: lexer.Location{
+												File: "parse field with dotted annotation key",
+												Row:  0,
+												Col:  12,
+											},
+											Value: "json",
+										},
+									},
+									Right: &parser.Ident{
+										Token: lexer.Token{
+											Tag: lexer.TokenTagWord,
+											Loc: lexer.Location{
+												File: "parse field with dotted annotation key",
+												Row:  0,
+												Col:  17,
+											},
+											Value: "name",
+										},
+									},
+								},
+								Value: &parser.Literal{
+									Token: lexer.Token{
+										Tag: lexer.TokenTagString,
+										Loc: lexer.Location{
+											File: "parse field with dotted annotation key",
+											Row:  0,
+											Col:  24,
+										},
+										Value: "x",
+									},
+								},
+							},
+						},
+						Decl: &parser.Field{
+							Name: &parser.Ident{
+								Token: lexer.Token{
+									Tag: lexer.TokenTagWord,
+									Loc: lexer.Location{
+										File: "parse field with dotted annotation key",
+										Row:  0,
+										Col:  31,
+									},
+									Value: "x",
+								},
+							},
+							Type: &parser.Ident{
+								Token: lexer.Token{
+									Tag: lexer.TokenTagWord,
+									Loc: lexer.Location{
+										File: "parse field with dotted annotation key",
+										Row:  0,
+										Col:  35,
+									},
+									Value: "int",
+								},
+							},
+						},
+					},
+				}},
+			},
+		},
+		{
+			name:  "parse field with flag annotation",
+			input: "struct { [[ a, b = c ]] x : int; }",
+			expectedExpr: &parser.StructDef{
+				Block: parser.Block{Decls: []parser.Decl{
+					&parser.AnnotatedDecl{
+						Annotations: []*parser.Annotation{
+							{
+								Name: &parser.Ident{
+									Token: lexer.Token{
+										Tag: lexer.TokenTagWord,
+										Loc: lexer.Location{
+											File: "parse field with flag annotation",
+											Row:  0,
+											Col:  12,
+										},
+										Value: "a",
+									},
+								},
+							},
+							{
+								Name: &parser.Ident{
+									Token: lexer.Token{
+										Tag: lexer.TokenTagWord,
+										Loc: lexer.Location{
+											File: "parse field with flag annotation",
+											Row:  0,
+											Col:  15,
+										},
+										Value: "b",
+									},
+								},
+								Value: &parser.Ident{
+									Token: lexer.Token{
+										Tag: lexer.TokenTagWord,
+										Loc: lexer.Location{
+											File: "parse field with flag annotation",
+											Row:  0,
+											Col:  19,
+										},
+										Value: "c",
+									},
+								},
+							},
+						},
+						Decl: &parser.Field{
+							Name: &parser.Ident{
+								Token: lexer.Token{
+									Tag: lexer.TokenTagWord,
+									Loc: lexer.Location{
+										File: "parse field with flag annotation",
+										Row:  0,
+										Col:  24,
+									},
+									Value: "x",
+								},
+							},
+							Type: &parser.Ident{
+								Token: lexer.Token{
+									Tag: lexer.TokenTagWord,
+									Loc: lexer.Location{
+										File: "parse field with flag annotation",
+										Row:  0,
+										Col:  28,
+									},
+									Value: "int",
+								},
+							},
+						},
+					},
+				}},
+			},
+		},
 	}
 	for _, tt := range cases {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1067,3 +1252,536 @@ func TestParse_Annotations(t *testing.T) {
 		})
 	}
 }
+
+func TestParse_MemberComments(t *testing.T) {
+	input := "enum {\n# the first\nA # trailing A\nB = 2 # trailing B\n# the last\n# really\nC\n}"
+	p := parser.NewFromString("member comments", input)
+	expr, err := p.ParseExpr()
+	require.NoError(t, err)
+
+	decls := expr.(*parser.EnumDef).Block.Decls
+	require.Len(t, decls, 3)
+
+	first := decls[0].(*parser.Field)
+	require.Equal(t, "A", parser.LookupName(first.Name))
+	require.Len(t, first.Leading, 1)
+	require.Equal(t, "# the first", first.Leading[0].Value)
+	require.Equal(t, 1, first.Leading[0].Loc.Row)
+	require.NotNil(t, first.Trailing)
+	require.Equal(t, "# trailing A", first.Trailing.Value)
+
+	second := decls[1].(*parser.Field)
+	require.Equal(t, "B", parser.LookupName(second.Name))
+	require.Nil(t, second.Leading)
+	require.NotNil(t, second.Trailing)
+	require.Equal(t, "# trailing B", second.Trailing.Value)
+
+	third := decls[2].(*parser.Field)
+	require.Equal(t, "C", parser.LookupName(third.Name))
+	require.Len(t, third.Leading, 2)
+	require.Equal(t, "# the last", third.Leading[0].Value)
+	require.Equal(t, "# really", third.Leading[1].Value)
+	require.Nil(t, third.Trailing)
+}
+
+func TestParser_NestedSubscripts(t *testing.T) {
+	expr, err := parser.NewFromString("nested subscripts", "a[b[1]]").ParseExpr()
+	require.NoError(t, err)
+	expected := &parser.Index{Base: ident("a"), Index: &parser.Index{Base: ident("b"), Index: decInt("1")}}
+	require.Empty(t, parser.Diff(expected, expr))
+}
+
+func TestParser_ParseListLit(t *testing.T) {
+	list := func(elements ...parser.Expr) *parser.ListLit {
+		return &parser.ListLit{Open: lexer.Token{Tag: lexer.TokenTagPunct, Value: "["}, Elements: elements}
+	}
+
+	cases := []struct {
+		name         string
+		input        string
+		expectedExpr parser.Expr
+		expectedErr  error
+	}{
+		{
+			name:         "values",
+			input:        "[255, 0, 0]",
+			expectedExpr: list(decInt("255"), decInt("0"), decInt("0")),
+		},
+		{
+			name:         "empty list",
+			input:        "[]",
+			expectedExpr: list(),
+		},
+		{
+			name:         "one value per line with a trailing comma",
+			input:        "[\n1,\n2,\n]",
+			expectedExpr: list(decInt("1"), decInt("2")),
+		},
+		{
+			name:         "nested lists",
+			input:        "[ [1], a + 1 ]",
+			expectedExpr: list(list(decInt("1")), binary("+", ident("a"), decInt("1"))),
+		},
+		{
+			name:        "unclosed list",
+			input:       "[1, 2",
+			expectedErr: parser.ErrUnexpectedToken,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualExpr, actualErr := parser.NewFromString(tt.name, tt.input).ParseExpr()
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+			require.Empty(t, parser.Diff(tt.expectedExpr, actualExpr))
+		})
+	}
+}
+
+func TestParser_ParseBytesLit(t *testing.T) {
+	cases := []struct {
+		name          string
+		input         string
+		expectedBytes []byte
+		expectedErr   error
+	}{
+		{
+			name:          "bytes",
+			input:         "0x[DEADbeef]",
+			expectedBytes: []byte{0xDE, 0xAD, 0xBE, 0xEF},
+		},
+		{
+			name:          "empty bytes",
+			input:         "0x[]",
+			expectedBytes: []byte{},
+		},
+		{
+			name:        "odd number of digits",
+			input:       "0x[ABC]",
+			expectedErr: parser.ErrMalformedBytes,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualExpr, actualErr := parser.NewFromString(tt.name, tt.input).ParseBytesLit()
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+			require.Equal(t, tt.expectedBytes, actualExpr.(*parser.BytesLit).Bytes)
+		})
+	}
+}
+
+func TestParse_BytesDefault(t *testing.T) {
+	schema, err := parser.NewFromString("bytes", "type s struct {\nmagic : u8[4] = 0x[CAFEBABE]\nmask : u32 = 0xFF\n}\n").Parse()
+	require.NoError(t, err)
+
+	def := schema.Decls[0].(*parser.TypeDecl).Type.(*parser.StructDef)
+	magic := def.Block.Decls[0].(*parser.Field).Value.(*parser.BytesLit)
+	require.Equal(t, []byte{0xCA, 0xFE, 0xBA, 0xBE}, magic.Bytes)
+
+	// plain hex integers are still integers
+	mask := def.Block.Decls[1].(*parser.Field).Value.(*parser.Literal)
+	require.Equal(t, lexer.TokenTagHexInt, mask.Token.Tag)
+
+	_, err = parser.NewFromString("odd bytes", "type s struct {\nmagic : u8[2] = 0x[CAF]\n}\n").Parse()
+	require.ErrorIs(t, err, parser.ErrMalformedBytes)
+}
+
+func TestParse_ListAnnotation(t *testing.T) {
+	decl, err := parser.NewFromString("list annotation", "[[ rgb = [255, 0, 0] ]]\ntype red int\n").ParseAnnotatedDecl()
+	require.NoError(t, err)
+
+	annotation, ok := decl.(*parser.AnnotatedDecl).Find("rgb")
+	require.True(t, ok)
+	require.Len(t, annotation.Value.(*parser.ListLit).Elements, 3)
+}
+
+func TestParse_FieldSeparators(t *testing.T) {
+	expected := &parser.StructDef{Block: parser.Block{Decls: []parser.Decl{
+		&parser.Field{Name: ident("a"), Type: ident("int")},
+		&parser.Field{Name: ident("b"), Type: ident("int")},
+	}}}
+
+	cases := []struct {
+		name  string
+		input string
+	}{
+		{name: "semicolons", input: "struct { a : int; b : int; }"},
+		{name: "new lines", input: "struct {\na : int\nb : int\n}"},
+		{name: "new lines without trailing one", input: "struct { a : int\nb : int }"},
+		{name: "blank lines", input: "struct {\n\na : int\n\n\nb : int\n\n}"},
+		{name: "mixed separators", input: "struct { a : int;\nb : int }"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualExpr, err := parser.NewFromString(tt.name, tt.input).ParseExpr()
+			require.NoError(t, err)
+			require.Empty(t, parser.Diff(expected, actualExpr))
+		})
+	}
+}
+
+func TestParse_CommentsBetweenFields(t *testing.T) {
+	cases := []struct {
+		name             string
+		input            string
+		expectedTrailing string
+		expectedLeading  []string
+	}{
+		{
+			name:             "after semicolon",
+			input:            "struct { a: int; # note\n b: int; }",
+			expectedTrailing: "# note",
+		},
+		{
+			name:            "own line after semicolon",
+			input:           "struct { a: int;\n# note\nb: int; }",
+			expectedLeading: []string{"# note"},
+		},
+		{
+			name:             "followed by blank lines",
+			input:            "struct {\na : int # note\n\n# lead\n  \n\nb : int\n# last\n\n}",
+			expectedTrailing: "# note",
+			expectedLeading:  []string{"# lead"},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := parser.NewFromString(tt.name, tt.input).ParseExpr()
+			require.NoError(t, err)
+
+			decls := expr.(*parser.StructDef).Block.Decls
+			require.Len(t, decls, 2)
+
+			first, second := decls[0].(*parser.Field), decls[1].(*parser.Field)
+			require.Equal(t, "a", parser.LookupName(first.Name))
+			require.Equal(t, "b", parser.LookupName(second.Name))
+			if tt.expectedTrailing != "" {
+				require.NotNil(t, first.Trailing)
+				require.Equal(t, tt.expectedTrailing, first.Trailing.Value)
+			} else {
+				require.Nil(t, first.Trailing)
+			}
+
+			leading := make([]string, 0)
+			for _, comment := range second.Leading {
+				leading = append(leading, comment.Value)
+			}
+			require.Equal(t, len(tt.expectedLeading), len(leading))
+			for i, comment := range tt.expectedLeading {
+				require.Equal(t, comment, leading[i])
+			}
+		})
+	}
+}
+
+func TestParse_CharDefault(t *testing.T) {
+	decl, err := parser.NewFromString("char default", "type s struct { c : char = 'A'; nl : char = '\\n'; }\n").ParseDecl()
+	require.NoError(t, err)
+
+	char := func(value string) *parser.Literal {
+		return &parser.Literal{Token: lexer.Token{Tag: lexer.TokenTagChar, Value: value}}
+	}
+	expected := &parser.StructDef{Block: parser.Block{Decls: []parser.Decl{
+		&parser.Field{Name: ident("c"), Type: ident("char"), Value: char("A")},
+		&parser.Field{Name: ident("nl"), Type: ident("char"), Value: char("\n")},
+	}}}
+	require.Empty(t, parser.Diff(expected, decl.(*parser.TypeDecl).Type))
+}
+
+func TestParse_StringEnum(t *testing.T) {
+	decl, err := parser.NewFromString("string enum", "type color enum { RED = \"red\"; GREEN = \"green\"; }\n").ParseDecl()
+	require.NoError(t, err)
+
+	str := func(value string) *parser.Literal {
+		return &parser.Literal{Token: lexer.Token{Tag: lexer.TokenTagString, Value: value}}
+	}
+	expected := &parser.EnumDef{Block: parser.Block{Decls: []parser.Decl{
+		&parser.Field{Name: ident("RED"), Value: str("red")},
+		&parser.Field{Name: ident("GREEN"), Value: str("green")},
+	}}}
+	require.Empty(t, parser.Diff(expected, decl.(*parser.TypeDecl).Type))
+}
+
+func TestParse_FieldWithoutSeparator(t *testing.T) {
+	_, err := parser.NewFromString("no separator", "type s struct { a : int b : int }\n").Parse()
+	require.ErrorIs(t, err, parser.ErrUnexpectedToken)
+}
+
+func TestParse_EscapedFieldNames(t *testing.T) {
+	schema, err := parser.NewFromString("escaped", "type s struct {\n`type` : int\n`struct` : `type`\n}\n").Parse()
+	require.NoError(t, err)
+
+	def := schema.Decls[0].(*parser.TypeDecl).Type.(*parser.StructDef)
+	require.Len(t, def.Block.Decls, 2)
+
+	first := def.Block.Decls[0].(*parser.Field)
+	require.Equal(t, "type", parser.LookupName(first.Name))
+	require.True(t, first.Name.(*parser.Ident).Token.Escaped)
+	require.Equal(t, "int", parser.LookupName(first.Type))
+
+	second := def.Block.Decls[1].(*parser.Field)
+	require.Equal(t, "struct", parser.LookupName(second.Name))
+	require.Equal(t, "type", parser.LookupName(second.Type))
+}
+
+func TestParse_EscapedKeywordIsNotAKeyword(t *testing.T) {
+	_, err := parser.NewFromString("escaped keyword", "`type` s int;").Parse()
+	require.Error(t, err)
+}
+
+func TestParse_MultilineAnnotations(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "values on following lines",
+			input:    "[[ size = 8,\n\tname = \"s\" ]]\ntype a int;",
+			expected: "[[ size = 8, name = \"s\" ]]\ntype a int;",
+		},
+		{
+			name:     "one annotation per line",
+			input:    "[[\n\tsize = 8,\n\topaque,\n\tname = \"s\"\n]]\ntype a int;",
+			expected: "[[ size = 8, opaque, name = \"s\" ]]\ntype a int;",
+		},
+		{
+			name:     "trailing comma",
+			input:    "[[\n\tsize = 8,\n\tname = \"s\",\n]]\ntype a int;",
+			expected: "[[ size = 8, name = \"s\" ]]\ntype a int;",
+		},
+		{
+			name:     "value spanning lines",
+			input:    "[[ size = 4 *\n\t2 ]]\ntype a int;",
+			expected: "[[ size = 4 * 2 ]]\ntype a int;",
+		},
+		{
+			name:     "keyed sigil values",
+			input:    "@json(\n\tname = \"x\",\n\tomit = true\n)\ntype a int;",
+			expected: "[[ json.name = \"x\", json.omit = true ]]\ntype a int;",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := parser.NewFromString(tt.name, tt.input).Parse()
+			require.NoError(t, err)
+
+			expected, err := parser.NewFromString(tt.name, tt.expected).Parse()
+			require.NoError(t, err)
+			require.Empty(t, parser.Diff(expected, actual))
+			require.Len(t, actual.Decls, 1)
+		})
+	}
+}
+
+func TestParse_SigilAnnotations(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "flag",
+			input:    "@deprecated\ntype a int;",
+			expected: "[[ deprecated ]]\ntype a int;",
+		},
+		{
+			name:     "flag on the same line",
+			input:    "@deprecated type a int;",
+			expected: "[[ deprecated ]]\ntype a int;",
+		},
+		{
+			name:     "positional value",
+			input:    "@doc(\"a number\")\ntype a int;",
+			expected: "[[ doc = \"a number\" ]]\ntype a int;",
+		},
+		{
+			name:     "keyed values",
+			input:    "@json(name = \"x\", omit = true)\ntype a int;",
+			expected: "[[ json.name = \"x\", json.omit = true ]]\ntype a int;",
+		},
+		{
+			name:     "mixed with brackets",
+			input:    "@deprecated\n[[ size = 4 ]]\n@json(name = \"x\")\ntype a int;",
+			expected: "[[ deprecated, size = 4, json.name = \"x\" ]]\ntype a int;",
+		},
+		{
+			name:     "field annotations",
+			input:    "type s struct {\n@json(name = \"x\")\nx : int\n@readonly y : int\n}\n",
+			expected: "type s struct {\n[[ json.name = \"x\" ]]\nx : int\n[[ readonly ]]\ny : int\n}\n",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := parser.NewFromString(tt.name, tt.input).Parse()
+			require.NoError(t, err)
+
+			expected, err := parser.NewFromString(tt.name, tt.expected).Parse()
+			require.NoError(t, err)
+			require.Empty(t, parser.Diff(expected, actual))
+		})
+	}
+}
+
+func TestParse_UnclosedSigilAnnotation(t *testing.T) {
+	_, err := parser.NewFromString("unclosed", "@json(name = \"x\"\ntype a int;").ParseAnnotatedDecl()
+	require.ErrorIs(t, err, parser.ErrUnclosedParenthesis)
+}
+
+func TestParser_PowerOperator(t *testing.T) {
+	cases := []struct {
+		name         string
+		input        string
+		expectedExpr parser.Expr
+	}{
+		{
+			name:         "right associative",
+			input:        "2 ** 3 ** 2",
+			expectedExpr: binary("**", decInt("2"), binary("**", decInt("3"), decInt("2"))),
+		},
+		{
+			name:         "binds tighter than product on the right",
+			input:        "2 * 3 ** 2",
+			expectedExpr: binary("*", decInt("2"), binary("**", decInt("3"), decInt("2"))),
+		},
+		{
+			name:         "binds tighter than product on the left",
+			input:        "2 ** 3 * 2",
+			expectedExpr: binary("*", binary("**", decInt("2"), decInt("3")), decInt("2")),
+		},
+		{
+			name:         "arithmetic stays left associative",
+			input:        "8 - 4 - 2",
+			expectedExpr: binary("-", binary("-", decInt("8"), decInt("4")), decInt("2")),
+		},
+		{
+			name:         "pointer to pointer",
+			input:        "**a",
+			expectedExpr: unary("*", unary("*", ident("a"))),
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualExpr, err := parser.NewFromString(tt.name, tt.input).ParseExpr()
+			require.NoError(t, err)
+			require.Empty(t, parser.Diff(tt.expectedExpr, actualExpr))
+		})
+	}
+}
+
+func TestParser_ShiftOperators(t *testing.T) {
+	cases := []struct {
+		name         string
+		input        string
+		expectedExpr parser.Expr
+	}{
+		{
+			name:         "binds looser than sum",
+			input:        "1 << 2 + 3",
+			expectedExpr: binary("<<", decInt("1"), binary("+", decInt("2"), decInt("3"))),
+		},
+		{
+			name:         "binds tighter than bitwise and",
+			input:        "a & 1 >> 2",
+			expectedExpr: binary("&", ident("a"), binary(">>", decInt("1"), decInt("2"))),
+		},
+		{
+			name:         "left associative",
+			input:        "8 >> 2 << 1",
+			expectedExpr: binary("<<", binary(">>", decInt("8"), decInt("2")), decInt("1")),
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualExpr, err := parser.NewFromString(tt.name, tt.input).ParseExpr()
+			require.NoError(t, err)
+			require.Empty(t, parser.Diff(tt.expectedExpr, actualExpr))
+		})
+	}
+}
+
+func TestParser_SetOperatorPrecedence(t *testing.T) {
+	table := parser.DefaultOperatorPrecedence()
+	table[12] = []string{"=>"}
+
+	p := parser.NewFromString("custom operator", "a => b || c => d")
+	p.SetOperatorPrecedence(table)
+	expr, err := p.ParseExpr()
+	require.NoError(t, err)
+	require.Empty(t, parser.Diff(binary("=>", binary("=>", ident("a"), binary("||", ident("b"), ident("c"))), ident("d")), expr))
+
+	// other parsers keep the default table
+	require.NotContains(t, parser.DefaultOperatorPrecedence(), 12)
+	_, err = parser.NewFromString("default table", "a => b").ParseDecl()
+	require.Error(t, err)
+}
+
+func TestParser_SetOperatorPrecedenceReorders(t *testing.T) {
+	// sums binding tighter than products
+	p := parser.NewFromString("reordered", "1 * 2 + 3")
+	p.SetOperatorPrecedence(map[int][]string{1: {"+", "-"}, 2: {"*", "/"}})
+	expr, err := p.ParseExpr()
+	require.NoError(t, err)
+	require.Empty(t, parser.Diff(binary("*", decInt("1"), binary("+", decInt("2"), decInt("3"))), expr))
+}
+
+func TestParser_PostfixOperators(t *testing.T) {
+	postfix := func(operator string, operand parser.Expr) *parser.UnaryOp {
+		op := unary(operator, operand)
+		op.Postfix = true
+		return op
+	}
+
+	cases := []struct {
+		name         string
+		input        string
+		expectedExpr parser.Expr
+	}{
+		{
+			name:         "increment",
+			input:        "a++",
+			expectedExpr: postfix("++", ident("a")),
+		},
+		{
+			name:         "decrement after subscript",
+			input:        "a[1]--",
+			expectedExpr: postfix("--", &parser.Index{Base: ident("a"), Index: decInt("1")}),
+		},
+		{
+			name:         "binds tighter than prefix",
+			input:        "-a++",
+			expectedExpr: unary("-", postfix("++", ident("a"))),
+		},
+		{
+			name:         "within binary operations",
+			input:        "a++ + b--",
+			expectedExpr: binary("+", postfix("++", ident("a")), postfix("--", ident("b"))),
+		},
+		{
+			name:         "prefix signs",
+			input:        "-1 + +2",
+			expectedExpr: binary("+", unary("-", decInt("1")), unary("+", decInt("2"))),
+		},
+		{
+			name:         "double prefix signs",
+			input:        "--1",
+			expectedExpr: unary("-", unary("-", decInt("1"))),
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualExpr, err := parser.NewFromString(tt.name, tt.input).ParseExpr()
+			require.NoError(t, err)
+			require.Empty(t, parser.Diff(tt.expectedExpr, actualExpr))
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/cedmundo/SimpleSchema/lexer"
+)
+
+// ParseType parses an expression in type position, where some words introduce type constructors (set[T], map[K]V)
+// and parenthesis introduce tuples ((A, B)). Anything else is parsed as a regular expression whose dotted lookups
+// are types of imported packages (pkg.Type), see QualifiedName.
+func (p *Parser) ParseType() (Expr, error) {
+	inType := p.inType
+	p.inType = true
+	defer func() { p.inType = inType }()
+
+	token, err := p.expect(
+		lexer.Token{Tag: lexer.TokenTagWord, Value: "set"},
+		lexer.Token{Tag: lexer.TokenTagWord, Value: "map"},
+		lexer.Token{Tag: lexer.TokenTagWord, Value: "decimal"},
+		lexer.Token{Tag: lexer.TokenTagWord, Value: "string"},
+		lexer.Token{Tag: lexer.TokenTagWord, Value: "int"},
+		lexer.Token{Tag: lexer.TokenTagWord, Value: "uint"},
+		lexer.Token{Tag: lexer.TokenTagPunct, Value: "("},
+		lexer.Token{Tag: lexer.TokenTagPunct, Value: "?"},
+	)
+	if err != nil {
+		expr, err := p.ParseExpr()
+		if err != nil {
+			return nil, err
+		}
+
+		return qualifyType(expr), nil
+	}
+
+	switch token.Value {
+	case "(":
+		return p.parseTupleType()
+	case "?":
+		typ, err := p.ParseType()
+		if err != nil {
+			return nil, err
+		}
+
+		return &OptionalType{Token: token, Type: typ}, nil
+	case "string":
+		return p.parseFixedStringType(token)
+	case "map":
+		return p.parseMapType(token)
+	case "decimal":
+		return p.parseDecimalType(token)
+	case "int", "uint":
+		return p.parseSizedIntType(token)
+	}
+
+	return p.parseSetType(token)
+}
+
+// qualifyType replaces the dotted lookups of a type expression by qualified names, including the ones within
+// pointers, arrays and type constructors (*pkg.T, pkg.T[4], const(pkg.T))
+func qualifyType(expr Expr) Expr {
+	switch e := expr.(type) {
+	case *BinaryOp:
+		if qualified := qualifyName(e); qualified != nil {
+			return qualified
+		}
+	case *UnaryOp:
+		e.Operand = qualifyType(e.Operand)
+	case *Index:
+		e.Base = qualifyType(e.Base)
+	case *Call:
+		for i, arg := range e.Args {
+			e.Args[i] = qualifyType(arg)
+		}
+	}
+
+	return expr
+}
+
+// qualifyName converts a lookup of words (a.b.c) into a qualified name, returns nil for any other expression
+func qualifyName(lookup *BinaryOp) *QualifiedName {
+	name, ok := lookup.Right.(*Ident)
+	if lookup.Operator.Value != "." || !ok {
+		return nil
+	}
+
+	switch left := lookup.Left.(type) {
+	case *Ident:
+		return &QualifiedName{Package: left, Name: name}
+	case *BinaryOp:
+		pkg := qualifyName(left)
+		if pkg == nil {
+			return nil
+		}
+
+		return &QualifiedName{Package: pkg, Name: name}
+	}
+
+	return nil
+}
+
+// parseFixedStringType parses the size of a fixed string (string<N>), a string word without size is a plain
+// identifier
+func (p *Parser) parseFixedStringType(str lexer.Token) (Expr, error) {
+	_, err := p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: "<"})
+	if err != nil {
+		return p.parseSubscriptTail(&Ident{Token: str})
+	}
+
+	size, err := p.parseIntParam()
+	if err != nil {
+		return nil, fmt.Errorf("%w: string size: %w", ErrMalformedType, err)
+	}
+
+	_, err = p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: ">"})
+	if err != nil {
+		return nil, fmt.Errorf("%w: unclosed string size: %w", ErrMalformedType, err)
+	}
+
+	return p.parseSubscriptTail(&FixedStringType{Size: size})
+}
+
+// parseDecimalType parses the precision and scale of a decimal (decimal<10, 2>), a decimal word without them is a
+// plain identifier
+func (p *Parser) parseDecimalType(decimal lexer.Token) (Expr, error) {
+	_, err := p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: "<"})
+	if err != nil {
+		return p.parseSubscriptTail(&Ident{Token: decimal})
+	}
+
+	precision, err := p.parseIntParam()
+	if err != nil {
+		return nil, fmt.Errorf("%w: decimal precision: %w", ErrMalformedType, err)
+	}
+
+	_, err = p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: ","})
+	if err != nil {
+		return nil, fmt.Errorf("%w: decimal without scale: %w", ErrMalformedType, err)
+	}
+
+	scale, err := p.parseIntParam()
+	if err != nil {
+		return nil, fmt.Errorf("%w: decimal scale: %w", ErrMalformedType, err)
+	}
+
+	_, err = p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: ">"})
+	if err != nil {
+		return nil, fmt.Errorf("%w: unclosed decimal: %w", ErrMalformedType, err)
+	}
+
+	return p.parseSubscriptTail(&DecimalType{Precision: precision, Scale: scale})
+}
+
+// parseSizedIntType parses the bits of an exact width integer (int<24>, uint<24>), an int or uint word without
+// them is a plain identifier
+func (p *Parser) parseSizedIntType(word lexer.Token) (Expr, error) {
+	_, err := p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: "<"})
+	if err != nil {
+		return p.parseSubscriptTail(&Ident{Token: word})
+	}
+
+	bits, err := p.parseIntParam()
+	if err != nil {
+		return nil, fmt.Errorf("%w: integer bits: %w", ErrMalformedType, err)
+	}
+
+	_, err = p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: ">"})
+	if err != nil {
+		return nil, fmt.Errorf("%w: unclosed integer bits: %w", ErrMalformedType, err)
+	}
+
+	return p.parseSubscriptTail(&SizedIntType{Bits: bits, Unsigned: word.Value == "uint"})
+}
+
+// parseIntParam parses an integer literal used as a type parameter
+func (p *Parser) parseIntParam() (Expr, error) {
+	token, err := p.expect(
+		lexer.Token{Tag: lexer.TokenTagDecInt},
+		lexer.Token{Tag: lexer.TokenTagHexInt},
+		lexer.Token{Tag: lexer.TokenTagOctInt},
+		lexer.Token{Tag: lexer.TokenTagBinInt},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Literal{Token: token}, nil
+}
+
+// parseTupleType parses a comma separated list of types after "(", a single type is a group, not a tuple
+func (p *Parser) parseTupleType() (Expr, error) {
+	p.lex.PushGroup()
+
+	elements := make([]Expr, 0)
+	for {
+		element, err := p.ParseType()
+		if err != nil {
+			return nil, err
+		}
+
+		elements = append(elements, element)
+		_, err = p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: ","})
+		if err != nil {
+			break
+		}
+	}
+
+	err := p.lex.PopGroup()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: ")"})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", err, ErrUnclosedParenthesis)
+	}
+
+	if len(elements) == 1 {
+		return p.parseSubscriptTail(elements[0])
+	}
+
+	return p.parseSubscriptTail(&TupleType{Elements: elements})
+}
+
+// parseSetType parses the element of a set (set[T]), a set word without brackets is a plain identifier
+func (p *Parser) parseSetType(set lexer.Token) (Expr, error) {
+	_, err := p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: "["})
+	if err != nil {
+		return p.parseSubscriptTail(&Ident{Token: set})
+	}
+
+	element, err := p.ParseType()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.expectCloseBracket()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", err, ErrUnclosedSubscription)
+	}
+
+	return p.parseSubscriptTail(&SetType{Element: element})
+}
+
+// parseMapType parses the key and value types of a map (map[K]V), a map word without brackets is a plain identifier.
+// Subscripts after the value type belong to it (map[K]V[4] maps to arrays).
+func (p *Parser) parseMapType(m lexer.Token) (Expr, error) {
+	_, err := p.expect(lexer.Token{Tag: lexer.TokenTagPunct, Value: "["})
+	if err != nil {
+		return p.parseSubscriptTail(&Ident{Token: m})
+	}
+
+	key, err := p.ParseType()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.expectCloseBracket()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", err, ErrUnclosedSubscription)
+	}
+
+	value, err := p.ParseType()
+	if err != nil {
+		return nil, fmt.Errorf("%w: map value: %w", ErrMalformedType, err)
+	}
+
+	return &MapType{Key: key, Value: value}, nil
+}
@@ -0,0 +1,692 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/cedmundo/SimpleSchema/lexer"
+	"github.com/cedmundo/SimpleSchema/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func ident(value string) *parser.Ident {
+	return &parser.Ident{Token: lexer.Token{Tag: lexer.TokenTagWord, Value: value}}
+}
+
+func decInt(value string) *parser.Literal {
+	return &parser.Literal{Token: lexer.Token{Tag: lexer.TokenTagDecInt, Value: value}}
+}
+
+func binary(operator string, left, right parser.Expr) *parser.BinaryOp {
+	return &parser.BinaryOp{Operator: lexer.Token{Tag: lexer.TokenTagPunct, Value: operator}, Left: left, Right: right}
+}
+
+func unary(operator string, operand parser.Expr) *parser.UnaryOp {
+	return &parser.UnaryOp{Operator: lexer.Token{Tag: lexer.TokenTagPunct, Value: operator}, Operand: operand}
+}
+
+func optional(typ parser.Expr) *parser.OptionalType {
+	return &parser.OptionalType{Token: lexer.Token{Tag: lexer.TokenTagPunct, Value: "?"}, Type: typ}
+}
+
+func TestParser_ParseTypeDecl(t *testing.T) {
+	cases := []struct {
+		name         string
+		input        string
+		expectedType parser.Expr
+		expectedErr  error
+	}{
+		{
+			name:         "plain type",
+			input:        "type s int;",
+			expectedType: ident("int"),
+		},
+		{
+			name:         "set type",
+			input:        "type s set[int];",
+			expectedType: &parser.SetType{Element: ident("int")},
+		},
+		{
+			name:         "set of arrays",
+			input:        "type s set[float[4]];",
+			expectedType: &parser.SetType{Element: &parser.Index{Base: ident("float"), Index: decInt("4")}},
+		},
+		{
+			name:         "array of sets",
+			input:        "type s set[int][4];",
+			expectedType: &parser.Index{Base: &parser.SetType{Element: ident("int")}, Index: decInt("4")},
+		},
+		{
+			name:         "nested sets",
+			input:        "type s set[set[Foo]];",
+			expectedType: &parser.SetType{Element: &parser.SetType{Element: ident("Foo")}},
+		},
+		{
+			name:         "set as a plain identifier",
+			input:        "type s set;",
+			expectedType: ident("set"),
+		},
+		{
+			name:         "map type",
+			input:        "type s map[string]int;",
+			expectedType: &parser.MapType{Key: ident("string"), Value: ident("int")},
+		},
+		{
+			name:         "map of arrays",
+			input:        "type s map[u32]float[4];",
+			expectedType: &parser.MapType{Key: ident("u32"), Value: &parser.Index{Base: ident("float"), Index: decInt("4")}},
+		},
+		{
+			name:         "map of sets",
+			input:        "type s map[u32]set[Foo];",
+			expectedType: &parser.MapType{Key: ident("u32"), Value: &parser.SetType{Element: ident("Foo")}},
+		},
+		{
+			name:         "map as a plain identifier",
+			input:        "type s map;",
+			expectedType: ident("map"),
+		},
+		{
+			name:         "tuple type",
+			input:        "type s (int, string);",
+			expectedType: &parser.TupleType{Elements: []parser.Expr{ident("int"), ident("string")}},
+		},
+		{
+			name:  "tuple type spanning lines",
+			input: "type s (\n\tint,\n\tset[string]\n);",
+			expectedType: &parser.TupleType{Elements: []parser.Expr{
+				ident("int"),
+				&parser.SetType{Element: ident("string")},
+			}},
+		},
+		{
+			name:         "array of tuples",
+			input:        "type s (int, int)[2];",
+			expectedType: &parser.Index{Base: &parser.TupleType{Elements: []parser.Expr{ident("int"), ident("int")}}, Index: decInt("2")},
+		},
+		{
+			name:         "grouped type",
+			input:        "type s (int);",
+			expectedType: ident("int"),
+		},
+		{
+			name:         "grouped array type",
+			input:        "type s (float[4]);",
+			expectedType: &parser.Index{Base: ident("float"), Index: decInt("4")},
+		},
+		{
+			name:        "unclosed tuple type",
+			input:       "type s (int, int",
+			expectedErr: parser.ErrUnclosedParenthesis,
+		},
+		{
+			name:         "array without size",
+			input:        "type s u8[];",
+			expectedType: &parser.Index{Base: ident("u8")},
+		},
+		{
+			name:         "fixed string type",
+			input:        "type s string<32>;",
+			expectedType: &parser.FixedStringType{Size: decInt("32")},
+		},
+		{
+			name:         "array of fixed strings",
+			input:        "type s string<8>[4];",
+			expectedType: &parser.Index{Base: &parser.FixedStringType{Size: decInt("8")}, Index: decInt("4")},
+		},
+		{
+			name:         "string as a plain identifier",
+			input:        "type s string;",
+			expectedType: ident("string"),
+		},
+		{
+			name:        "fixed string without size",
+			input:       "type s string<>;",
+			expectedErr: parser.ErrMalformedType,
+		},
+		{
+			name:        "fixed string with non-integer size",
+			input:       "type s string<1.5>;",
+			expectedErr: parser.ErrMalformedType,
+		},
+		{
+			name:        "unclosed fixed string",
+			input:       "type s string<32;",
+			expectedErr: parser.ErrMalformedType,
+		},
+		{
+			name:        "unclosed map key",
+			input:       "type s map[int int;",
+			expectedErr: parser.ErrUnclosedSubscription,
+		},
+		{
+			name:        "map without value",
+			input:       "type s map[int];",
+			expectedErr: parser.ErrMalformedType,
+		},
+		{
+			name:         "decimal type",
+			input:        "type s decimal<10, 2>;",
+			expectedType: &parser.DecimalType{Precision: decInt("10"), Scale: decInt("2")},
+		},
+		{
+			name:         "array of decimals",
+			input:        "type s decimal<10,2>[4];",
+			expectedType: &parser.Index{Base: &parser.DecimalType{Precision: decInt("10"), Scale: decInt("2")}, Index: decInt("4")},
+		},
+		{
+			name:         "decimal as a plain identifier",
+			input:        "type s decimal;",
+			expectedType: ident("decimal"),
+		},
+		{
+			name:        "decimal without scale",
+			input:       "type s decimal<10>;",
+			expectedErr: parser.ErrMalformedType,
+		},
+		{
+			name:        "decimal with non-integer scale",
+			input:       "type s decimal<10, x>;",
+			expectedErr: parser.ErrMalformedType,
+		},
+		{
+			name:        "unclosed decimal",
+			input:       "type s decimal<10, 2;",
+			expectedErr: parser.ErrMalformedType,
+		},
+		{
+			name:         "optional type",
+			input:        "type s ?int;",
+			expectedType: optional(ident("int")),
+		},
+		{
+			name:         "optional array",
+			input:        "type s ?u8[4];",
+			expectedType: optional(&parser.Index{Base: ident("u8"), Index: decInt("4")}),
+		},
+		{
+			name:         "optional of an optional pointer",
+			input:        "type s ??*node;",
+			expectedType: optional(optional(unary("*", ident("node")))),
+		},
+		{
+			name:         "sized integer type",
+			input:        "type s int<24>;",
+			expectedType: &parser.SizedIntType{Bits: decInt("24")},
+		},
+		{
+			name:         "array of sized unsigned integers",
+			input:        "type s uint<24>[4];",
+			expectedType: &parser.Index{Base: &parser.SizedIntType{Bits: decInt("24"), Unsigned: true}, Index: decInt("4")},
+		},
+		{
+			name:         "int as a plain identifier",
+			input:        "type s int[2];",
+			expectedType: &parser.Index{Base: ident("int"), Index: decInt("2")},
+		},
+		{
+			name:        "sized integer without bits",
+			input:       "type s int<>;",
+			expectedErr: parser.ErrMalformedType,
+		},
+		{
+			name:        "unclosed sized integer",
+			input:       "type s int<24;",
+			expectedErr: parser.ErrMalformedType,
+		},
+		{
+			name:        "unclosed set type",
+			input:       "type s set[int;",
+			expectedErr: parser.ErrUnclosedSubscription,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			decl, actualErr := parser.NewFromString(tt.name, tt.input).ParseDecl()
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+			actualType := decl.(*parser.TypeDecl).Type
+			require.Empty(t, parser.Diff(tt.expectedType, actualType))
+		})
+	}
+}
+
+func TestParser_ParseSetTypeField(t *testing.T) {
+	expr, err := parser.NewFromString("set field", "struct { tags : set[string]; }").ParseExpr()
+	require.NoError(t, err)
+
+	field := expr.(*parser.StructDef).Block.Decls[0].(*parser.Field)
+	require.Empty(t, parser.Diff(&parser.SetType{Element: ident("string")}, field.Type))
+}
+
+func TestParser_SetInValuePosition(t *testing.T) {
+	// outside of type position set is a regular identifier, so set[x] is an index
+	expr, err := parser.NewFromString("set value", "set[x]").ParseExpr()
+	require.NoError(t, err)
+	require.Empty(t, parser.Diff(&parser.Index{Base: ident("set"), Index: ident("x")}, expr))
+}
+
+func TestParser_ParseInlineTypeFields(t *testing.T) {
+	field := func(name string, typ parser.Expr) *parser.Field {
+		return &parser.Field{Name: ident(name), Type: typ}
+	}
+	point := &parser.StructDef{Block: parser.Block{Decls: []parser.Decl{field("x", ident("int")), field("y", ident("int"))}}}
+
+	cases := []struct {
+		name           string
+		input          string
+		expectedFields []parser.Decl
+		expectedErr    error
+	}{
+		{
+			name:           "inline struct followed by a field",
+			input:          "type s struct { point : struct { x: int; y: int; }; z : int; };",
+			expectedFields: []parser.Decl{field("point", point), field("z", ident("int"))},
+		},
+		{
+			name:           "inline struct spanning lines",
+			input:          "type s struct {\npoint : struct {\nx: int\ny: int\n}\nz : int\n}\n",
+			expectedFields: []parser.Decl{field("point", point), field("z", ident("int"))},
+		},
+		{
+			name:           "inline struct as the last field",
+			input:          "type s struct { point : struct { x: int; y: int } };",
+			expectedFields: []parser.Decl{field("point", point)},
+		},
+		{
+			name:  "inline union and enum",
+			input: "type s struct { u : union { a: int; b: float; }; e : enum { A; B }; };",
+			expectedFields: []parser.Decl{
+				field("u", &parser.UnionDef{Block: parser.Block{Decls: []parser.Decl{field("a", ident("int")), field("b", ident("float"))}}}),
+				field("e", &parser.EnumDef{Block: parser.Block{Decls: []parser.Decl{&parser.Field{Name: ident("A")}, &parser.Field{Name: ident("B")}}}}),
+			},
+		},
+		{
+			name:        "inline struct without separator",
+			input:       "type s struct { point : struct { x: int; y: int; } z : int; };",
+			expectedErr: parser.ErrUnexpectedToken,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, actualErr := parser.NewFromString(tt.name, tt.input).Parse()
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+			require.Len(t, schema.Decls, 1)
+			actualFields := schema.Decls[0].(*parser.TypeDecl).Type.(*parser.StructDef).Block.Decls
+			require.Empty(t, parser.Diff(tt.expectedFields, actualFields))
+		})
+	}
+}
+
+func TestParser_ParseRangeConstraints(t *testing.T) {
+	cases := []struct {
+		name          string
+		input         string
+		expectedRange *parser.RangeConstraint
+		expectedValue parser.Expr
+	}{
+		{
+			name:          "integer range",
+			input:         "type s struct { score : int in 0..100; };",
+			expectedRange: &parser.RangeConstraint{Low: decInt("0"), High: decInt("100")},
+		},
+		{
+			name:          "negative low end",
+			input:         "type s struct { offset : i8 in -10..10; };",
+			expectedRange: &parser.RangeConstraint{Low: unary("-", decInt("10")), High: decInt("10")},
+		},
+		{
+			name:          "expressions and names",
+			input:         "type s struct { level : u8 in min..max - 1; };",
+			expectedRange: &parser.RangeConstraint{Low: ident("min"), High: binary("-", ident("max"), decInt("1"))},
+		},
+		{
+			name:          "range with a default value",
+			input:         "type s struct { score : int in 1..10 = 5; };",
+			expectedRange: &parser.RangeConstraint{Low: decInt("1"), High: decInt("10")},
+			expectedValue: decInt("5"),
+		},
+		{
+			name:  "unconstrained field",
+			input: "type s struct { score : int; };",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := parser.NewFromString(tt.name, tt.input).ParseDecl()
+			require.NoError(t, err)
+
+			field := expr.(*parser.TypeDecl).Type.(*parser.StructDef).Block.Decls[0].(*parser.Field)
+			require.Empty(t, parser.Diff(tt.expectedValue, field.Value))
+			if tt.expectedRange == nil {
+				require.Nil(t, field.Range)
+				return
+			}
+
+			require.Empty(t, parser.Diff(tt.expectedRange, field.Range))
+		})
+	}
+}
+
+func TestParser_ParseMalformedRangeConstraints(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+	}{
+		{name: "missing high end", input: "@doc(\"score\") score : int in 0..;"},
+		{name: "missing low end", input: "@doc(\"score\") score : int in ..10;"},
+		{name: "missing range punctuation", input: "@doc(\"score\") score : int in 0 100;"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// type blocks report their unclosed brace instead, so the field is parsed on its own
+			_, err := parser.NewFromString(tt.name, tt.input).ParseAnnotatedField()
+			require.ErrorIs(t, err, parser.ErrMalformedRange)
+		})
+	}
+}
+
+func TestParser_ParseUnionTagField(t *testing.T) {
+	tagged := func(name string, typ parser.Expr) *parser.Field {
+		return &parser.Field{Name: ident(name), Type: typ, Tag: true}
+	}
+	field := func(name string, typ parser.Expr) *parser.Field {
+		return &parser.Field{Name: ident(name), Type: typ}
+	}
+
+	cases := []struct {
+		name           string
+		input          string
+		expectedFields []parser.Decl
+		expectedErr    error
+	}{
+		{
+			name:           "tag field",
+			input:          "type u union { tag kind : Kind; a : int; b : float; };",
+			expectedFields: []parser.Decl{tagged("kind", ident("Kind")), field("a", ident("int")), field("b", ident("float"))},
+		},
+		{
+			name:           "member named tag",
+			input:          "type u union { tag : u8; a : int; };",
+			expectedFields: []parser.Decl{field("tag", ident("u8")), field("a", ident("int"))},
+		},
+		{
+			name:  "tag word in a struct nested in an union",
+			input: "type u union { tag kind : Kind; s : struct { tag : u8; }; };",
+			expectedFields: []parser.Decl{
+				tagged("kind", ident("Kind")),
+				field("s", &parser.StructDef{Block: parser.Block{Decls: []parser.Decl{field("tag", ident("u8"))}}}),
+			},
+		},
+		{
+			name:        "escaped tag word",
+			input:       "type u union { `tag` kind : Kind; };",
+			expectedErr: parser.ErrUnexpectedToken,
+		},
+		{
+			name:        "tag word in a struct",
+			input:       "type s struct { tag kind : Kind; };",
+			expectedErr: parser.ErrUnexpectedToken,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			decl, actualErr := parser.NewFromString(tt.name, tt.input).ParseDecl()
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+			actualFields := decl.(*parser.TypeDecl).Type.(*parser.UnionDef).Block.Decls
+			require.Empty(t, parser.Diff(tt.expectedFields, actualFields))
+		})
+	}
+}
+
+func TestParser_ParseEmbedDecl(t *testing.T) {
+	field := func(name string, typ parser.Expr) *parser.Field {
+		return &parser.Field{Name: ident(name), Type: typ}
+	}
+
+	cases := []struct {
+		name           string
+		input          string
+		expectedFields []parser.Decl
+	}{
+		{
+			name:           "include",
+			input:          "type s struct { include Base; a : int; };",
+			expectedFields: []parser.Decl{&parser.EmbedDecl{Type: ident("Base")}, field("a", ident("int"))},
+		},
+		{
+			name:  "include between fields",
+			input: "type s struct {\n  a : int\n  include Base\n  b : float\n};",
+			expectedFields: []parser.Decl{
+				field("a", ident("int")), &parser.EmbedDecl{Type: ident("Base")}, field("b", ident("float")),
+			},
+		},
+		{
+			name:  "qualified include",
+			input: "type s struct { include pkg.Base; };",
+			expectedFields: []parser.Decl{
+				&parser.EmbedDecl{Type: &parser.QualifiedName{Package: ident("pkg"), Name: ident("Base")}},
+			},
+		},
+		{
+			name:  "escaped include word",
+			input: "type s struct { `include` : int; };",
+			expectedFields: []parser.Decl{&parser.Field{
+				Name: &parser.Ident{Token: lexer.Token{Tag: lexer.TokenTagWord, Value: "include", Escaped: true}},
+				Type: ident("int"),
+			}},
+		},
+		{
+			name:           "field named include",
+			input:          "type s struct { include : int; };",
+			expectedFields: []parser.Decl{&parser.Field{Name: ident("include"), Type: ident("int")}},
+		},
+		{
+			name:           "field named include with value",
+			input:          "type s struct { include = 3; };",
+			expectedFields: []parser.Decl{&parser.Field{Name: ident("include"), Value: decInt("3")}},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			decl, err := parser.NewFromString(tt.name, tt.input).ParseDecl()
+			require.NoError(t, err)
+
+			actualFields := decl.(*parser.TypeDecl).Type.(*parser.StructDef).Block.Decls
+			require.Empty(t, parser.Diff(tt.expectedFields, actualFields))
+		})
+	}
+}
+
+func TestParser_ParseMalformedEmbedDecl(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+	}{
+		{name: "trailing field", input: "type S struct { include Base extra : int }\n"},
+		{name: "trailing junk", input: "type S struct {\ninclude Base )\n}\n"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parser.NewFromString(tt.name, tt.input).Parse()
+			require.ErrorIs(t, err, parser.ErrMalformedEmbed)
+		})
+	}
+}
+
+func TestParser_ParseQualifiedFieldType(t *testing.T) {
+	qualified := func(pkg parser.Expr, name string) *parser.QualifiedName {
+		return &parser.QualifiedName{Package: pkg, Name: ident(name)}
+	}
+
+	cases := []struct {
+		name          string
+		input         string
+		expectedType  parser.Expr
+		expectedValue parser.Expr
+		expectedName  string
+	}{
+		{
+			name:         "qualified type",
+			input:        "type s struct { a : pkg.Type; };",
+			expectedType: qualified(ident("pkg"), "Type"),
+			expectedName: "pkg.Type",
+		},
+		{
+			name:         "dotted package",
+			input:        "type s struct { a : net.http.Request; };",
+			expectedType: qualified(qualified(ident("net"), "http"), "Request"),
+			expectedName: "net.http.Request",
+		},
+		{
+			name:         "qualified array of pointers",
+			input:        "type s struct { a : *pkg.Type[4]; };",
+			expectedType: unary("*", &parser.Index{Base: qualified(ident("pkg"), "Type"), Index: decInt("4")}),
+		},
+		{
+			name:         "qualified constructor argument",
+			input:        "type s struct { a : const(pkg.Type); };",
+			expectedType: &parser.Call{Callee: ident("const"), Args: []parser.Expr{qualified(ident("pkg"), "Type")}},
+		},
+		{
+			name:          "values remain member accesses",
+			input:         "type s struct { a : pkg.Kind = pkg.Kind.A; };",
+			expectedType:  qualified(ident("pkg"), "Kind"),
+			expectedValue: binary(".", binary(".", ident("pkg"), ident("Kind")), ident("A")),
+			expectedName:  "pkg.Kind",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			decl, err := parser.NewFromString(tt.name, tt.input).ParseDecl()
+			require.NoError(t, err)
+
+			field := decl.(*parser.TypeDecl).Type.(*parser.StructDef).Block.Decls[0].(*parser.Field)
+			require.Empty(t, parser.Diff(tt.expectedType, field.Type))
+			if tt.expectedValue != nil {
+				require.Empty(t, parser.Diff(tt.expectedValue, field.Value))
+			}
+			if tt.expectedName != "" {
+				require.Equal(t, tt.expectedName, parser.LookupName(field.Type))
+			}
+		})
+	}
+}
+
+func TestParser_ParseType(t *testing.T) {
+	cases := []struct {
+		name         string
+		input        string
+		expectedType parser.Expr
+		expectedErr  error
+	}{
+		{
+			name:         "array type",
+			input:        "u8[4]",
+			expectedType: &parser.Index{Base: ident("u8"), Index: decInt("4")},
+		},
+		{
+			name:  "map type",
+			input: "map[string]*pkg.Item",
+			expectedType: &parser.MapType{
+				Key:   ident("string"),
+				Value: unary("*", &parser.QualifiedName{Package: ident("pkg"), Name: ident("Item")}),
+			},
+		},
+		{
+			name:         "tuple type",
+			input:        "(int, bool)",
+			expectedType: &parser.TupleType{Elements: []parser.Expr{ident("int"), ident("bool")}},
+		},
+		{
+			name:        "malformed map type",
+			input:       "map[string]",
+			expectedErr: parser.ErrMalformedType,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualType, actualErr := parser.NewFromString(tt.name, tt.input).ParseType()
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+			require.Empty(t, parser.Diff(tt.expectedType, actualType))
+		})
+	}
+}
+
+func TestParser_ParseFieldList(t *testing.T) {
+	cases := []struct {
+		name           string
+		input          string
+		expectedFields []parser.Field
+		expectedErr    error
+	}{
+		{
+			name:  "fields on one line",
+			input: "a : int; b : float = 1.5",
+			expectedFields: []parser.Field{
+				{Name: ident("a"), Type: ident("int")},
+				{
+					Name:  ident("b"),
+					Type:  ident("float"),
+					Value: &parser.Literal{Token: lexer.Token{Tag: lexer.TokenTagFloat, Value: "1.5"}},
+				},
+			},
+		},
+		{
+			name:  "fields on multiple lines",
+			input: "\n# first\na : int # trailing\n\nb : set[u8]\n",
+			expectedFields: []parser.Field{
+				{
+					Name:     ident("a"),
+					Type:     ident("int"),
+					Leading:  []lexer.Token{{Tag: lexer.TokenTagComment, Value: "# first"}},
+					Trailing: &lexer.Token{Tag: lexer.TokenTagComment, Value: "# trailing", Trailing: true},
+				},
+				{Name: ident("b"), Type: &parser.SetType{Element: ident("u8")}},
+			},
+		},
+		{
+			name:           "empty list",
+			input:          "",
+			expectedFields: []parser.Field{},
+		},
+		{
+			name:        "annotated field",
+			input:       "[[ size = 4 ]]\na : int",
+			expectedErr: parser.ErrUnexpectedToken,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			actualFields, actualErr := parser.NewFromString(tt.name, tt.input).ParseFieldList()
+			if tt.expectedErr != nil {
+				require.ErrorIs(t, actualErr, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, actualErr)
+			require.Len(t, actualFields, len(tt.expectedFields))
+			for i := range tt.expectedFields {
+				require.Empty(t, parser.Diff(&tt.expectedFields[i], &actualFields[i]))
+			}
+		})
+	}
+}
@@ -14,16 +14,47 @@ var (
 	ErrUnexpectedToken      = errors.New("unexpected token")
 	ErrUnclosedParenthesis  = errors.New("unclosed parenthesis")
 	ErrUnclosedSubscription = errors.New("unclosed subscription")
+	ErrMalformedType        = errors.New("malformed type")
+	ErrMalformedRange       = errors.New("malformed range")
+	ErrDuplicateOptions     = errors.New("duplicate options")
+	ErrMalformedBytes       = errors.New("malformed byte string")
+	ErrMalformedEmbed       = errors.New("malformed include")
+	ErrReservedName         = errors.New("reserved word used as a name")
 )
 
+// committed reports whether a parse error comes from a rule whose leading tokens were already accepted (a byte
+// string, an include or a declaration naming a reserved word), such errors are returned as they are instead of
+// trying the next alternative
+func committed(err error) bool {
+	return errors.Is(err, ErrMalformedBytes) || errors.Is(err, ErrMalformedEmbed) || errors.Is(err, ErrReservedName)
+}
+
+// errorf returns an error located at loc, see lexer.Error
+func errorf(loc lexer.Location, err error, msg string, args ...any) error {
+	return &lexer.Error{Loc: loc, Err: err, Msg: fmt.Sprintf(msg, args...)}
+}
+
 // Parser handle a single file parsing
 type Parser struct {
-	lex *lexer.Lexer
+	lex tokenSource
+
+	// inType is set while parsing a type expression, where empty subscripts (T[]) are allowed
+	inType bool
+
+	// inUnion is set while parsing the members of an union, where fields may be marked as the tag (tag kind : Kind)
+	inUnion bool
+
+	// prec groups the binary operators by precedence level, see SetOperatorPrecedence
+	prec    map[int][]string
+	maxPrec int
+
+	// lastErrLoc is the location of the last token that did not match, see LastErrorLocation
+	lastErrLoc lexer.Location
 }
 
 // New returns a new parser using only a filename and a rune reader
 func New(filename string, r io.RuneReader) *Parser {
-	return &Parser{lex: lexer.New(filename, r)}
+	return &Parser{lex: lexer.New(filename, r), prec: punctPrec, maxPrec: maxPrec}
 }
 
 // NewFromString returns new parser using a string as content
@@ -31,26 +62,116 @@ func NewFromString(filename, content string) *Parser {
 	return New(filename, strings.NewReader(content))
 }
 
+// ParseDeclString parses a single declaration, annotated or not, the end of lines around it are skipped. Anything
+// else after the declaration is an unexpected token.
+func ParseDeclString(file, src string) (Decl, error) {
+	p := NewFromString(file, src)
+	_, _ = p.expect(lexer.Token{Tag: lexer.TokenTagEOL})
+
+	decl, err := p.ParseAnnotatedDecl()
+	if err != nil {
+		decl, err = p.ParseDecl()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if _, err = p.expect(lexer.Token{Tag: lexer.TokenTagEOL}); err != nil {
+			break
+		}
+	}
+
+	_, err = p.expect(lexer.Token{Tag: lexer.TokenTagEOF})
+	if err != nil {
+		return nil, err
+	}
+
+	return decl, nil
+}
+
 func (p *Parser) expect(anyOf ...lexer.Token) (lexer.Token, error) {
+	// the tokens of a whole file are only looked at, there is nothing to put back when they do not match
+	if ts, ok := p.lex.(*tokenSlice); ok && ts.unread == nil {
+		token, at := ts.peek()
+		if !matchesAny(token, anyOf) {
+			p.lastErrLoc = token.Loc
+			ts.hold(at)
+			return token, &unexpectedError{value: token.Value}
+		}
+
+		ts.skip(at)
+		return token, nil
+	}
+
 	token, err := p.lex.Read()
 	if err != nil {
 		return token, err
 	}
 
+	if matchesAny(token, anyOf) {
+		return token, nil
+	}
+
+	p.lastErrLoc = token.Loc
+	err = p.lex.Unread(token)
+	if err != nil {
+		return token, err
+	}
+
+	return token, &unexpectedError{value: token.Value}
+}
+
+func matchesAny(token lexer.Token, anyOf []lexer.Token) bool {
 	for _, matching := range anyOf {
 		matchesTag := token.Tag == matching.Tag
-		matchesValue := matching.Value == "" || (matching.Value != "" && matching.Value == token.Value)
+		// escaped words only match a bare tag, so they cannot be taken as keywords
+		matchesValue := matching.Value == "" || (matching.Value == token.Value && !token.Escaped)
 		if matchesTag && matchesValue {
-			return token, nil
+			return true
 		}
 	}
 
-	err = p.lex.Unread(token)
-	if err != nil {
+	return false
+}
+
+// unexpectedError is a token that did not match in expect, it is only formatted when printed since most of them are
+// discarded by the parser to try the next alternative
+type unexpectedError struct {
+	value string
+}
+
+func (e *unexpectedError) Error() string {
+	return ErrUnexpectedToken.Error() + " `" + e.value + "`"
+}
+
+func (e *unexpectedError) Unwrap() error {
+	return ErrUnexpectedToken
+}
+
+// LastErrorLocation returns the location of the last token that did not match what the parser expected, so editors
+// can point at it even when parsing recovers. Alternatives are tried by expecting them, so a successful Parse resets
+// it to the zero location rather than pointing at a token that was finally accepted.
+func (p *Parser) LastErrorLocation() lexer.Location {
+	return p.lastErrLoc
+}
+
+// expectCloseBracket expects a "]", the lexer reads "]]" as a single token (annotations end) so it is split
+// when it closes two nested subscripts
+func (p *Parser) expectCloseBracket() (lexer.Token, error) {
+	token, err := p.expect(
+		lexer.Token{Tag: lexer.TokenTagPunct, Value: "]"},
+		lexer.Token{Tag: lexer.TokenTagPunct, Value: "]]"},
+	)
+	if err != nil || token.Value == "]" {
 		return token, err
 	}
 
-	return token, fmt.Errorf("%w `%s`", ErrUnexpectedToken, token.Value)
+	second := token
+	second.Value = "]"
+	second.Loc.Col += 1
+	token.Value = "]"
+	return token, p.lex.Unread(second)
 }
 
 // Parse reads the entire file and descends on each rule to make an AST
@@ -59,25 +180,36 @@ func (p *Parser) Parse() (*Schema, error) {
 	_, _ = p.expect(lexer.Token{Tag: lexer.TokenTagEOL})
 
 	decls := make([]Decl, 0)
+	var options *OptionsDecl
 	for {
-		annotatedDecl, err := p.ParseAnnotatedDecl()
-		if err == nil {
-			decls = append(decls, annotatedDecl)
-			continue
+		decl, err := p.ParseAnnotatedDecl()
+		if err != nil {
+			decl, err = p.ParseDecl()
+		}
+		if committed(err) {
+			return nil, err
+		}
+		if err != nil {
+			break
 		}
 
-		decl, err := p.ParseDecl()
-		if err == nil {
-			decls = append(decls, decl)
-			continue
+		// a schema is configured by a single options directive
+		if directive, ok := unwrapAnnotated(decl).(*OptionsDecl); ok {
+			if options != nil {
+				return nil, errorf(directive.Token.Loc, ErrDuplicateOptions, "")
+			}
+			options = directive
 		}
 
-		break
+		decls = append(decls, decl)
 	}
 
 	// Skip trailing end of lines and EOF
 	_, _ = p.expect(lexer.Token{Tag: lexer.TokenTagEOL})
 	_, err := p.expect(lexer.Token{Tag: lexer.TokenTagEOF})
+	if err == nil {
+		p.lastErrLoc = lexer.Location{}
+	}
 
 	return &Schema{
 		Decls: decls,
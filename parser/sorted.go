@@ -0,0 +1,51 @@
+package parser
+
+import (
+	"slices"
+	"strings"
+)
+
+// DeclOrder selects how the declarations of a schema are sorted, see SortedDeclsBy
+type DeclOrder int
+
+const (
+	DeclOrderSource DeclOrder = iota // DeclOrderSource keeps the order of the file
+	DeclOrderName                    // DeclOrderName sorts types, procs and consts by name
+)
+
+// SortedDecls returns the declarations of the schema in source order with their annotations unwrapped
+func (s *Schema) SortedDecls() []Decl {
+	return s.SortedDeclsBy(DeclOrderSource)
+}
+
+// SortedDeclsBy returns the declarations of the schema with their annotations unwrapped in a deterministic order,
+// so backends iterating them produce reproducible output. Sorting by name is stable and puts the declarations
+// without one (modules, imports and options) first, in source order. The schema itself is not modified.
+func (s *Schema) SortedDeclsBy(order DeclOrder) []Decl {
+	decls := make([]Decl, 0, len(s.Decls))
+	for _, decl := range s.Decls {
+		decls = append(decls, unwrapAnnotated(decl))
+	}
+
+	if order == DeclOrderName {
+		slices.SortStableFunc(decls, func(a, b Decl) int {
+			return strings.Compare(sortName(a), sortName(b))
+		})
+	}
+
+	return decls
+}
+
+// sortName returns the name of a type, proc or const declaration, or an empty string for any other declaration
+func sortName(decl Decl) string {
+	switch d := decl.(type) {
+	case *TypeDecl:
+		return LookupName(d.Name)
+	case *ProcDecl:
+		return LookupName(d.Name)
+	case *ConstDecl:
+		return LookupName(d.Name)
+	}
+
+	return ""
+}
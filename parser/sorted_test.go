@@ -0,0 +1,78 @@
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/cedmundo/SimpleSchema/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema_SortedDecls(t *testing.T) {
+	input := "module m;\ntype zeta int;\n[[ doc = \"alpha\" ]]\nproc alpha() -> int;\nimport \"b.ss\";\n" +
+		"const MID : int = 1;\ntype beta struct {\na : int\n}\n"
+
+	cases := []struct {
+		name          string
+		order         parser.DeclOrder
+		expectedNames []string
+	}{
+		{
+			name:          "source order",
+			order:         parser.DeclOrderSource,
+			expectedNames: []string{"module", "zeta", "alpha", "import", "MID", "beta"},
+		},
+		{
+			name:          "name order",
+			order:         parser.DeclOrderName,
+			expectedNames: []string{"module", "import", "MID", "alpha", "beta", "zeta"},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			// the order must not change between runs
+			for range 10 {
+				schema, err := parser.NewFromString(tt.name, input).Parse()
+				require.NoError(t, err)
+
+				decls := schema.SortedDeclsBy(tt.order)
+				names := make([]string, 0, len(decls))
+				for _, decl := range decls {
+					_, annotated := decl.(*parser.AnnotatedDecl)
+					require.False(t, annotated)
+					names = append(names, testDeclName(decl))
+				}
+				require.Equal(t, tt.expectedNames, names)
+			}
+		})
+	}
+}
+
+func TestSchema_SortedDeclsKeepsSchema(t *testing.T) {
+	schema, err := parser.NewFromString("keeps schema", "type b int;\n[[ doc = \"a\" ]]\ntype a int;\n").Parse()
+	require.NoError(t, err)
+
+	require.Len(t, schema.SortedDecls(), 2)
+	sorted := schema.SortedDeclsBy(parser.DeclOrderName)
+	require.Equal(t, "a", testDeclName(sorted[0]))
+
+	_, annotated := schema.Decls[1].(*parser.AnnotatedDecl)
+	require.True(t, annotated)
+	require.Equal(t, "b", testDeclName(schema.Decls[0]))
+}
+
+func testDeclName(decl parser.Decl) string {
+	switch d := decl.(type) {
+	case *parser.TypeDecl:
+		return parser.LookupName(d.Name)
+	case *parser.ProcDecl:
+		return parser.LookupName(d.Name)
+	case *parser.ConstDecl:
+		return parser.LookupName(d.Name)
+	case *parser.ModuleDecl:
+		return "module"
+	case *parser.ImportDecl:
+		return "import"
+	}
+
+	return ""
+}
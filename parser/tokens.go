@@ -0,0 +1,132 @@
+package parser
+
+import "github.com/cedmundo/SimpleSchema/lexer"
+
+// tokenSource feeds the parser, either a lexer reading on demand or the tokens of a whole file (see NewFromTokens)
+type tokenSource interface {
+	Read() (lexer.Token, error)
+	Unread(token lexer.Token) error
+	PushGroup()
+	PopGroup() error
+}
+
+// tokenSlice reads the tokens of a whole file, putting back the last token read only moves the position back
+type tokenSlice struct {
+	tokens []lexer.Token
+	pos    int
+	group  int
+
+	// replay marks the token at pos as put back, it is read again as it was (end of lines too) even if a group was
+	// pushed since then, like the unread token of a lexer
+	replay bool
+
+	// unread is a token put back that differs from the last one read (half of a split "]]"), it goes first
+	unread *lexer.Token
+}
+
+func (ts *tokenSlice) Read() (lexer.Token, error) {
+	if ts.unread != nil {
+		token := *ts.unread
+		ts.unread = nil
+		return token, nil
+	}
+
+	if ts.replay {
+		ts.replay = false
+		ts.pos += 1
+		return ts.tokens[ts.pos-1], nil
+	}
+
+	for ts.pos < len(ts.tokens) {
+		token := ts.tokens[ts.pos]
+		// the end of file is read over and over, like the lexer does
+		if token.Tag != lexer.TokenTagEOF {
+			ts.pos += 1
+		}
+
+		// within groups the lexer takes new lines as spaces
+		if ts.group != 0 && token.Tag == lexer.TokenTagEOL {
+			continue
+		}
+
+		return token, nil
+	}
+
+	return lexer.Token{Tag: lexer.TokenTagEOF}, nil
+}
+
+func (ts *tokenSlice) Unread(token lexer.Token) error {
+	if ts.unread != nil || ts.replay {
+		return lexer.ErrAlreadyUnread
+	}
+
+	if ts.pos > 0 && ts.tokens[ts.pos-1] == token {
+		ts.pos -= 1
+		ts.replay = true
+		return nil
+	}
+
+	ts.unread = &token
+	return nil
+}
+
+// peek returns the token Read would return and its position without reading it, it must not be called while a token
+// differing from the last one read is put back
+func (ts *tokenSlice) peek() (lexer.Token, int) {
+	if ts.replay {
+		return ts.tokens[ts.pos], ts.pos
+	}
+
+	at := ts.pos
+	for ; at < len(ts.tokens); at++ {
+		token := ts.tokens[at]
+		if ts.group != 0 && token.Tag == lexer.TokenTagEOL {
+			continue
+		}
+
+		return token, at
+	}
+
+	return lexer.Token{Tag: lexer.TokenTagEOF}, at
+}
+
+// skip reads the token peeked at the given position
+func (ts *tokenSlice) skip(at int) {
+	ts.pos = at
+	ts.replay = false
+	if at < len(ts.tokens) && ts.tokens[at].Tag != lexer.TokenTagEOF {
+		ts.pos += 1
+	}
+}
+
+// hold leaves the token peeked at the given position as if it was read and put back
+func (ts *tokenSlice) hold(at int) {
+	ts.pos = at
+	ts.replay = at < len(ts.tokens) && ts.tokens[at].Tag != lexer.TokenTagEOF
+}
+
+func (ts *tokenSlice) PushGroup() {
+	ts.group += 1
+}
+
+func (ts *tokenSlice) PopGroup() error {
+	if ts.group <= 0 {
+		return lexer.ErrUnbalancedGroup
+	}
+
+	ts.group -= 1
+	return nil
+}
+
+// NewFromTokens returns a parser over the tokens of a whole file already read (see lexer.ReadAll), so tools holding
+// the tokens can parse them without lexing the input again. The tokens must be read outside of any group, the end
+// of lines within parenthesis and annotations are skipped while parsing (the locations of the tokens following them
+// may differ from the ones of a streaming parser).
+func NewFromTokens(tokens []lexer.Token) *Parser {
+	return &Parser{lex: &tokenSlice{tokens: tokens}, prec: punctPrec, maxPrec: maxPrec}
+}
+
+// ParseTokens parses a whole schema from its tokens, see NewFromTokens
+func ParseTokens(tokens []lexer.Token) (*Schema, error) {
+	return NewFromTokens(tokens).Parse()
+}
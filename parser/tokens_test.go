@@ -0,0 +1,95 @@
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cedmundo/SimpleSchema/lexer"
+	"github.com/cedmundo/SimpleSchema/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTokens(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "types and procs",
+			input: "module net.http\nimport \"base.ss\"\ntype vec2 struct {\nx : float = 1.5 # trailing\ny : float\n}\nproc len(v: vec2) -> float\n",
+		},
+		{
+			name:  "annotations on several lines",
+			input: "[[ size = 8,\n\tname = \"s\" ]]\ntype a int;\n@deprecated(\"old\")\ntype b u8\n",
+		},
+		{
+			name:  "grouped expressions",
+			input: "const N : int = (1 +\n 2) * 3\ntype buf struct {\ndata : u8[(N\n)]\n}\n",
+		},
+		{
+			name:  "nested subscripts closing together",
+			input: "type m struct {\ncells : u8[a[2]]\n}\n",
+		},
+		{
+			name:  "unions with tags",
+			input: "# a shape\ntype shape union {\ntag kind : u8\ncircle : float\n}\n",
+		},
+		{
+			name:  "unexpected token",
+			input: "type a struct {\nb : int c : int\n}\n",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			expectedSchema, expectedErr := parser.NewFromString(tt.name, tt.input).Parse()
+
+			tokens, err := lexer.NewFromString(tt.name, tt.input).ReadAll()
+			require.NoError(t, err)
+			require.Equal(t, lexer.TokenTagEOF, tokens[len(tokens)-1].Tag)
+
+			// the lexer skips new lines within groups as spaces, so the locations after them may differ
+			actualSchema, actualErr := parser.ParseTokens(tokens)
+			require.Equal(t, expectedErr, actualErr)
+			require.Empty(t, parser.Diff(expectedSchema, actualSchema))
+		})
+	}
+}
+
+func TestParseTokens_Empty(t *testing.T) {
+	schema, err := parser.ParseTokens(nil)
+	require.NoError(t, err)
+	require.Empty(t, schema.Decls)
+}
+
+func benchmarkSchema() string {
+	return strings.Repeat("[[ size = 16,\n  align = 8 ]]\ntype position struct {\n  x : float = 1.5 # first\n"+
+		"  y : float = (0x10 +\n 2) * 3\n  tags : u8[4]\n}\nproc move(p: position, dx: float) -> position\n", 1000)
+}
+
+func BenchmarkParser_Parse(b *testing.B) {
+	content := benchmarkSchema()
+	b.SetBytes(int64(len(content)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for range b.N {
+		_, err := parser.NewFromString("bench", content).Parse()
+		require.NoError(b, err)
+	}
+}
+
+func BenchmarkParser_ParseTokens(b *testing.B) {
+	content := benchmarkSchema()
+	// tools calling ParseTokens already hold the tokens, lexing them is not part of parsing
+	tokens, err := lexer.NewFromString("bench", content).ReadAll()
+	require.NoError(b, err)
+
+	b.SetBytes(int64(len(content)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for range b.N {
+		_, err := parser.ParseTokens(tokens)
+		require.NoError(b, err)
+	}
+}